@@ -0,0 +1,164 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	"github.com/google/go-github/v50/github"
+	"github.com/grafana/grafana-github-actions-go/pkg/changelog"
+	"github.com/grafana/grafana-github-actions-go/pkg/ghgql"
+	"github.com/grafana/grafana-github-actions-go/pkg/toolkit"
+	"github.com/rs/zerolog"
+	"github.com/sethvargo/go-githubactions"
+	"github.com/spf13/pflag"
+)
+
+const inputFailOnProblems = "FAIL_ON_PROBLEMS"
+const changelogLabel = "add to changelog"
+
+func main() {
+	logger := zerolog.New(zerolog.ConsoleWriter{Out: os.Stderr}).With().Timestamp().Logger()
+	ctx := context.Background()
+	ctx, cancel := signal.NotifyContext(ctx, syscall.SIGINT, syscall.SIGTERM)
+	defer cancel()
+	ctx = logger.WithContext(ctx)
+
+	var eventPath string
+	var listInputs bool
+
+	pflag.StringVar(&eventPath, "event-path", os.Getenv("GITHUB_EVENT_PATH"), "Path to the JSON file containing the pull_request event")
+	pflag.BoolVar(&listInputs, "list-inputs", false, "Show a list of all available inputs")
+	pflag.Parse()
+
+	tk, err := toolkit.Init(
+		ctx,
+		toolkit.WithRegisteredInput(inputFailOnProblems, "Fail the action if problems were found (1) or only report them (0)"),
+	)
+	if err != nil {
+		logger.Fatal().Err(err).Msg("Failed to initialize toolkit")
+	}
+
+	if listInputs {
+		tk.ShowInputList()
+		return
+	}
+	defer func() {
+		if err := tk.SubmitUsageMetrics(ctx); err != nil {
+			logger.Warn().Err(err).Msg("Failed to submit usage metrics")
+		}
+	}()
+
+	event, err := readPullRequestEvent(eventPath)
+	if err != nil {
+		logger.Fatal().Err(err).Msg("Failed to read pull_request event")
+	}
+	pr := convertPullRequest(event)
+	logger = logger.With().Int("pr", pr.GetNumber()).Logger()
+
+	if !hasLabel(pr, changelogLabel) {
+		logger.Info().Msgf("Pull request is not labelled with `%s`. Nothing to check.", changelogLabel)
+		return
+	}
+
+	result := changelog.Lint(ctx, tk, pr)
+	for _, problem := range result.Problems {
+		githubactions.WithFieldsMap(map[string]string{
+			"title": fmt.Sprintf("Changelog: pull request %s", problem.Field),
+		}).Errorf("%s", problem.Message)
+	}
+	if result.Excluded {
+		logger.Info().Msg("Pull request is excluded from the changelog")
+	} else {
+		logger.Info().Msgf("Preview (%s): %s", result.Section, result.Preview)
+	}
+	githubactions.AddStepSummary(summary(result))
+
+	if len(result.Problems) > 0 {
+		if tk.MustGetBoolInput(ctx, inputFailOnProblems) {
+			logger.Fatal().Msgf("%d problems found", len(result.Problems))
+		}
+		logger.Warn().Msgf("%d problems found", len(result.Problems))
+	}
+}
+
+func readPullRequestEvent(path string) (*github.PullRequestEvent, error) {
+	if path == "" {
+		return nil, fmt.Errorf("no event path provided")
+	}
+	fp, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer fp.Close()
+	event := github.PullRequestEvent{}
+	if err := json.NewDecoder(fp).Decode(&event); err != nil {
+		return nil, err
+	}
+	if event.PullRequest == nil {
+		return nil, fmt.Errorf("event does not contain a pull request")
+	}
+	return &event, nil
+}
+
+// convertPullRequest turns the pull request of the event into the structure
+// used by the changelog package.
+func convertPullRequest(event *github.PullRequestEvent) ghgql.PullRequest {
+	pr := event.GetPullRequest()
+	labels := make([]string, 0, len(pr.Labels))
+	for _, l := range pr.Labels {
+		labels = append(labels, l.GetName())
+	}
+	authorResourcePath := "/" + pr.GetUser().GetLogin()
+	if pr.GetUser().GetType() == "Bot" {
+		authorResourcePath = "/apps/" + strings.TrimSuffix(pr.GetUser().GetLogin(), "[bot]")
+	}
+	return ghgql.PullRequest{
+		Number:             github.Int(pr.GetNumber()),
+		Title:              github.String(pr.GetTitle()),
+		Body:               github.String(pr.GetBody()),
+		Labels:             labels,
+		AuthorLogin:        github.String(pr.GetUser().GetLogin()),
+		AuthorResourcePath: github.String(authorResourcePath),
+		RepoOwner:          github.String(event.GetRepo().GetOwner().GetLogin()),
+		RepoName:           github.String(event.GetRepo().GetName()),
+		HeadRefName:        github.String(pr.GetHead().GetRef()),
+		State:              github.String(strings.ToUpper(pr.GetState())),
+	}
+}
+
+func hasLabel(pr ghgql.PullRequest, label string) bool {
+	for _, l := range pr.Labels {
+		if l == label {
+			return true
+		}
+	}
+	return false
+}
+
+func summary(result changelog.LintResult) string {
+	out := strings.Builder{}
+	out.WriteString("## Changelog preview\n\n")
+	if result.Excluded {
+		out.WriteString("This pull request is excluded from the changelog by its description.\n\n")
+	} else {
+		out.WriteString("This pull request will be listed in the *")
+		out.WriteString(result.Section)
+		out.WriteString("* section:\n\n")
+		out.WriteString(result.Preview)
+		out.WriteString("\n\n")
+	}
+	if len(result.Problems) > 0 {
+		out.WriteString("### Problems\n\n")
+		for _, problem := range result.Problems {
+			out.WriteString("- ")
+			out.WriteString(problem.Message)
+			out.WriteString("\n")
+		}
+	}
+	return out.String()
+}
@@ -24,6 +24,7 @@ func main() {
 		"backport",
 		"bump-release",
 		"migrate-open-prs",
+		"changelog-lint",
 	}
 
 	var doTest bool
@@ -0,0 +1,192 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/grafana/grafana-github-actions-go/pkg/announce"
+	"github.com/grafana/grafana-github-actions-go/pkg/community"
+	"github.com/grafana/grafana-github-actions-go/pkg/toolkit"
+	"github.com/rs/zerolog"
+)
+
+// newAnnouncers configures the announcers for the selected targets based on
+// the inputs. Discourse, Mastodon, and the feed handle reruns for the same
+// version, but Slack and the webhook are notified again on every run.
+func newAnnouncers(ctx context.Context, tk *toolkit.Toolkit, targets []string, repoOwner string, repoName string, version string, texts postTexts) ([]announce.Announcer, error) {
+	result := make([]announce.Announcer, 0, len(targets))
+	for _, target := range targets {
+		var announcer announce.Announcer
+		var err error
+		switch target {
+		case announce.TargetDiscourse:
+			announcer, err = newDiscourseAnnouncer(ctx, tk, repoOwner, repoName, version, texts)
+		case announce.TargetSlack:
+			url, err := requireInput(ctx, tk, inputSlackWebhookURL)
+			if err != nil {
+				return nil, err
+			}
+			announcer = announce.NewSlackAnnouncer(url, nil)
+		case announce.TargetWebhook:
+			url, err := requireInput(ctx, tk, inputWebhookURL)
+			if err != nil {
+				return nil, err
+			}
+			headers := make(map[string]string)
+			if auth := tk.MustGetInput(ctx, inputWebhookAuthorization); auth != "" {
+				headers["Authorization"] = auth
+			}
+			announcer = announce.NewWebhookAnnouncer(url, headers, nil)
+		case announce.TargetMastodon:
+			url, err := requireInput(ctx, tk, inputMastodonURL)
+			if err != nil {
+				return nil, err
+			}
+			token, err := requireInput(ctx, tk, inputMastodonToken)
+			if err != nil {
+				return nil, err
+			}
+			announcer = announce.NewMastodonAnnouncer(url, token, tk.MustGetInput(ctx, inputMastodonVisibility), nil)
+		case announce.TargetFeed:
+			announcer, err = newFeedAnnouncer(ctx, tk)
+		}
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, announcer)
+	}
+	return result, nil
+}
+
+func requireInput(ctx context.Context, tk *toolkit.Toolkit, name string) (string, error) {
+	value := tk.MustGetInput(ctx, name)
+	if value == "" {
+		return "", fmt.Errorf("no %s provided", tk.GetInputEnvName(name))
+	}
+	return value, nil
+}
+
+func newDiscourseAnnouncer(ctx context.Context, tk *toolkit.Toolkit, repoOwner string, repoName string, version string, texts postTexts) (announce.Announcer, error) {
+	key, err := requireInput(ctx, tk, inputCommunityAPIKey)
+	if err != nil {
+		return nil, err
+	}
+	username, err := requireInput(ctx, tk, inputCommunityAPIUsername)
+	if err != nil {
+		return nil, err
+	}
+	communityBaseURL := tk.MustGetInput(ctx, inputCommunityBaseURL)
+	if communityBaseURL == "" {
+		communityBaseURL = defaultBaseURL
+	}
+	communityCategoryID, err := intInput(ctx, tk, inputCommunityCategoryID, defaultCategoryID)
+	if err != nil {
+		return nil, err
+	}
+	markdownOpts, err := newDiscourseMarkdownOptions(ctx, tk, repoOwner, repoName)
+	if err != nil {
+		return nil, err
+	}
+	pin := tk.MustGetInput(ctx, inputCommunityPin)
+	if err := community.ValidatePin(pin); err != nil {
+		return nil, fmt.Errorf("invalid value for %s: %w", tk.GetInputEnvName(inputCommunityPin), err)
+	}
+
+	zerolog.Ctx(ctx).Info().Msgf("Posting to the community board in category %d", communityCategoryID)
+	comm := community.New(
+		community.CommunityWithBaseURL(communityBaseURL),
+		community.CommunityWithAPICredentials(username, key),
+	)
+	announcer := announce.NewDiscourseAnnouncer(comm, community.PostInput{
+		Author:   username,
+		Category: communityCategoryID,
+		// Reruns for the same version have to update the same topic even
+		// if the title changed or the search index is outdated:
+		ExternalID: externalID(repoName, version),
+		Tags:       parseTags(tk.MustGetInput(ctx, inputCommunityTags), version),
+	}, &community.PostOptions{
+		FallbackBody:         fallbackChangelog(texts),
+		MaxPostLength:        markdownOpts.MaxSectionLength,
+		Pin:                  pin,
+		RelatedTopicPrefix:   texts.TitlePrefix,
+		CloseRelatedTopics:   tk.MustGetBoolInput(ctx, inputCommunityCloseOlder),
+		ArchiveRelatedTopics: tk.MustGetBoolInput(ctx, inputCommunityArchiveOlder),
+	})
+	return &markdownAdaptingAnnouncer{Announcer: announcer, opts: markdownOpts}, nil
+}
+
+// markdownAdaptingAnnouncer adapts the body of the announcement for Discourse
+// before passing it on.
+type markdownAdaptingAnnouncer struct {
+	announce.Announcer
+	opts discourseMarkdownOptions
+}
+
+func (m *markdownAdaptingAnnouncer) Announce(ctx context.Context, a announce.Announcement) error {
+	a.Body = adaptMarkdownForDiscourse(a.Body, m.opts)
+	return m.Announcer.Announce(ctx, a)
+}
+
+func newDiscourseMarkdownOptions(ctx context.Context, tk *toolkit.Toolkit, repoOwner string, repoName string) (discourseMarkdownOptions, error) {
+	maxPostLength, err := intInput(ctx, tk, inputCommunityMaxPostLength, defaultMaxPostLength)
+	if err != nil {
+		return discourseMarkdownOptions{}, err
+	}
+	detailsThreshold, err := intInput(ctx, tk, inputCommunityDetailsThreshold, defaultDetailsThreshold)
+	if err != nil {
+		return discourseMarkdownOptions{}, err
+	}
+	return discourseMarkdownOptions{
+		RepoOwner:        repoOwner,
+		RepoName:         repoName,
+		DetailsThreshold: detailsThreshold,
+		MaxSectionLength: maxPostLength,
+	}, nil
+}
+
+// intInput parses the given input as integer and uses the fallback value if
+// it is empty.
+func intInput(ctx context.Context, tk *toolkit.Toolkit, name string, fallback string) (int, error) {
+	raw := tk.MustGetInput(ctx, name)
+	if raw == "" {
+		raw = fallback
+	}
+	value, err := strconv.Atoi(raw)
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse %s: %w", tk.GetInputEnvName(name), err)
+	}
+	return value, nil
+}
+
+func newFeedAnnouncer(ctx context.Context, tk *toolkit.Toolkit) (announce.Announcer, error) {
+	feedRepository, err := requireInput(ctx, tk, inputFeedRepository)
+	if err != nil {
+		return nil, err
+	}
+	owner, repo, found := strings.Cut(feedRepository, "/")
+	if !found {
+		return nil, fmt.Errorf("%s has to be an owner/repo pair", tk.GetInputEnvName(inputFeedRepository))
+	}
+	path, err := requireInput(ctx, tk, inputFeedPath)
+	if err != nil {
+		return nil, err
+	}
+	title := tk.MustGetInput(ctx, inputFeedTitle)
+	if title == "" {
+		title = "Grafana releases"
+	}
+	return announce.NewFeedAnnouncer(tk.GitHubClient().Repositories, announce.FeedOptions{
+		Owner:  owner,
+		Repo:   repo,
+		Path:   path,
+		Branch: tk.MustGetInput(ctx, inputFeedBranch),
+		Title:  title,
+		URL:    tk.MustGetInput(ctx, inputFeedURL),
+	}), nil
+}
+
+func releaseURL(repoOwner string, repoName string, version string) string {
+	return fmt.Sprintf("https://github.com/%s/%s/releases/tag/v%s", repoOwner, repoName, strings.TrimPrefix(version, "v"))
+}
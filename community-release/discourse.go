@@ -0,0 +1,164 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// referencePattern matches GitHub user links rendered by the changelog
+// package as well as bare mentions and issue references. Mentions and
+// references directly following a word, a link text, or a path are not
+// matched so that e-mail addresses, URLs, and existing links stay untouched.
+var referencePattern = regexp.MustCompile(`\[@(?P<linked>[A-Za-z0-9-]+(?:\[bot\])?)\]\((?P<url>[^)\s]+)\)` +
+	"|(?P<prefix>^|[^\\w\\[/&#@`.])(?:@(?P<user>[A-Za-z0-9][A-Za-z0-9-]*(?:/[A-Za-z0-9_-]+)?)|(?P<repo>[\\w.-]+/[\\w.-]+)?#(?P<number>\\d+)\\b)")
+
+var headingPattern = regexp.MustCompile(`^#{1,6} `)
+
+type discourseMarkdownOptions struct {
+	RepoOwner string
+	RepoName  string
+	// DetailsThreshold is the number of entries a section needs to have
+	// before it is collapsed into a `[details]` block. 0 disables
+	// collapsing.
+	DetailsThreshold int
+	// MaxSectionLength prevents sections from being collapsed if they are
+	// so long that they would be split across multiple posts as this
+	// would break the `[details]` block.
+	MaxSectionLength int
+}
+
+// adaptMarkdownForDiscourse rewrites the changelog so that it renders on
+// Discourse like it does on GitHub:
+//
+//   - Mentions of GitHub users are turned into profile links that don't
+//     notify forum users with the same name.
+//   - Bare issue references like `#1234` are linked as Discourse doesn't
+//     know about the repository.
+//   - HTML escapes inside code spans are reverted as they would otherwise be
+//     shown literally.
+//   - Sections with many entries are collapsed into `[details]` blocks.
+//
+// Fenced code blocks are left untouched.
+func adaptMarkdownForDiscourse(content string, opts discourseMarkdownOptions) string {
+	lines := strings.Split(content, "\n")
+	inFence := false
+	for idx, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			inFence = !inFence
+			continue
+		}
+		if inFence {
+			continue
+		}
+		lines[idx] = adaptInlineMarkdown(line, opts)
+	}
+	return collapseLongSections(lines, opts)
+}
+
+// adaptInlineMarkdown rewrites a single line. Text inside code spans is only
+// unescaped.
+func adaptInlineMarkdown(line string, opts discourseMarkdownOptions) string {
+	segments := strings.Split(line, "`")
+	for idx, segment := range segments {
+		// An unmatched backtick doesn't start a code span:
+		inCode := idx%2 == 1 && idx < len(segments)-1
+		if inCode {
+			segment = strings.ReplaceAll(segment, "&lt;", "<")
+			segments[idx] = strings.ReplaceAll(segment, "&gt;", ">")
+			continue
+		}
+		segments[idx] = referencePattern.ReplaceAllStringFunc(segment, func(match string) string {
+			return rewriteReference(match, opts)
+		})
+	}
+	return strings.Join(segments, "`")
+}
+
+func rewriteReference(match string, opts discourseMarkdownOptions) string {
+	groups := referencePattern.FindStringSubmatch(match)
+	group := func(name string) string {
+		return groups[referencePattern.SubexpIndex(name)]
+	}
+	// Discourse doesn't look for mentions inside code spans:
+	if linked := group("linked"); linked != "" {
+		return fmt.Sprintf("[`@%s`](%s)", linked, group("url"))
+	}
+	prefix := group("prefix")
+	if user := group("user"); user != "" {
+		if org, team, found := strings.Cut(user, "/"); found {
+			return fmt.Sprintf("%s[`@%s`](https://github.com/orgs/%s/teams/%s)", prefix, user, org, team)
+		}
+		return fmt.Sprintf("%s[`@%s`](https://github.com/%s)", prefix, user, user)
+	}
+	repo := group("repo")
+	if repo == "" {
+		if opts.RepoOwner == "" || opts.RepoName == "" {
+			return match
+		}
+		return fmt.Sprintf("%s[#%s](https://github.com/%s/%s/issues/%s)", prefix, group("number"), opts.RepoOwner, opts.RepoName, group("number"))
+	}
+	return fmt.Sprintf("%s[%s#%s](https://github.com/%s/issues/%s)", prefix, repo, group("number"), repo, group("number"))
+}
+
+// collapseLongSections wraps the content of sections with more than
+// opts.DetailsThreshold entries into a `[details]` block. The heading stays
+// outside of the block so that the post can still be split at it.
+func collapseLongSections(lines []string, opts discourseMarkdownOptions) string {
+	if opts.DetailsThreshold <= 0 {
+		return strings.Join(lines, "\n")
+	}
+	out := strings.Builder{}
+	writeSection := func(heading string, body []string) {
+		if heading == "" && len(body) == 0 {
+			return
+		}
+		if heading != "" {
+			out.WriteString(heading)
+			out.WriteString("\n")
+		}
+		first, last, entries := -1, -1, 0
+		for idx, line := range body {
+			if strings.HasPrefix(line, "- ") || strings.HasPrefix(line, "* ") {
+				if first == -1 {
+					first = idx
+				}
+				last = idx
+				entries++
+			}
+		}
+		// Only the list of entries is wrapped so that notices and footers
+		// stay visible:
+		if heading != "" && entries > opts.DetailsThreshold {
+			list := strings.Join(body[first:last+1], "\n")
+			wrapped := fmt.Sprintf("[details=\"Show all %d entries\"]\n\n%s\n\n[/details]", entries, list)
+			length := utf8.RuneCountInString(heading+"\n"+strings.Join(body, "\n")) + utf8.RuneCountInString(wrapped) - utf8.RuneCountInString(list)
+			if opts.MaxSectionLength <= 0 || length <= opts.MaxSectionLength {
+				body = append(append(append([]string{}, body[:first]...), wrapped), body[last+1:]...)
+			}
+		}
+		out.WriteString(strings.Join(body, "\n"))
+		out.WriteString("\n")
+	}
+
+	heading := ""
+	body := make([]string, 0, len(lines))
+	inFence := false
+	for _, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			inFence = !inFence
+		}
+		if !inFence && headingPattern.MatchString(line) {
+			writeSection(heading, body)
+			heading = line
+			body = body[:0]
+			continue
+		}
+		body = append(body, line)
+	}
+	writeSection(heading, body)
+	return strings.TrimSuffix(out.String(), "\n")
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+var updateGolden = flag.Bool("update", false, "Update the golden files in testdata")
+
+func TestAdaptMarkdownForDiscourse(t *testing.T) {
+	inputs, err := filepath.Glob(filepath.Join("testdata", "discourse", "*.md"))
+	require.NoError(t, err)
+	require.NotEmpty(t, inputs)
+	opts := discourseMarkdownOptions{
+		RepoOwner:        "grafana",
+		RepoName:         "grafana",
+		DetailsThreshold: 4,
+	}
+	for _, input := range inputs {
+		if strings.HasSuffix(input, ".golden.md") {
+			continue
+		}
+		t.Run(strings.TrimSuffix(filepath.Base(input), ".md"), func(t *testing.T) {
+			content, err := os.ReadFile(input)
+			require.NoError(t, err)
+			output := adaptMarkdownForDiscourse(string(content), opts)
+			goldenFile := strings.TrimSuffix(input, ".md") + ".golden.md"
+			if *updateGolden {
+				require.NoError(t, os.WriteFile(goldenFile, []byte(output), 0644))
+			}
+			expected, err := os.ReadFile(goldenFile)
+			require.NoError(t, err)
+			require.Equal(t, string(expected), output)
+		})
+	}
+
+	t.Run("section-too-long-for-details", func(t *testing.T) {
+		content := "### Bug fixes\n\n- A\n- B\n- C\n- D"
+		require.Equal(t, content, adaptMarkdownForDiscourse(content, discourseMarkdownOptions{
+			DetailsThreshold: 3,
+			MaxSectionLength: 20,
+		}))
+	})
+}
@@ -5,14 +5,13 @@ import (
 	"fmt"
 	"os"
 	"os/signal"
-	"strconv"
+	"slices"
 	"strings"
 	"syscall"
 	"unicode/utf8"
 
-	"github.com/google/go-github/v50/github"
+	"github.com/grafana/grafana-github-actions-go/pkg/announce"
 	"github.com/grafana/grafana-github-actions-go/pkg/changelog"
-	"github.com/grafana/grafana-github-actions-go/pkg/community"
 	"github.com/grafana/grafana-github-actions-go/pkg/toolkit"
 	"github.com/rs/zerolog"
 	"github.com/spf13/pflag"
@@ -23,8 +22,35 @@ const inputCommunityAPIUsername = "COMMUNITY_API_USERNAME"
 const inputCommunityCategoryID = "COMMUNITY_CATEGORY_ID"
 const inputCommunityBaseURL = "COMMUNITY_BASE_URL"
 const inputVersion = "VERSION"
+const inputChangelogFormat = "CHANGELOG_FORMAT"
+const inputCommunityMaxPostLength = "COMMUNITY_MAX_POST_LENGTH"
+const inputCommunityTags = "COMMUNITY_TAGS"
+const inputCommunityPin = "COMMUNITY_PIN"
+const inputCommunityCloseOlder = "COMMUNITY_CLOSE_OLDER"
+const inputCommunityArchiveOlder = "COMMUNITY_ARCHIVE_OLDER"
+const inputAnnounceTargets = "ANNOUNCE_TARGETS"
+const inputSlackWebhookURL = "SLACK_WEBHOOK_URL"
+const inputWebhookURL = "WEBHOOK_URL"
+const inputWebhookAuthorization = "WEBHOOK_AUTHORIZATION"
+const inputMastodonURL = "MASTODON_URL"
+const inputMastodonToken = "MASTODON_TOKEN"
+const inputMastodonVisibility = "MASTODON_VISIBILITY"
+const inputFeedRepository = "FEED_REPOSITORY"
+const inputFeedPath = "FEED_PATH"
+const inputFeedBranch = "FEED_BRANCH"
+const inputFeedTitle = "FEED_TITLE"
+const inputFeedURL = "FEED_URL"
+const inputCommunityDetailsThreshold = "COMMUNITY_DETAILS_THRESHOLD"
+const inputTemplatesPath = "TEMPLATES_PATH"
+const inputTitleTemplate = "TITLE_TEMPLATE"
+const inputHeaderTemplate = "HEADER_TEMPLATE"
+const inputFooterTemplate = "FOOTER_TEMPLATE"
+const inputFallbackTemplate = "FALLBACK_TEMPLATE"
+const inputDownloadURLTemplate = "DOWNLOAD_URL_TEMPLATE"
 const defaultCategoryID = "9"
 const defaultBaseURL = "https://community.grafana.com/"
+const defaultMaxPostLength = "30000"
+const defaultDetailsThreshold = "30"
 
 func main() {
 	logger := zerolog.New(zerolog.ConsoleWriter{Out: os.Stderr}).With().Timestamp().Logger()
@@ -51,6 +77,31 @@ func main() {
 		toolkit.WithRegisteredInput(inputCommunityAPIUsername, "API username for the Discourse community"),
 		toolkit.WithRegisteredInput(inputCommunityCategoryID, "Discourse category ID for the changelog post"),
 		toolkit.WithRegisteredInput(inputCommunityBaseURL, "URL where the Discourse community can be found"),
+		toolkit.WithRegisteredInput(inputCommunityMaxPostLength, "Maximum number of characters per post before the changelog is split into replies (0 falls back to a link to the GitHub release)"),
+		toolkit.WithRegisteredInput(inputCommunityTags, "Comma-separated list of tags for the topic; `{major}` is replaced with the major version"),
+		toolkit.WithRegisteredInput(inputCommunityPin, "Pin the topic inside its category (`category`) or globally (`global`) and unpin older release topics"),
+		toolkit.WithRegisteredInput(inputCommunityCloseOlder, "Close the topics of older releases"),
+		toolkit.WithRegisteredInput(inputCommunityArchiveOlder, "Archive the topics of older releases"),
+		toolkit.WithRegisteredInput(inputCommunityDetailsThreshold, "Number of entries after which a section of the community post is collapsed (0 disables collapsing)"),
+		toolkit.WithRegisteredInput(inputAnnounceTargets, "Comma-separated list of targets to announce the release to (discourse, slack, webhook, mastodon, feed)"),
+		toolkit.WithRegisteredInput(inputSlackWebhookURL, "URL of the Slack incoming webhook"),
+		toolkit.WithRegisteredInput(inputWebhookURL, "URL the announcement is posted to as JSON"),
+		toolkit.WithRegisteredInput(inputWebhookAuthorization, "Value of the Authorization header for the webhook"),
+		toolkit.WithRegisteredInput(inputMastodonURL, "URL of the Mastodon instance"),
+		toolkit.WithRegisteredInput(inputMastodonToken, "Access token for posting a status on Mastodon"),
+		toolkit.WithRegisteredInput(inputMastodonVisibility, "Visibility of the Mastodon status (public, unlisted, private)"),
+		toolkit.WithRegisteredInput(inputFeedRepository, "owner/repo pair of the repository containing the Atom feed"),
+		toolkit.WithRegisteredInput(inputFeedPath, "Path of the Atom feed file inside the repository"),
+		toolkit.WithRegisteredInput(inputFeedBranch, "Branch the Atom feed is committed to"),
+		toolkit.WithRegisteredInput(inputFeedTitle, "Title of the Atom feed"),
+		toolkit.WithRegisteredInput(inputFeedURL, "Public URL of the Atom feed"),
+		toolkit.WithRegisteredInput(inputTemplatesPath, "Directory inside the repository containing title.tmpl, header.tmpl, footer.tmpl, fallback.tmpl, and download_url.tmpl"),
+		toolkit.WithRegisteredInput(inputTitleTemplate, "Template for the title of the announcement"),
+		toolkit.WithRegisteredInput(inputHeaderTemplate, "Template for the text above the changelog"),
+		toolkit.WithRegisteredInput(inputFooterTemplate, "Template for the text below the changelog"),
+		toolkit.WithRegisteredInput(inputFallbackTemplate, "Template for the text used instead of a changelog that is too long"),
+		toolkit.WithRegisteredInput(inputDownloadURLTemplate, "Template for the URL of the download page"),
+		toolkit.WithRegisteredInput(inputChangelogFormat, "Format of the changelog; anything but `default` renders it from the milestone instead of loading it from CHANGELOG.md"),
 	)
 	if err != nil {
 		logger.Fatal().Err(err).Msg("Failed to initialize toolkit")
@@ -72,81 +123,112 @@ func main() {
 	repoOwner := elems[0]
 	repoName := elems[1]
 
-	gh := tk.GitHubClient()
+	changelogFormat := tk.MustGetInput(ctx, inputChangelogFormat)
 
-	changelogContent, err := retrieveChangelog(ctx, gh, repoOwner, repoName, version)
+	data, err := newTemplateData(ctx, tk.GitHubClient().Repositories, repoOwner, repoName, version)
+	if err != nil {
+		logger.Warn().Err(err).Msg("Failed to retrieve release information for the templates")
+	}
+	templates, err := loadTemplates(ctx, tk, repoOwner, repoName)
+	if err != nil {
+		logger.Fatal().Err(err).Msg("Failed to load templates")
+	}
+	texts, err := renderPostTexts(templates, data)
+	if err != nil {
+		logger.Fatal().Err(err).Msg("Failed to render templates")
+	}
+
+	changelogContent, err := retrieveChangelog(ctx, tk, repoOwner, repoName, version, changelogFormat, texts)
 	if err != nil {
 		logger.Fatal().Err(err).Msgf("Failed to retrieve changelog for %s", version)
 	}
 
 	logger.Info().Msgf("Changelog received with %d characters", utf8.RuneCountInString(changelogContent))
 
-	releaseTitle := fmt.Sprintf("Changelog: Updates in Grafana %s", version)
+	releaseTitle := texts.Title
+
+	targets, err := announce.ParseTargets(tk.MustGetInput(ctx, inputAnnounceTargets))
+	if err != nil {
+		logger.Fatal().Err(err).Msgf("Invalid value for %s", tk.GetInputEnvName(inputAnnounceTargets))
+	}
+	if len(targets) == 0 {
+		targets = []string{announce.TargetDiscourse}
+	}
 
 	if doPreview {
-		logger.Info().Msgf("No post will be created but this is what it would look like:")
-		fmt.Printf("TITLE: %s\n\n%s\n", releaseTitle, changelogContent)
+		logger.Info().Msgf("No announcement will be made to %s but this is what it would look like:", strings.Join(targets, ", "))
+		previewContent := changelogContent
+		if slices.Contains(targets, announce.TargetDiscourse) {
+			opts, err := newDiscourseMarkdownOptions(ctx, tk, repoOwner, repoName)
+			if err != nil {
+				logger.Fatal().Err(err).Msg("Failed to configure Discourse post")
+			}
+			previewContent = adaptMarkdownForDiscourse(changelogContent, opts)
+		}
+		fmt.Printf("TITLE: %s\n\n%s\n", releaseTitle, previewContent)
 		return
 	}
 
-	key := tk.MustGetInput(ctx, inputCommunityAPIKey)
-	username := tk.MustGetInput(ctx, inputCommunityAPIUsername)
-	communityBaseURL := tk.MustGetInput(ctx, inputCommunityBaseURL)
-	rawCommunityCategoryID := tk.MustGetInput(ctx, inputCommunityCategoryID)
-	if key == "" {
-		logger.Fatal().Msgf("No %s provided", tk.GetInputEnvName(inputCommunityAPIKey))
-	}
-	if username == "" {
-		logger.Fatal().Msgf("No %s provided", tk.GetInputEnvName(inputCommunityAPIUsername))
-	}
-	if communityBaseURL == "" {
-		communityBaseURL = defaultBaseURL
-	}
-	if rawCommunityCategoryID == "" {
-		rawCommunityCategoryID = defaultCategoryID
-	}
-	communityCategoryID, err := strconv.Atoi(rawCommunityCategoryID)
+	announcers, err := newAnnouncers(ctx, tk, targets, repoOwner, repoName, version, texts)
 	if err != nil {
-		logger.Fatal().Err(err).Msgf("Failed to parse %s", tk.GetInputEnvName(inputCommunityCategoryID))
+		logger.Fatal().Err(err).Msg("Failed to configure announcement targets")
 	}
-
-	logger.Info().Msgf("Posting to the community board in category %d", communityCategoryID)
-	comm := community.New(
-		community.CommunityWithBaseURL(communityBaseURL),
-		community.CommunityWithAPICredentials(username, key),
-	)
-	if _, err := comm.CreateOrUpdatePost(ctx, community.PostInput{
-		Title:    releaseTitle,
-		Author:   username,
-		Body:     changelogContent,
-		Category: communityCategoryID,
-	}, &community.PostOptions{
-		FallbackBody: fallbackChangelog(version),
+	if err := announce.AnnounceAll(ctx, announcers, announce.Announcement{
+		Title:   releaseTitle,
+		Version: version,
+		Body:    changelogContent,
+		URL:     releaseURL(repoOwner, repoName, version),
 	}); err != nil {
-		logger.Fatal().Err(err).Msg("Failed to post to the forums")
+		logger.Fatal().Err(err).Msg("Failed to announce the release")
 	}
-
 }
 
-func retrieveChangelog(ctx context.Context, gh *github.Client, repoOwner string, repoName string, version string) (string, error) {
-	loader := changelog.NewLoader(gh)
-	output, err := loader.LoadContent(ctx, repoOwner, repoName, version, &changelog.LoaderOptions{
-		RemoveHeading: true,
-	})
+func retrieveChangelog(ctx context.Context, tk *toolkit.Toolkit, repoOwner string, repoName string, version string, format string, texts postTexts) (string, error) {
+	output, err := changelog.LoadOrBuild(ctx, tk, changelog.NewGitHubDataSource(tk), repoOwner, repoName, version, format)
 	if err != nil {
 		return "", err
 	}
-	return fmt.Sprintf(`%s
+	return composePost(output, texts), nil
+}
 
-%s`, output, changelogFooter(version)), nil
+// composePost uses the highlights of the changelog as opening paragraph of
+// the post followed by the remaining changelog. The header and footer
+// surround the content.
+func composePost(content string, texts postTexts) string {
+	highlights, rest := changelog.SplitHighlights(content)
+	parts := make([]string, 0, 4)
+	for _, part := range []string{texts.Header, highlights, rest, texts.Footer} {
+		if part != "" {
+			parts = append(parts, part)
+		}
+	}
+	return strings.Join(parts, "\n\n")
+}
+
+// parseTags splits the comma-separated list of tags and replaces the
+// `{major}` placeholder with the major version.
+func parseTags(raw string, version string) []string {
+	major, _, _ := strings.Cut(strings.TrimPrefix(version, "v"), ".")
+	result := make([]string, 0, 3)
+	for _, tag := range strings.Split(raw, ",") {
+		tag = strings.TrimSpace(strings.ReplaceAll(tag, "{major}", major))
+		if tag != "" {
+			result = append(result, tag)
+		}
+	}
+	return result
 }
 
-func changelogFooter(version string) string {
-	return fmt.Sprintf(`[Download page](https://grafana.com/grafana/download/%s)
-[What's new highlights](https://grafana.com/docs/grafana/latest/whatsnew/)`, version)
+// externalID returns the ID under which the topic of the given version is
+// tracked in Discourse.
+func externalID(repoName string, version string) string {
+	return fmt.Sprintf("%s-changelog-%s", repoName, strings.TrimPrefix(version, "v"))
 }
 
-func fallbackChangelog(version string) string {
-	return fmt.Sprintf(`[Full changelog](https://github.com/grafana/grafana/releases/tag/v%s)
-%s`, version, changelogFooter(version))
+// fallbackChangelog is posted if the changelog is too long for the post.
+func fallbackChangelog(texts postTexts) string {
+	if texts.Footer == "" {
+		return texts.Fallback
+	}
+	return texts.Fallback + "\n" + texts.Footer
 }
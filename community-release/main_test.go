@@ -1 +1,32 @@
 package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestComposePost(t *testing.T) {
+	texts := postTexts{Footer: "[Download page](https://grafana.com/grafana/download/1.0.0)"}
+	t.Run("without-highlights", func(t *testing.T) {
+		require.Equal(t, "### Bug fixes\n\n- Fix\n\n"+texts.Footer, composePost("### Bug fixes\n\n- Fix", texts))
+	})
+	t.Run("with-highlights", func(t *testing.T) {
+		content := "### Highlights\n\n**New thing.** Summary\n\n### Bug fixes\n\n- Fix"
+		require.Equal(t, "**New thing.** Summary\n\n### Bug fixes\n\n- Fix\n\n"+texts.Footer, composePost(content, texts))
+	})
+	t.Run("with-header", func(t *testing.T) {
+		texts := postTexts{Header: "Loki 3.0.0 is out!"}
+		require.Equal(t, "Loki 3.0.0 is out!\n\n### Bug fixes\n\n- Fix", composePost("### Bug fixes\n\n- Fix", texts))
+	})
+}
+
+func TestParseTags(t *testing.T) {
+	require.Equal(t, []string{}, parseTags("", "11.2.1"))
+	require.Equal(t, []string{"release", "grafana-11"}, parseTags("release, grafana-{major},", "11.2.1"))
+}
+
+func TestReleaseURL(t *testing.T) {
+	require.Equal(t, "https://github.com/grafana/grafana/releases/tag/v11.2.1", releaseURL("grafana", "grafana", "11.2.1"))
+	require.Equal(t, "https://github.com/grafana/grafana/releases/tag/v11.2.1", releaseURL("grafana", "grafana", "v11.2.1"))
+}
@@ -0,0 +1,217 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"path"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/coreos/go-semver/semver"
+	"github.com/google/go-github/v50/github"
+	"github.com/grafana/grafana-github-actions-go/pkg/toolkit"
+	"github.com/rs/zerolog"
+)
+
+const (
+	templateTitle       = "title"
+	templateHeader      = "header"
+	templateFooter      = "footer"
+	templateFallback    = "fallback"
+	templateDownloadURL = "download_url"
+)
+
+// defaultTemplates contain the wording used for Grafana releases.
+var defaultTemplates = map[string]string{
+	templateTitle:  "Changelog: Updates in Grafana {{.Version}}",
+	templateHeader: "",
+	templateFooter: `[Download page]({{.DownloadURL}})
+[What's new highlights](https://grafana.com/docs/grafana/latest/whatsnew/)`,
+	templateFallback:    "[Full changelog]({{.ReleaseURL}})",
+	templateDownloadURL: "https://grafana.com/grafana/download/{{.Version}}",
+}
+
+// templateInputs map the templates to the inputs overriding them.
+var templateInputs = map[string]string{
+	templateTitle:       inputTitleTemplate,
+	templateHeader:      inputHeaderTemplate,
+	templateFooter:      inputFooterTemplate,
+	templateFallback:    inputFallbackTemplate,
+	templateDownloadURL: inputDownloadURLTemplate,
+}
+
+// titleVersionMarker is used in place of the version to find the part of the
+// title shared by all releases.
+const titleVersionMarker = "\x00"
+
+// templateData is available inside all templates.
+type templateData struct {
+	Version         string
+	MajorVersion    string
+	ReleaseDate     string
+	PreviousVersion string
+	RepoOwner       string
+	RepoName        string
+	ReleaseURL      string
+	DownloadURL     string
+}
+
+// postTexts are the rendered templates.
+type postTexts struct {
+	Title string
+	// TitlePrefix is the part of the title before the version and used to
+	// find the topics of other releases.
+	TitlePrefix string
+	Header      string
+	Footer      string
+	Fallback    string
+}
+
+// loadTemplates returns the sources of all templates. Templates provided as
+// input take precedence over the `<name>.tmpl` files in the templates
+// directory of the repository, which in turn override the defaults.
+func loadTemplates(ctx context.Context, tk *toolkit.Toolkit, repoOwner string, repoName string) (map[string]string, error) {
+	logger := zerolog.Ctx(ctx)
+	templatesPath := tk.MustGetInput(ctx, inputTemplatesPath)
+	result := make(map[string]string, len(defaultTemplates))
+	for name, source := range defaultTemplates {
+		result[name] = source
+		if value := tk.MustGetInput(ctx, templateInputs[name]); value != "" {
+			result[name] = value
+			continue
+		}
+		if templatesPath == "" {
+			continue
+		}
+		filePath := path.Join(templatesPath, name+".tmpl")
+		content, _, resp, err := tk.GitHubClient().Repositories.GetContents(ctx, repoOwner, repoName, filePath, nil)
+		if resp != nil && resp.StatusCode == http.StatusNotFound {
+			logger.Info().Msgf("No template found at %s, using the default one", filePath)
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to load template %s: %w", filePath, err)
+		}
+		raw, err := content.GetContent()
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode template %s: %w", filePath, err)
+		}
+		result[name] = strings.TrimSpace(raw)
+	}
+	return result, nil
+}
+
+// renderPostTexts renders the templates. The download URL is rendered first
+// so that it can be used by the other templates.
+func renderPostTexts(sources map[string]string, data templateData) (postTexts, error) {
+	result := postTexts{}
+	var err error
+	if data.DownloadURL, err = renderTemplate(templateDownloadURL, sources[templateDownloadURL], data); err != nil {
+		return result, err
+	}
+	if result.Title, err = renderTemplate(templateTitle, sources[templateTitle], data); err != nil {
+		return result, err
+	}
+	if result.Header, err = renderTemplate(templateHeader, sources[templateHeader], data); err != nil {
+		return result, err
+	}
+	if result.Footer, err = renderTemplate(templateFooter, sources[templateFooter], data); err != nil {
+		return result, err
+	}
+	if result.Fallback, err = renderTemplate(templateFallback, sources[templateFallback], data); err != nil {
+		return result, err
+	}
+	markerData := data
+	markerData.Version = titleVersionMarker
+	markerTitle, err := renderTemplate(templateTitle, sources[templateTitle], markerData)
+	if err != nil {
+		return result, err
+	}
+	if prefix, _, found := strings.Cut(markerTitle, titleVersionMarker); found {
+		result.TitlePrefix = prefix
+	}
+	return result, nil
+}
+
+func renderTemplate(name string, source string, data templateData) (string, error) {
+	tmpl, err := template.New(name).Option("missingkey=error").Parse(source)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse %s template: %w", name, err)
+	}
+	out := bytes.Buffer{}
+	if err := tmpl.Execute(&out, data); err != nil {
+		return "", fmt.Errorf("failed to render %s template: %w", name, err)
+	}
+	return strings.TrimSpace(out.String()), nil
+}
+
+// releaseLister lists the GitHub releases of a repository.
+type releaseLister interface {
+	ListReleases(ctx context.Context, owner string, repo string, opts *github.ListOptions) ([]*github.RepositoryRelease, *github.Response, error)
+}
+
+// newTemplateData collects the data for the templates. The release date is
+// taken from the GitHub release of the version if it was already published.
+func newTemplateData(ctx context.Context, client releaseLister, repoOwner string, repoName string, version string) (templateData, error) {
+	version = strings.TrimPrefix(version, "v")
+	major, _, _ := strings.Cut(version, ".")
+	data := templateData{
+		Version:      version,
+		MajorVersion: major,
+		ReleaseDate:  time.Now().UTC().Format(time.DateOnly),
+		RepoOwner:    repoOwner,
+		RepoName:     repoName,
+		ReleaseURL:   releaseURL(repoOwner, repoName, version),
+	}
+	// Older maintenance streams might only be found on later pages and so
+	// all releases are considered:
+	tags := make([]string, 0, 100)
+	opts := github.ListOptions{Page: 1, PerPage: 100}
+	for {
+		releases, resp, err := client.ListReleases(ctx, repoOwner, repoName, &opts)
+		if err != nil {
+			return data, fmt.Errorf("failed to list releases: %w", err)
+		}
+		for _, release := range releases {
+			if release.GetDraft() {
+				continue
+			}
+			tags = append(tags, release.GetTagName())
+			if strings.TrimPrefix(release.GetTagName(), "v") == version && release.PublishedAt != nil {
+				data.ReleaseDate = release.GetPublishedAt().UTC().Format(time.DateOnly)
+			}
+		}
+		if resp == nil || resp.NextPage <= opts.Page {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+	data.PreviousVersion = previousVersion(version, tags)
+	return data, nil
+}
+
+// previousVersion returns the highest stable version lower than the given
+// one or an empty string if there is none.
+func previousVersion(version string, tags []string) string {
+	current, err := semver.NewVersion(strings.TrimPrefix(version, "v"))
+	if err != nil {
+		return ""
+	}
+	var result *semver.Version
+	for _, tag := range tags {
+		candidate, err := semver.NewVersion(strings.TrimPrefix(tag, "v"))
+		if err != nil || candidate.PreRelease != "" || !candidate.LessThan(*current) {
+			continue
+		}
+		if result == nil || result.LessThan(*candidate) {
+			result = candidate
+		}
+	}
+	if result == nil {
+		return ""
+	}
+	return result.String()
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v50/github"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRenderPostTexts(t *testing.T) {
+	data := templateData{
+		Version:         "11.2.1",
+		MajorVersion:    "11",
+		ReleaseDate:     "2024-09-26",
+		PreviousVersion: "11.2.0",
+		RepoOwner:       "grafana",
+		RepoName:        "grafana",
+		ReleaseURL:      "https://github.com/grafana/grafana/releases/tag/v11.2.1",
+	}
+
+	t.Run("defaults", func(t *testing.T) {
+		texts, err := renderPostTexts(defaultTemplates, data)
+		require.NoError(t, err)
+		require.Equal(t, postTexts{
+			Title:       "Changelog: Updates in Grafana 11.2.1",
+			TitlePrefix: "Changelog: Updates in Grafana ",
+			Footer:      "[Download page](https://grafana.com/grafana/download/11.2.1)\n[What's new highlights](https://grafana.com/docs/grafana/latest/whatsnew/)",
+			Fallback:    "[Full changelog](https://github.com/grafana/grafana/releases/tag/v11.2.1)",
+		}, texts)
+		require.Equal(t, "[Full changelog](https://github.com/grafana/grafana/releases/tag/v11.2.1)\n"+texts.Footer, fallbackChangelog(texts))
+	})
+
+	t.Run("custom", func(t *testing.T) {
+		texts, err := renderPostTexts(map[string]string{
+			templateTitle:       "Loki {{.Version}} released on {{.ReleaseDate}}",
+			templateHeader:      "Changes since {{.PreviousVersion}}:",
+			templateFooter:      "[Download]({{.DownloadURL}})",
+			templateFallback:    "See {{.ReleaseURL}}",
+			templateDownloadURL: "https://github.com/{{.RepoOwner}}/{{.RepoName}}/releases/tag/v{{.Version}}",
+		}, data)
+		require.NoError(t, err)
+		require.Equal(t, postTexts{
+			Title:       "Loki 11.2.1 released on 2024-09-26",
+			TitlePrefix: "Loki ",
+			Header:      "Changes since 11.2.0:",
+			Footer:      "[Download](https://github.com/grafana/grafana/releases/tag/v11.2.1)",
+			Fallback:    "See https://github.com/grafana/grafana/releases/tag/v11.2.1",
+		}, texts)
+	})
+
+	t.Run("title-without-version", func(t *testing.T) {
+		sources := map[string]string{templateTitle: "New release"}
+		texts, err := renderPostTexts(sources, data)
+		require.NoError(t, err)
+		require.Equal(t, "", texts.TitlePrefix)
+	})
+
+	t.Run("unknown-field", func(t *testing.T) {
+		_, err := renderPostTexts(map[string]string{templateTitle: "{{.Product}} {{.Version}}"}, data)
+		require.Error(t, err)
+	})
+}
+
+func TestPreviousVersion(t *testing.T) {
+	tags := []string{"v11.1.0", "v11.2.0", "v11.2.1", "v11.3.0-beta1", "v10.4.9", "nightly"}
+	require.Equal(t, "11.2.0", previousVersion("11.2.1", tags))
+	require.Equal(t, "11.2.1", previousVersion("v11.3.0", tags))
+	require.Equal(t, "", previousVersion("10.0.0", tags))
+}
+
+// pagedReleaseLister returns one page of releases per call.
+type pagedReleaseLister struct {
+	pages [][]*github.RepositoryRelease
+}
+
+func (l *pagedReleaseLister) ListReleases(ctx context.Context, owner string, repo string, opts *github.ListOptions) ([]*github.RepositoryRelease, *github.Response, error) {
+	if opts.Page < 1 || opts.Page > len(l.pages) {
+		return nil, nil, fmt.Errorf("unexpected page %d", opts.Page)
+	}
+	resp := &github.Response{}
+	if opts.Page < len(l.pages) {
+		resp.NextPage = opts.Page + 1
+	}
+	return l.pages[opts.Page-1], resp, nil
+}
+
+func TestNewTemplateData(t *testing.T) {
+	ctx := context.Background()
+	published := time.Date(2024, 3, 2, 10, 0, 0, 0, time.UTC)
+	lister := &pagedReleaseLister{
+		pages: [][]*github.RepositoryRelease{
+			{
+				{TagName: github.String("v11.2.1")},
+				{TagName: github.String("v11.2.0")},
+			},
+			{
+				{TagName: github.String("v10.4.3"), Draft: github.Bool(true)},
+				{TagName: github.String("v10.4.2"), PublishedAt: &github.Timestamp{Time: published}},
+				{TagName: github.String("v10.4.1")},
+			},
+		},
+	}
+
+	// The releases of the older stream are only on the second page:
+	data, err := newTemplateData(ctx, lister, "grafana", "grafana", "v10.4.2")
+	require.NoError(t, err)
+	require.Equal(t, "10.4.2", data.Version)
+	require.Equal(t, "10", data.MajorVersion)
+	require.Equal(t, "10.4.1", data.PreviousVersion)
+	require.Equal(t, "2024-03-02", data.ReleaseDate)
+
+	data, err = newTemplateData(ctx, lister, "grafana", "grafana", "10.4.4")
+	require.NoError(t, err)
+	require.Equal(t, "10.4.2", data.PreviousVersion)
+}
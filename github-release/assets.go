@@ -0,0 +1,245 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log/slog"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/google/go-github/v50/github"
+)
+
+// checksumsFilename is the name of the asset listing the SHA256 digests of
+// all other assets.
+const checksumsFilename = "SHA256SUMS"
+
+type AssetUploader interface {
+	ListReleaseAssets(ctx context.Context, owner, repo string, id int64, opts *github.ListOptions) ([]*github.ReleaseAsset, *github.Response, error)
+	DeleteReleaseAsset(ctx context.Context, owner, repo string, id int64) (*github.Response, error)
+	UploadReleaseAsset(ctx context.Context, owner, repo string, id int64, opts *github.UploadOptions, file *os.File) (*github.ReleaseAsset, *github.Response, error)
+	DownloadReleaseAsset(ctx context.Context, owner, repo string, id int64, followRedirectsClient *http.Client) (io.ReadCloser, string, error)
+}
+
+type UploadAssetsOptions struct {
+	// Verify downloads every uploaded asset again and compares its digest
+	// with the one of the local file.
+	Verify bool
+}
+
+// localAsset is a file that should be uploaded to the release.
+type localAsset struct {
+	Path   string
+	Name   string
+	Size   int64
+	Digest string
+}
+
+// FindAssets returns the files matching the comma- or newline-separated list
+// of glob patterns. Every pattern has to match at least one file.
+func FindAssets(patterns string) ([]string, error) {
+	seen := make(map[string]struct{})
+	result := make([]string, 0, 10)
+	for _, pattern := range strings.FieldsFunc(patterns, func(r rune) bool { return r == ',' || r == '\n' }) {
+		pattern = strings.TrimSpace(pattern)
+		if pattern == "" {
+			continue
+		}
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid asset pattern `%s`: %w", pattern, err)
+		}
+		files := 0
+		for _, match := range matches {
+			info, err := os.Stat(match)
+			if err != nil {
+				return nil, err
+			}
+			if info.IsDir() {
+				continue
+			}
+			files++
+			if _, found := seen[match]; found {
+				continue
+			}
+			seen[match] = struct{}{}
+			result = append(result, match)
+		}
+		if files == 0 {
+			return nil, fmt.Errorf("no files found matching `%s`", pattern)
+		}
+	}
+	sort.Strings(result)
+	return result, nil
+}
+
+// UploadAssets uploads the files and a SHA256SUMS file listing their digests
+// to the release. Existing assets with the same name are replaced so that
+// the upload can be retried.
+func UploadAssets(ctx context.Context, client AssetUploader, owner, repo string, releaseID int64, files []string, opts UploadAssetsOptions) ([]*github.ReleaseAsset, error) {
+	assets := make([]localAsset, 0, len(files)+1)
+	names := make(map[string]string, len(files))
+	for _, file := range files {
+		asset, err := newLocalAsset(file)
+		if err != nil {
+			return nil, err
+		}
+		if other, found := names[asset.Name]; found {
+			return nil, fmt.Errorf("assets `%s` and `%s` have the same name", other, file)
+		}
+		if asset.Name == checksumsFilename {
+			return nil, fmt.Errorf("asset `%s` conflicts with the generated %s file", file, checksumsFilename)
+		}
+		names[asset.Name] = file
+		assets = append(assets, asset)
+	}
+	if len(assets) == 0 {
+		return nil, nil
+	}
+
+	tmpDir, err := os.MkdirTemp("", "github-release-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(tmpDir)
+	checksumsFile := filepath.Join(tmpDir, checksumsFilename)
+	if err := os.WriteFile(checksumsFile, checksums(assets), 0o644); err != nil {
+		return nil, err
+	}
+	checksumsAsset, err := newLocalAsset(checksumsFile)
+	if err != nil {
+		return nil, err
+	}
+	assets = append(assets, checksumsAsset)
+
+	existing, err := listAssets(ctx, client, owner, repo, releaseID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list existing assets: %w", err)
+	}
+
+	result := make([]*github.ReleaseAsset, 0, len(assets))
+	for _, asset := range assets {
+		if old, found := existing[asset.Name]; found {
+			slog.Info("replacing existing asset", "name", asset.Name)
+			if _, err := client.DeleteReleaseAsset(ctx, owner, repo, old.GetID()); err != nil {
+				return result, fmt.Errorf("failed to delete existing asset `%s`: %w", asset.Name, err)
+			}
+		}
+		uploaded, err := uploadAsset(ctx, client, owner, repo, releaseID, asset)
+		if err != nil {
+			return result, err
+		}
+		if err := verifyAsset(ctx, client, owner, repo, uploaded, asset, opts.Verify); err != nil {
+			return result, err
+		}
+		result = append(result, uploaded)
+	}
+	return result, nil
+}
+
+func newLocalAsset(path string) (localAsset, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return localAsset{}, err
+	}
+	defer file.Close()
+	hash := sha256.New()
+	size, err := io.Copy(hash, file)
+	if err != nil {
+		return localAsset{}, fmt.Errorf("failed to read asset `%s`: %w", path, err)
+	}
+	return localAsset{
+		Path:   path,
+		Name:   filepath.Base(path),
+		Size:   size,
+		Digest: hex.EncodeToString(hash.Sum(nil)),
+	}, nil
+}
+
+// checksums renders the assets in the format of `sha256sum`.
+func checksums(assets []localAsset) []byte {
+	sorted := append([]localAsset{}, assets...)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Name < sorted[j].Name
+	})
+	out := bytes.Buffer{}
+	for _, asset := range sorted {
+		fmt.Fprintf(&out, "%s  %s\n", asset.Digest, asset.Name)
+	}
+	return out.Bytes()
+}
+
+func listAssets(ctx context.Context, client AssetUploader, owner, repo string, releaseID int64) (map[string]*github.ReleaseAsset, error) {
+	result := make(map[string]*github.ReleaseAsset)
+	opts := github.ListOptions{Page: 1, PerPage: 100}
+	for {
+		assets, resp, err := client.ListReleaseAssets(ctx, owner, repo, releaseID, &opts)
+		if err != nil {
+			return nil, err
+		}
+		for _, asset := range assets {
+			result[asset.GetName()] = asset
+		}
+		if resp == nil || resp.NextPage <= opts.Page {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+	return result, nil
+}
+
+func uploadAsset(ctx context.Context, client AssetUploader, owner, repo string, releaseID int64, asset localAsset) (*github.ReleaseAsset, error) {
+	file, err := os.Open(asset.Path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	mediaType := mime.TypeByExtension(filepath.Ext(asset.Name))
+	if mediaType == "" {
+		mediaType = "application/octet-stream"
+	}
+	slog.Info("uploading asset", "name", asset.Name, "size", asset.Size)
+	uploaded, _, err := client.UploadReleaseAsset(ctx, owner, repo, releaseID, &github.UploadOptions{
+		Name:      asset.Name,
+		MediaType: mediaType,
+	}, file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to upload asset `%s`: %w", asset.Name, err)
+	}
+	return uploaded, nil
+}
+
+// verifyAsset checks the size of the uploaded asset and optionally downloads
+// it again to compare the digests.
+func verifyAsset(ctx context.Context, client AssetUploader, owner, repo string, uploaded *github.ReleaseAsset, asset localAsset, download bool) error {
+	if uploaded.GetState() != "" && uploaded.GetState() != "uploaded" {
+		return fmt.Errorf("asset `%s` is in state `%s` after the upload", asset.Name, uploaded.GetState())
+	}
+	if int64(uploaded.GetSize()) != asset.Size {
+		return fmt.Errorf("asset `%s` has %d bytes after the upload instead of %d", asset.Name, uploaded.GetSize(), asset.Size)
+	}
+	if !download {
+		return nil
+	}
+	content, _, err := client.DownloadReleaseAsset(ctx, owner, repo, uploaded.GetID(), http.DefaultClient)
+	if err != nil {
+		return fmt.Errorf("failed to download asset `%s` for verification: %w", asset.Name, err)
+	}
+	defer content.Close()
+	hash := sha256.New()
+	if _, err := io.Copy(hash, content); err != nil {
+		return fmt.Errorf("failed to download asset `%s` for verification: %w", asset.Name, err)
+	}
+	if digest := hex.EncodeToString(hash.Sum(nil)); digest != asset.Digest {
+		return fmt.Errorf("asset `%s` has digest %s after the upload instead of %s", asset.Name, digest, asset.Digest)
+	}
+	return nil
+}
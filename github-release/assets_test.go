@@ -0,0 +1,198 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-github/v50/github"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeAssetUploader keeps the assets of a single release in memory.
+type fakeAssetUploader struct {
+	nextID   int64
+	assets   []*github.ReleaseAsset
+	contents map[int64][]byte
+	deleted  []int64
+	// corrupt is applied to the content of every uploaded asset.
+	corrupt func([]byte) []byte
+}
+
+func newFakeAssetUploader() *fakeAssetUploader {
+	return &fakeAssetUploader{
+		nextID:   100,
+		contents: make(map[int64][]byte),
+	}
+}
+
+func (f *fakeAssetUploader) ListReleaseAssets(ctx context.Context, owner, repo string, id int64, opts *github.ListOptions) ([]*github.ReleaseAsset, *github.Response, error) {
+	return f.assets, &github.Response{}, nil
+}
+
+func (f *fakeAssetUploader) DeleteReleaseAsset(ctx context.Context, owner, repo string, id int64) (*github.Response, error) {
+	f.deleted = append(f.deleted, id)
+	for idx, asset := range f.assets {
+		if asset.GetID() == id {
+			f.assets = append(f.assets[:idx], f.assets[idx+1:]...)
+			break
+		}
+	}
+	delete(f.contents, id)
+	return nil, nil
+}
+
+func (f *fakeAssetUploader) UploadReleaseAsset(ctx context.Context, owner, repo string, id int64, opts *github.UploadOptions, file *os.File) (*github.ReleaseAsset, *github.Response, error) {
+	content, err := io.ReadAll(file)
+	if err != nil {
+		return nil, nil, err
+	}
+	if f.corrupt != nil {
+		content = f.corrupt(content)
+	}
+	asset := f.addAsset(opts.Name, content)
+	return asset, nil, nil
+}
+
+func (f *fakeAssetUploader) DownloadReleaseAsset(ctx context.Context, owner, repo string, id int64, followRedirectsClient *http.Client) (io.ReadCloser, string, error) {
+	return io.NopCloser(bytes.NewReader(f.contents[id])), "", nil
+}
+
+func (f *fakeAssetUploader) addAsset(name string, content []byte) *github.ReleaseAsset {
+	asset := &github.ReleaseAsset{
+		ID:    github.Int64(f.nextID),
+		Name:  github.String(name),
+		Size:  github.Int(len(content)),
+		State: github.String("uploaded"),
+	}
+	f.nextID++
+	f.assets = append(f.assets, asset)
+	f.contents[asset.GetID()] = content
+	return asset
+}
+
+func (f *fakeAssetUploader) content(name string) string {
+	for _, asset := range f.assets {
+		if asset.GetName() == name {
+			return string(f.contents[asset.GetID()])
+		}
+	}
+	return ""
+}
+
+func writeAssetFiles(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+	}
+	return dir
+}
+
+func sha256Hex(content string) string {
+	sum := sha256.Sum256([]byte(content))
+	return hex.EncodeToString(sum[:])
+}
+
+func TestFindAssets(t *testing.T) {
+	dir := writeAssetFiles(t, map[string]string{
+		"dist/a.tar.gz":  "a",
+		"dist/b.tar.gz":  "b",
+		"dist/b.zip":     "b",
+		"other/notes.md": "notes",
+	})
+
+	t.Run("multiple-patterns", func(t *testing.T) {
+		files, err := FindAssets(filepath.Join(dir, "dist/*.tar.gz") + ",\n" + filepath.Join(dir, "dist/b.*"))
+		require.NoError(t, err)
+		require.Equal(t, []string{
+			filepath.Join(dir, "dist/a.tar.gz"),
+			filepath.Join(dir, "dist/b.tar.gz"),
+			filepath.Join(dir, "dist/b.zip"),
+		}, files)
+	})
+	t.Run("only-directories", func(t *testing.T) {
+		files, err := FindAssets(filepath.Join(dir, "*/*.md") + "," + filepath.Join(dir, "*"))
+		require.Error(t, err)
+		require.Nil(t, files)
+	})
+	t.Run("no-match", func(t *testing.T) {
+		_, err := FindAssets(filepath.Join(dir, "dist/*.deb"))
+		require.ErrorContains(t, err, "no files found")
+	})
+}
+
+func TestUploadAssets(t *testing.T) {
+	ctx := context.Background()
+	dir := writeAssetFiles(t, map[string]string{
+		"a.tar.gz": "content of a",
+		"b.zip":    "content of b",
+	})
+	files := []string{filepath.Join(dir, "a.tar.gz"), filepath.Join(dir, "b.zip")}
+	expectedChecksums := sha256Hex("content of a") + "  a.tar.gz\n" + sha256Hex("content of b") + "  b.zip\n"
+
+	t.Run("new-release", func(t *testing.T) {
+		client := newFakeAssetUploader()
+		uploaded, err := UploadAssets(ctx, client, "grafana", "grafana", 1, files, UploadAssetsOptions{Verify: true})
+		require.NoError(t, err)
+		require.Len(t, uploaded, 3)
+		require.Equal(t, "content of a", client.content("a.tar.gz"))
+		require.Equal(t, "content of b", client.content("b.zip"))
+		require.Equal(t, expectedChecksums, client.content(checksumsFilename))
+		require.Empty(t, client.deleted)
+	})
+
+	t.Run("replaces-existing-assets", func(t *testing.T) {
+		client := newFakeAssetUploader()
+		old := client.addAsset("a.tar.gz", []byte("old"))
+		oldChecksums := client.addAsset(checksumsFilename, []byte("old"))
+		unrelated := client.addAsset("c.txt", []byte("c"))
+		_, err := UploadAssets(ctx, client, "grafana", "grafana", 1, files, UploadAssetsOptions{Verify: true})
+		require.NoError(t, err)
+		require.ElementsMatch(t, []int64{old.GetID(), oldChecksums.GetID()}, client.deleted)
+		require.Equal(t, "content of a", client.content("a.tar.gz"))
+		require.Equal(t, expectedChecksums, client.content(checksumsFilename))
+		require.Equal(t, "c", client.content(unrelated.GetName()))
+	})
+
+	t.Run("duplicate-names", func(t *testing.T) {
+		other := writeAssetFiles(t, map[string]string{"a.tar.gz": "other"})
+		client := newFakeAssetUploader()
+		_, err := UploadAssets(ctx, client, "grafana", "grafana", 1, append(files, filepath.Join(other, "a.tar.gz")), UploadAssetsOptions{})
+		require.ErrorContains(t, err, "same name")
+		require.Empty(t, client.assets)
+	})
+
+	t.Run("size-mismatch", func(t *testing.T) {
+		client := newFakeAssetUploader()
+		client.corrupt = func(content []byte) []byte {
+			return content[1:]
+		}
+		_, err := UploadAssets(ctx, client, "grafana", "grafana", 1, files, UploadAssetsOptions{})
+		require.ErrorContains(t, err, "bytes after the upload")
+	})
+
+	t.Run("digest-mismatch", func(t *testing.T) {
+		client := newFakeAssetUploader()
+		client.corrupt = func(content []byte) []byte {
+			return bytes.ToUpper(content)
+		}
+		_, err := UploadAssets(ctx, client, "grafana", "grafana", 1, files, UploadAssetsOptions{})
+		require.NoError(t, err)
+
+		client = newFakeAssetUploader()
+		client.corrupt = func(content []byte) []byte {
+			return bytes.ToUpper(content)
+		}
+		_, err = UploadAssets(ctx, client, "grafana", "grafana", 1, files, UploadAssetsOptions{Verify: true})
+		require.ErrorContains(t, err, "digest")
+	})
+}
@@ -13,44 +13,82 @@ type ReleaseCreator interface {
 	CreateRelease(ctx context.Context, owner, repo string, release *github.RepositoryRelease) (*github.RepositoryRelease, *github.Response, error)
 	GetReleaseByTag(ctx context.Context, owner, repo, tag string) (*github.RepositoryRelease, *github.Response, error)
 	EditRelease(ctx context.Context, owner, repo string, id int64, release *github.RepositoryRelease) (*github.RepositoryRelease, *github.Response, error)
+	ListReleases(ctx context.Context, owner, repo string, opts *github.ListOptions) ([]*github.RepositoryRelease, *github.Response, error)
 }
 
-func CreateRelease(ctx context.Context, client ReleaseCreator, owner, repo, tag string, release *github.RepositoryRelease) (string, error) {
+// CreateRelease creates the release for the given tag or updates it if it
+// already exists. Draft releases are updated as well. An existing draft is
+// only published if release.Draft is explicitly set to false while a
+// published release is never turned back into a draft.
+func CreateRelease(ctx context.Context, client ReleaseCreator, owner, repo, tag string, release *github.RepositoryRelease) (*github.RepositoryRelease, error) {
 	tagExists, err := verifyTagExists(ctx, client, owner, repo, tag)
 	if err != nil {
-		return "", fmt.Errorf("failed to verify tag exists: %w", err)
+		return nil, fmt.Errorf("failed to verify tag exists: %w", err)
 	}
 
 	if !tagExists {
-		return "", fmt.Errorf("tag `%s` does not exist", tag)
+		return nil, fmt.Errorf("tag `%s` does not exist", tag)
 	}
 
-	rel, resp, err := client.GetReleaseByTag(ctx, owner, repo, tag)
+	rel, err := findRelease(ctx, client, owner, repo, tag)
 	if err != nil {
-		if resp.StatusCode != http.StatusNotFound {
-			return "", fmt.Errorf("failed to check for existing release: %w", err)
-		}
+		return nil, fmt.Errorf("failed to check for existing release: %w", err)
 	}
 
 	if rel != nil {
 		rel.Name = release.Name
 		rel.Body = release.Body
 		rel.MakeLatest = release.MakeLatest
+		rel.Prerelease = release.Prerelease
+		if rel.GetDraft() && release.Draft != nil && !release.GetDraft() {
+			rel.Draft = github.Bool(false)
+		}
 
 		r, _, err := client.EditRelease(ctx, owner, repo, rel.GetID(), rel)
 		if err != nil {
-			return "", fmt.Errorf("failed to update existing release: %w", err)
+			return nil, fmt.Errorf("failed to update existing release: %w", err)
 		}
 
-		return r.GetHTMLURL(), nil
+		return r, nil
 	}
 
 	rel, _, err = client.CreateRelease(ctx, owner, repo, release)
 	if err != nil {
-		return "", err
+		return nil, err
+	}
+
+	return rel, nil
+}
+
+// findRelease returns the release for the given tag or nil if there is none.
+// GetReleaseByTag only considers published releases and so drafts are looked
+// up in the list of all releases.
+func findRelease(ctx context.Context, client ReleaseCreator, owner, repo, tag string) (*github.RepositoryRelease, error) {
+	rel, resp, err := client.GetReleaseByTag(ctx, owner, repo, tag)
+	if err != nil && (resp == nil || resp.StatusCode != http.StatusNotFound) {
+		return nil, err
+	}
+	if rel != nil {
+		return rel, nil
 	}
 
-	return "", nil
+	opts := github.ListOptions{Page: 1, PerPage: 100}
+	for {
+		releases, resp, err := client.ListReleases(ctx, owner, repo, &opts)
+		if err != nil {
+			return nil, err
+		}
+		for _, r := range releases {
+			if r.GetDraft() && r.GetTagName() == tag {
+				return r, nil
+			}
+		}
+		if resp == nil || resp.NextPage <= opts.Page {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+	return nil, nil
 }
 
 func verifyTagExists(ctx context.Context, client ReleaseCreator, owner string, repo string, tag string) (bool, error) {
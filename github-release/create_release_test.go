@@ -6,6 +6,7 @@ import (
 	"testing"
 
 	"github.com/google/go-github/v50/github"
+	"github.com/stretchr/testify/require"
 )
 
 type TestReleaseCreator struct {
@@ -13,6 +14,7 @@ type TestReleaseCreator struct {
 	CreateReleaseFunc   func(ctx context.Context, owner, repo string, release *github.RepositoryRelease) (*github.RepositoryRelease, *github.Response, error)
 	GetReleaseByTagFunc func(ctx context.Context, owner, repo, tag string) (*github.RepositoryRelease, *github.Response, error)
 	EditReleaseFunc     func(ctx context.Context, owner, repo string, id int64, release *github.RepositoryRelease) (*github.RepositoryRelease, *github.Response, error)
+	ListReleasesFunc    func(ctx context.Context, owner, repo string, opts *github.ListOptions) ([]*github.RepositoryRelease, *github.Response, error)
 }
 
 func (c *TestReleaseCreator) ListTags(ctx context.Context, owner string, repo string, opts *github.ListOptions) ([]*github.RepositoryTag, *github.Response, error) {
@@ -31,6 +33,13 @@ func (c *TestReleaseCreator) EditRelease(ctx context.Context, owner, repo string
 	return c.EditReleaseFunc(ctx, owner, repo, id, release)
 }
 
+func (c *TestReleaseCreator) ListReleases(ctx context.Context, owner, repo string, opts *github.ListOptions) ([]*github.RepositoryRelease, *github.Response, error) {
+	if c.ListReleasesFunc == nil {
+		return nil, &github.Response{}, nil
+	}
+	return c.ListReleasesFunc(ctx, owner, repo, opts)
+}
+
 func TestCreateRelease(t *testing.T) {
 	ctx := context.Background()
 
@@ -160,4 +169,102 @@ func TestCreateRelease(t *testing.T) {
 			t.Fatal("EditRelease was not called")
 		}
 	})
+
+	notFoundFunc := func(ctx context.Context, owner, repo, tag string) (*github.RepositoryRelease, *github.Response, error) {
+		return nil, &github.Response{
+			Response: &http.Response{
+				StatusCode: http.StatusNotFound,
+			},
+		}, &github.ErrorResponse{}
+	}
+
+	t.Run("It should update an existing draft release and keep it a draft", func(t *testing.T) {
+		var edited *github.RepositoryRelease
+		client := &TestReleaseCreator{
+			ListTagsFunc: listTagsFunc,
+			CreateReleaseFunc: func(ctx context.Context, owner, repo string, release *github.RepositoryRelease) (*github.RepositoryRelease, *github.Response, error) {
+				t.Fatal("CreateRelease should not be called")
+				return nil, nil, nil
+			},
+			GetReleaseByTagFunc: notFoundFunc,
+			ListReleasesFunc: func(ctx context.Context, owner, repo string, opts *github.ListOptions) ([]*github.RepositoryRelease, *github.Response, error) {
+				return []*github.RepositoryRelease{
+					{ID: github.Int64(1), TagName: github.String("v1.2.4"), Draft: github.Bool(true)},
+					{ID: github.Int64(2), TagName: github.String("v1.2.3"), Draft: github.Bool(true)},
+				}, &github.Response{}, nil
+			},
+			EditReleaseFunc: func(ctx context.Context, owner, repo string, id int64, release *github.RepositoryRelease) (*github.RepositoryRelease, *github.Response, error) {
+				require.Equal(t, int64(2), id)
+				edited = release
+				return release, nil, nil
+			},
+		}
+
+		rel, err := CreateRelease(ctx, client, "grafana", "grafana", "v1.2.3", &github.RepositoryRelease{
+			Body:       github.String("new"),
+			Prerelease: github.Bool(false),
+		})
+		require.NoError(t, err)
+		require.Equal(t, edited, rel)
+		require.Equal(t, "new", rel.GetBody())
+		require.True(t, rel.GetDraft())
+	})
+
+	t.Run("It should publish an existing draft release", func(t *testing.T) {
+		client := &TestReleaseCreator{
+			ListTagsFunc:        listTagsFunc,
+			CreateReleaseFunc:   createReleaseFunc,
+			GetReleaseByTagFunc: notFoundFunc,
+			ListReleasesFunc: func(ctx context.Context, owner, repo string, opts *github.ListOptions) ([]*github.RepositoryRelease, *github.Response, error) {
+				return []*github.RepositoryRelease{
+					{ID: github.Int64(2), TagName: github.String("v1.2.3"), Draft: github.Bool(true)},
+				}, &github.Response{}, nil
+			},
+			EditReleaseFunc: func(ctx context.Context, owner, repo string, id int64, release *github.RepositoryRelease) (*github.RepositoryRelease, *github.Response, error) {
+				return release, nil, nil
+			},
+		}
+
+		rel, err := CreateRelease(ctx, client, "grafana", "grafana", "v1.2.3", &github.RepositoryRelease{
+			Draft:      github.Bool(false),
+			MakeLatest: github.String("legacy"),
+		})
+		require.NoError(t, err)
+		require.False(t, rel.GetDraft())
+		require.Equal(t, "legacy", rel.GetMakeLatest())
+	})
+
+	t.Run("It should not turn a published release into a draft", func(t *testing.T) {
+		client := &TestReleaseCreator{
+			ListTagsFunc:        listTagsFunc,
+			CreateReleaseFunc:   createReleaseFunc,
+			GetReleaseByTagFunc: getReleaseByTagFunc,
+			EditReleaseFunc: func(ctx context.Context, owner, repo string, id int64, release *github.RepositoryRelease) (*github.RepositoryRelease, *github.Response, error) {
+				return release, nil, nil
+			},
+		}
+
+		rel, err := CreateRelease(ctx, client, "grafana", "grafana", "v1.2.3", &github.RepositoryRelease{
+			Draft: github.Bool(true),
+		})
+		require.NoError(t, err)
+		require.False(t, rel.GetDraft())
+	})
+
+	t.Run("It should create a draft pre-release", func(t *testing.T) {
+		client := &TestReleaseCreator{
+			ListTagsFunc:        listTagsFunc,
+			CreateReleaseFunc:   createReleaseFunc,
+			GetReleaseByTagFunc: notFoundFunc,
+			EditReleaseFunc:     editReleaseFunc,
+		}
+
+		rel, err := CreateRelease(ctx, client, "grafana", "grafana", "v1.2.3", &github.RepositoryRelease{
+			Draft:      github.Bool(true),
+			Prerelease: github.Bool(true),
+		})
+		require.NoError(t, err)
+		require.True(t, rel.GetDraft())
+		require.True(t, rel.GetPrerelease())
+	})
 }
@@ -12,6 +12,7 @@ import (
 	"github.com/google/go-github/v50/github"
 	"github.com/grafana/grafana-github-actions-go/pkg/changelog"
 	"github.com/grafana/grafana-github-actions-go/pkg/toolkit"
+	"github.com/grafana/grafana-github-actions-go/pkg/versions"
 	"github.com/spf13/pflag"
 )
 
@@ -25,6 +26,70 @@ func LatestString(latest bool) *string {
 	return github.String("false")
 }
 
+// parseLatest maps the `latest` input to the value of `make_latest`. Besides
+// `1` and `0`, `legacy` lets GitHub decide based on the creation date and
+// semantic version of the release.
+func parseLatest(input string) (*string, error) {
+	switch input {
+	case "1", "true":
+		return LatestString(true), nil
+	case "", "0", "false":
+		return LatestString(false), nil
+	case "legacy":
+		return github.String("legacy"), nil
+	}
+	return nil, fmt.Errorf("unsupported value `%s` for latest; expected 1, 0, or legacy", input)
+}
+
+// isPrerelease determines whether the release should be marked as
+// pre-release. With `auto` (the default) this is the case if the version has
+// a prerelease component like `-beta1`.
+func isPrerelease(version string, input string) (bool, error) {
+	switch input {
+	case "1", "true":
+		return true, nil
+	case "0", "false":
+		return false, nil
+	case "", "auto":
+		v, err := versions.Parse(version)
+		if err != nil {
+			return false, err
+		}
+		return v.Prerelease != "", nil
+	}
+	return false, fmt.Errorf("unsupported value `%s` for prerelease; expected auto, 1, or 0", input)
+}
+
+// latestForPrerelease returns the value of `make_latest` for the release. A
+// pre-release cannot be the latest release: If it was detected automatically,
+// it is not marked as latest instead, but if it was requested explicitly, the
+// inputs contradict each other.
+func latestForPrerelease(latest *string, prerelease bool, prereleaseInput string) (*string, error) {
+	if !prerelease || *latest != "true" {
+		return latest, nil
+	}
+	switch prereleaseInput {
+	case "1", "true":
+		return nil, fmt.Errorf("a pre-release cannot be marked as latest")
+	}
+	return LatestString(false), nil
+}
+
+// draftState returns the draft flag for the release. nil keeps the state of
+// an existing release while new releases are published.
+func draftState(draft bool, publish bool) (*bool, error) {
+	if draft && publish {
+		return nil, fmt.Errorf("draft and publish cannot be requested at the same time")
+	}
+	if draft {
+		return github.Bool(true), nil
+	}
+	if publish {
+		return github.Bool(false), nil
+	}
+	return nil, nil
+}
+
 func main() {
 	log := slog.New(slog.NewTextHandler(os.Stdout, nil))
 	ctx := context.Background()
@@ -48,7 +113,13 @@ func main() {
 
 	tk, err := toolkit.Init(
 		ctx,
-		toolkit.WithRegisteredInput("latest", "`true` for marking the release as latest, otherwise not"),
+		toolkit.WithRegisteredInput("latest", "`1` for marking the release as latest, `legacy` for letting GitHub decide, otherwise not"),
+		toolkit.WithRegisteredInput("draft", "`1` for creating the release as draft"),
+		toolkit.WithRegisteredInput("prerelease", "`auto` for marking the release as pre-release if the version has a prerelease component, `1` or `0` to override"),
+		toolkit.WithRegisteredInput("publish", "`1` for publishing an existing draft release"),
+		toolkit.WithRegisteredInput("changelog_format", "Format of the changelog; anything but `default` renders it from the milestone instead of loading it from CHANGELOG.md"),
+		toolkit.WithRegisteredInput("assets", "Comma- or newline-separated glob patterns of files to upload to the release"),
+		toolkit.WithRegisteredInput("verify_assets", "`1` for downloading uploaded assets again and comparing their SHA256 digests"),
 	)
 	if err != nil {
 		log.Error("failed to initialize toolkit", "error", err)
@@ -66,7 +137,27 @@ func main() {
 	}()
 
 	log = log.With("tag", tag, "repo", ownerRepo)
-	latest := tk.MustGetBoolInput(ctx, "latest")
+	latest, err := parseLatest(tk.MustGetInput(ctx, "latest"))
+	if err != nil {
+		panic(err)
+	}
+	prereleaseInput := tk.MustGetInput(ctx, "prerelease")
+	prerelease, err := isPrerelease(version, prereleaseInput)
+	if err != nil {
+		panic(err)
+	}
+	requestedLatest := *latest
+	latest, err = latestForPrerelease(latest, prerelease, prereleaseInput)
+	if err != nil {
+		panic(err)
+	}
+	if *latest != requestedLatest {
+		log.Warn("version is a pre-release and is therefore not marked as latest", "version", version)
+	}
+	draft, err := draftState(tk.MustGetBoolInput(ctx, "draft"), tk.MustGetBoolInput(ctx, "publish"))
+	if err != nil {
+		panic(err)
+	}
 
 	elems := strings.Split(ownerRepo, "/")
 	owner := elems[0]
@@ -74,16 +165,35 @@ func main() {
 
 	gh := tk.GitHubClient()
 
-	changelogContent, err := retrieveChangelog(ctx, gh, owner, repo, version)
+	changelogFormat := tk.MustGetInput(ctx, "changelog_format")
+
+	changelogContent, err := retrieveChangelog(ctx, tk, owner, repo, version, changelogFormat)
 	if err != nil {
 		panic(fmt.Sprintf("failed to retrieve changelog for %s", version))
 	}
 
+	var assets []string
+	if patterns := tk.MustGetInput(ctx, "assets"); patterns != "" {
+		assets, err = FindAssets(patterns)
+		if err != nil {
+			panic(err)
+		}
+	}
+
 	releaseTitle := version
 
 	if doPreview {
 		fmt.Println("no release will be created but this is what it would look like:")
-		fmt.Printf("TITLE: %s\n\n%s\n", releaseTitle, changelogContent)
+		fmt.Printf("TITLE: %s\n", releaseTitle)
+		fmt.Printf("DRAFT: %s\n", previewDraft(draft))
+		fmt.Printf("PRERELEASE: %t\n", prerelease)
+		fmt.Printf("LATEST: %s\n\n%s\n", *latest, changelogContent)
+		if len(assets) > 0 {
+			fmt.Printf("\nASSETS (plus %s):\n", checksumsFilename)
+			for _, asset := range assets {
+				fmt.Printf("- %s\n", asset)
+			}
+		}
 		return
 	}
 
@@ -91,27 +201,59 @@ func main() {
 		TagName:    github.String(tag),
 		Name:       github.String(releaseTitle),
 		Body:       github.String(changelogContent),
-		MakeLatest: LatestString(latest),
+		MakeLatest: latest,
+		Draft:      draft,
+		Prerelease: github.Bool(prerelease),
 	}
 
-	url, err := CreateRelease(ctx, gh.Repositories, owner, repo, tag, newRelease)
+	rel, err := CreateRelease(ctx, gh.Repositories, owner, repo, tag, newRelease)
 	if err != nil {
 		panic(err)
 	}
 
-	log.Info("release available", "url", url)
+	if len(assets) > 0 {
+		uploaded, err := UploadAssets(ctx, gh.Repositories, owner, repo, rel.GetID(), assets, UploadAssetsOptions{
+			Verify: tk.MustGetBoolInput(ctx, "verify_assets"),
+		})
+		if err != nil {
+			panic(err)
+		}
+		log.Info("assets uploaded", "count", len(uploaded))
+	}
+
+	log.Info("release available", "url", rel.GetHTMLURL(), "draft", rel.GetDraft(), "prerelease", rel.GetPrerelease())
+}
+
+func previewDraft(draft *bool) string {
+	if draft == nil {
+		return "unchanged (new releases are published)"
+	}
+	return fmt.Sprintf("%t", *draft)
 }
 
-func retrieveChangelog(ctx context.Context, gh *github.Client, owner string, repo string, version string) (string, error) {
-	loader := changelog.NewLoader(gh)
-	output, err := loader.LoadContent(ctx, owner, repo, version, &changelog.LoaderOptions{
-		RemoveHeading: true,
-	})
+func retrieveChangelog(ctx context.Context, tk *toolkit.Toolkit, owner string, repo string, version string, format string) (string, error) {
+	output, err := changelog.LoadOrBuild(ctx, tk, changelog.NewGitHubDataSource(tk), owner, repo, version, format)
 	if err != nil {
 		return "", err
 	}
-	return fmt.Sprintf(`[Download page](https://grafana.com/grafana/download/%s)
-[What's new highlights](https://grafana.com/docs/grafana/latest/whatsnew/)
+	return composeReleaseBody(output, version), nil
+}
+
+// composeReleaseBody uses the highlights of the changelog as opening
+// paragraph of the release followed by the download links and the remaining
+// changelog.
+func composeReleaseBody(content string, version string) string {
+	highlights, rest := changelog.SplitHighlights(content)
+	links := fmt.Sprintf(`[Download page](https://grafana.com/grafana/download/%s)
+[What's new highlights](https://grafana.com/docs/grafana/latest/whatsnew/)`, version)
+	if highlights == "" {
+		return fmt.Sprintf(`%s
+
+%s`, links, rest)
+	}
+	return fmt.Sprintf(`%s
+
+%s
 
-%s`, version, output), nil
+%s`, highlights, links, rest)
 }
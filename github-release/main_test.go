@@ -1 +1,90 @@
 package main
+
+import (
+	"testing"
+
+	"github.com/google/go-github/v50/github"
+	"github.com/stretchr/testify/require"
+)
+
+func TestComposeReleaseBody(t *testing.T) {
+	links := "[Download page](https://grafana.com/grafana/download/1.0.0)\n[What's new highlights](https://grafana.com/docs/grafana/latest/whatsnew/)"
+	t.Run("without-highlights", func(t *testing.T) {
+		require.Equal(t, links+"\n\n### Bug fixes\n\n- Fix", composeReleaseBody("### Bug fixes\n\n- Fix", "1.0.0"))
+	})
+	t.Run("with-highlights", func(t *testing.T) {
+		content := "### Highlights\n\n**New thing.** Summary\n\n### Bug fixes\n\n- Fix"
+		require.Equal(t, "**New thing.** Summary\n\n"+links+"\n\n### Bug fixes\n\n- Fix", composeReleaseBody(content, "1.0.0"))
+	})
+}
+
+func TestParseLatest(t *testing.T) {
+	for input, expected := range map[string]string{
+		"":       "false",
+		"0":      "false",
+		"1":      "true",
+		"true":   "true",
+		"legacy": "legacy",
+	} {
+		latest, err := parseLatest(input)
+		require.NoError(t, err)
+		require.Equal(t, expected, *latest, input)
+	}
+	_, err := parseLatest("maybe")
+	require.Error(t, err)
+}
+
+func TestIsPrerelease(t *testing.T) {
+	for _, tc := range []struct {
+		version  string
+		input    string
+		expected bool
+	}{
+		{version: "1.2.3", input: "", expected: false},
+		{version: "1.2.3-beta1", input: "", expected: true},
+		{version: "1.2.3-beta1", input: "auto", expected: true},
+		{version: "1.2.3+security-01", input: "auto", expected: false},
+		{version: "1.2.3-beta1", input: "0", expected: false},
+		{version: "1.2.3", input: "1", expected: true},
+	} {
+		prerelease, err := isPrerelease(tc.version, tc.input)
+		require.NoError(t, err)
+		require.Equal(t, tc.expected, prerelease, "%s with %q", tc.version, tc.input)
+	}
+	_, err := isPrerelease("1.2.3", "sometimes")
+	require.Error(t, err)
+}
+
+func TestLatestForPrerelease(t *testing.T) {
+	latest, err := latestForPrerelease(LatestString(true), false, "auto")
+	require.NoError(t, err)
+	require.Equal(t, "true", *latest)
+
+	latest, err = latestForPrerelease(LatestString(true), true, "auto")
+	require.NoError(t, err)
+	require.Equal(t, "false", *latest)
+
+	latest, err = latestForPrerelease(github.String("legacy"), true, "1")
+	require.NoError(t, err)
+	require.Equal(t, "legacy", *latest)
+
+	_, err = latestForPrerelease(LatestString(true), true, "1")
+	require.Error(t, err)
+}
+
+func TestDraftState(t *testing.T) {
+	draft, err := draftState(false, false)
+	require.NoError(t, err)
+	require.Nil(t, draft)
+
+	draft, err = draftState(true, false)
+	require.NoError(t, err)
+	require.True(t, *draft)
+
+	draft, err = draftState(false, true)
+	require.NoError(t, err)
+	require.False(t, *draft)
+
+	_, err = draftState(true, true)
+	require.Error(t, err)
+}
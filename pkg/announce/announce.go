@@ -0,0 +1,101 @@
+// Package announce publishes release announcements to various targets like
+// Discourse, Slack, or an Atom feed.
+package announce
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/rs/zerolog"
+)
+
+const (
+	TargetDiscourse = "discourse"
+	TargetSlack     = "slack"
+	TargetWebhook   = "webhook"
+	TargetMastodon  = "mastodon"
+	TargetFeed      = "feed"
+)
+
+// Announcement is the content shared by all targets.
+type Announcement struct {
+	Title   string `json:"title"`
+	Version string `json:"version"`
+	// Body is the changelog in Markdown.
+	Body string `json:"body"`
+	// URL points to the full release notes.
+	URL string `json:"url"`
+}
+
+// Announcer publishes an announcement to a single target.
+type Announcer interface {
+	// Name identifies the target in logs and errors.
+	Name() string
+	Announce(ctx context.Context, a Announcement) error
+}
+
+// ParseTargets splits a comma-separated list of targets and checks that all
+// of them are supported.
+func ParseTargets(raw string) ([]string, error) {
+	result := make([]string, 0, 3)
+	for _, target := range strings.Split(raw, ",") {
+		target = strings.ToLower(strings.TrimSpace(target))
+		switch target {
+		case "":
+			continue
+		case TargetDiscourse, TargetSlack, TargetWebhook, TargetMastodon, TargetFeed:
+			result = append(result, target)
+		default:
+			return nil, fmt.Errorf("unsupported announcement target: %s", target)
+		}
+	}
+	return result, nil
+}
+
+// AnnounceAll publishes the announcement using all announcers. A failing
+// target doesn't prevent the others from being notified; all errors are
+// returned together.
+func AnnounceAll(ctx context.Context, announcers []Announcer, a Announcement) error {
+	logger := zerolog.Ctx(ctx)
+	errs := make([]error, 0, len(announcers))
+	for _, announcer := range announcers {
+		logger.Info().Msgf("Announcing %s via %s", a.Version, announcer.Name())
+		if err := announcer.Announce(ctx, a); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", announcer.Name(), err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// postJSON sends the payload to the URL and treats any non-2xx response as
+// error.
+func postJSON(ctx context.Context, client *http.Client, url string, headers map[string]string, payload any) error {
+	body := bytes.Buffer{}
+	if err := json.NewEncoder(&body).Encode(payload); err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, &body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("unexpected status code %d: %s", resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+	return nil
+}
@@ -0,0 +1,150 @@
+package announce
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/stretchr/testify/require"
+)
+
+type recordedRequest struct {
+	Path    string
+	Headers http.Header
+	Payload map[string]string
+}
+
+// recordingServer records the requests it receives. The handler runs in
+// the goroutines of the server and so decoding errors are collected and only
+// reported by Requests, which is called from the test.
+type recordingServer struct {
+	*httptest.Server
+	lock     sync.Mutex
+	requests []recordedRequest
+	errs     []error
+}
+
+func newRecordingServer(t *testing.T, status int) *recordingServer {
+	rec := &recordingServer{}
+	rec.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		payload := make(map[string]string)
+		err := json.NewDecoder(r.Body).Decode(&payload)
+		rec.lock.Lock()
+		defer rec.lock.Unlock()
+		if err != nil {
+			rec.errs = append(rec.errs, fmt.Errorf("failed to decode request to %s: %w", r.URL.Path, err))
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		rec.requests = append(rec.requests, recordedRequest{Path: r.URL.Path, Headers: r.Header, Payload: payload})
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(rec.Close)
+	return rec
+}
+
+// Requests fails the test if any request could not be decoded and returns
+// the recorded requests otherwise.
+func (rec *recordingServer) Requests(t *testing.T) []recordedRequest {
+	t.Helper()
+	rec.lock.Lock()
+	defer rec.lock.Unlock()
+	require.NoError(t, errors.Join(rec.errs...))
+	return append([]recordedRequest{}, rec.requests...)
+}
+
+var testAnnouncement = Announcement{
+	Title:   "Changelog: Updates in Grafana 1.0.0",
+	Version: "1.0.0",
+	Body:    "### Features and enhancements\n\n- **Alerting:** Add something. [#123](https://github.com/grafana/grafana/issues/123)",
+	URL:     "https://github.com/grafana/grafana/releases/tag/v1.0.0",
+}
+
+func TestParseTargets(t *testing.T) {
+	targets, err := ParseTargets("discourse, Slack,,feed")
+	require.NoError(t, err)
+	require.Equal(t, []string{TargetDiscourse, TargetSlack, TargetFeed}, targets)
+
+	_, err = ParseTargets("discourse,pigeon")
+	require.Error(t, err)
+}
+
+func TestSlackAnnouncer(t *testing.T) {
+	srv := newRecordingServer(t, http.StatusOK)
+	require.NoError(t, NewSlackAnnouncer(srv.URL, srv.Client()).Announce(context.Background(), testAnnouncement))
+	require.Len(t, srv.Requests(t), 1)
+	require.Equal(t, "*Changelog: Updates in Grafana 1.0.0*\nhttps://github.com/grafana/grafana/releases/tag/v1.0.0\n\n*Features and enhancements*\n\n- *Alerting:* Add something. <https://github.com/grafana/grafana/issues/123|#123>", srv.Requests(t)[0].Payload["text"])
+}
+
+func TestTruncateSlackText(t *testing.T) {
+	t.Run("short", func(t *testing.T) {
+		require.Equal(t, "- a\n- b", truncateSlackText("- a\n- b"))
+	})
+	t.Run("cut-at-line-break", func(t *testing.T) {
+		text := strings.Repeat("a", 2000) + "\n" + strings.Repeat("b", 2000)
+		require.Equal(t, strings.Repeat("a", 2000)+"\n…", truncateSlackText(text))
+	})
+	t.Run("no-line-break", func(t *testing.T) {
+		// Each "ä" takes two bytes and so the limit falls in the middle of
+		// a rune:
+		text := "a" + strings.Repeat("ä", 2000)
+		result := truncateSlackText(text)
+		require.True(t, utf8.ValidString(result))
+		require.Equal(t, "a"+strings.Repeat("ä", 1499)+"\n…", result)
+	})
+}
+
+func TestWebhookAnnouncer(t *testing.T) {
+	srv := newRecordingServer(t, http.StatusNoContent)
+	err := NewWebhookAnnouncer(srv.URL, map[string]string{"X-Token": "secret"}, srv.Client()).Announce(context.Background(), testAnnouncement)
+	require.NoError(t, err)
+	require.Len(t, srv.Requests(t), 1)
+	require.Equal(t, "secret", srv.Requests(t)[0].Headers.Get("X-Token"))
+	require.Equal(t, map[string]string{
+		"title":   testAnnouncement.Title,
+		"version": testAnnouncement.Version,
+		"body":    testAnnouncement.Body,
+		"url":     testAnnouncement.URL,
+	}, srv.Requests(t)[0].Payload)
+}
+
+func TestMastodonAnnouncer(t *testing.T) {
+	srv := newRecordingServer(t, http.StatusOK)
+	err := NewMastodonAnnouncer(srv.URL+"/", "token", "unlisted", srv.Client()).Announce(context.Background(), testAnnouncement)
+	require.NoError(t, err)
+	require.Len(t, srv.Requests(t), 1)
+	require.Equal(t, "/api/v1/statuses", srv.Requests(t)[0].Path)
+	require.Equal(t, "Bearer token", srv.Requests(t)[0].Headers.Get("Authorization"))
+	require.Equal(t, map[string]string{
+		"status":     "Changelog: Updates in Grafana 1.0.0\n\nhttps://github.com/grafana/grafana/releases/tag/v1.0.0",
+		"visibility": "unlisted",
+	}, srv.Requests(t)[0].Payload)
+}
+
+type failingAnnouncer struct{}
+
+func (f failingAnnouncer) Name() string {
+	return "failing"
+}
+
+func (f failingAnnouncer) Announce(ctx context.Context, a Announcement) error {
+	return errors.New("broken")
+}
+
+func TestAnnounceAll(t *testing.T) {
+	// A failing target must not prevent the others from being notified:
+	srv := newRecordingServer(t, http.StatusOK)
+	err := AnnounceAll(context.Background(), []Announcer{
+		failingAnnouncer{},
+		NewWebhookAnnouncer(srv.URL, nil, srv.Client()),
+	}, testAnnouncement)
+	require.EqualError(t, err, "failing: broken")
+	require.Len(t, srv.Requests(t), 1)
+}
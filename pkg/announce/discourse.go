@@ -0,0 +1,35 @@
+package announce
+
+import (
+	"context"
+
+	"github.com/grafana/grafana-github-actions-go/pkg/community"
+)
+
+type discourseAnnouncer struct {
+	community *community.Community
+	post      community.PostInput
+	opts      *community.PostOptions
+}
+
+// NewDiscourseAnnouncer creates or updates a topic using the given post as
+// template. Its title and body are replaced by the ones of the announcement.
+func NewDiscourseAnnouncer(c *community.Community, post community.PostInput, opts *community.PostOptions) Announcer {
+	return &discourseAnnouncer{
+		community: c,
+		post:      post,
+		opts:      opts,
+	}
+}
+
+func (d *discourseAnnouncer) Name() string {
+	return TargetDiscourse
+}
+
+func (d *discourseAnnouncer) Announce(ctx context.Context, a Announcement) error {
+	post := d.post
+	post.Title = a.Title
+	post.Body = a.Body
+	_, err := d.community.CreateOrUpdatePost(ctx, post, d.opts)
+	return err
+}
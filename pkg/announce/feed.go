@@ -0,0 +1,174 @@
+package announce
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/google/go-github/v50/github"
+)
+
+const atomNamespace = "http://www.w3.org/2005/Atom"
+
+// defaultFeedMaxEntries limits the number of entries kept inside the feed if
+// nothing else is configured.
+const defaultFeedMaxEntries = 50
+
+// ContentsClient is the part of the GitHub API required for updating the
+// feed file. It is implemented by github.RepositoriesService.
+type ContentsClient interface {
+	GetContents(ctx context.Context, owner, repo, path string, opts *github.RepositoryContentGetOptions) (*github.RepositoryContent, []*github.RepositoryContent, *github.Response, error)
+	CreateFile(ctx context.Context, owner, repo, path string, opts *github.RepositoryContentFileOptions) (*github.RepositoryContentResponse, *github.Response, error)
+	UpdateFile(ctx context.Context, owner, repo, path string, opts *github.RepositoryContentFileOptions) (*github.RepositoryContentResponse, *github.Response, error)
+}
+
+type FeedOptions struct {
+	Owner  string
+	Repo   string
+	Path   string
+	Branch string
+	// Title of the feed that is used when the file is created.
+	Title string
+	// URL of the feed that is used as its ID.
+	URL string
+	// MaxEntries limits the number of entries in the feed (default: 50).
+	MaxEntries int
+	// Now returns the time used for the updated timestamps. Defaults to
+	// time.Now.
+	Now func() time.Time
+}
+
+type feedAnnouncer struct {
+	client ContentsClient
+	opts   FeedOptions
+}
+
+// NewFeedAnnouncer adds the announcement as entry to an Atom feed file inside
+// a GitHub repository. If the feed already contains an entry for the version,
+// that entry is replaced.
+func NewFeedAnnouncer(client ContentsClient, opts FeedOptions) Announcer {
+	if opts.MaxEntries <= 0 {
+		opts.MaxEntries = defaultFeedMaxEntries
+	}
+	if opts.Now == nil {
+		opts.Now = time.Now
+	}
+	return &feedAnnouncer{
+		client: client,
+		opts:   opts,
+	}
+}
+
+type atomFeed struct {
+	XMLName xml.Name    `xml:"feed"`
+	Xmlns   string      `xml:"xmlns,attr"`
+	Title   string      `xml:"title"`
+	ID      string      `xml:"id"`
+	Updated string      `xml:"updated"`
+	Links   []atomLink  `xml:"link"`
+	Entries []atomEntry `xml:"entry"`
+}
+
+type atomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr,omitempty"`
+}
+
+type atomEntry struct {
+	Title   string      `xml:"title"`
+	ID      string      `xml:"id"`
+	Updated string      `xml:"updated"`
+	Links   []atomLink  `xml:"link"`
+	Content atomContent `xml:"content"`
+}
+
+type atomContent struct {
+	Type string `xml:"type,attr"`
+	Body string `xml:",chardata"`
+}
+
+func (f *feedAnnouncer) Name() string {
+	return TargetFeed
+}
+
+func (f *feedAnnouncer) Announce(ctx context.Context, a Announcement) error {
+	feed := atomFeed{
+		Title: f.opts.Title,
+		ID:    f.opts.URL,
+	}
+	if f.opts.URL != "" {
+		feed.Links = []atomLink{{Href: f.opts.URL, Rel: "self"}}
+	}
+	var sha *string
+	content, _, resp, err := f.client.GetContents(ctx, f.opts.Owner, f.opts.Repo, f.opts.Path, &github.RepositoryContentGetOptions{
+		Ref: f.opts.Branch,
+	})
+	if err != nil && (resp == nil || resp.StatusCode != http.StatusNotFound) {
+		return fmt.Errorf("failed to retrieve feed: %w", err)
+	}
+	if err == nil && content != nil {
+		raw, err := content.GetContent()
+		if err != nil {
+			return fmt.Errorf("failed to decode feed: %w", err)
+		}
+		if err := xml.Unmarshal([]byte(raw), &feed); err != nil {
+			return fmt.Errorf("failed to parse feed: %w", err)
+		}
+		sha = content.SHA
+	}
+
+	now := f.opts.Now().UTC().Format(time.RFC3339)
+	addFeedEntry(&feed, f.entryID(a), atomEntry{
+		Title:   a.Title,
+		Updated: now,
+		Links:   []atomLink{{Href: a.URL}},
+		Content: atomContent{Type: "text", Body: a.Body},
+	}, f.opts.MaxEntries)
+	feed.Xmlns = atomNamespace
+	feed.Updated = now
+
+	output, err := xml.MarshalIndent(feed, "", "  ")
+	if err != nil {
+		return err
+	}
+	fileOpts := &github.RepositoryContentFileOptions{
+		Message: github.String(fmt.Sprintf("Update release feed for %s", a.Version)),
+		Content: append([]byte(xml.Header), append(output, '\n')...),
+		SHA:     sha,
+	}
+	if f.opts.Branch != "" {
+		fileOpts.Branch = github.String(f.opts.Branch)
+	}
+	if sha == nil {
+		_, _, err = f.client.CreateFile(ctx, f.opts.Owner, f.opts.Repo, f.opts.Path, fileOpts)
+	} else {
+		_, _, err = f.client.UpdateFile(ctx, f.opts.Owner, f.opts.Repo, f.opts.Path, fileOpts)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to commit feed: %w", err)
+	}
+	return nil
+}
+
+func (f *feedAnnouncer) entryID(a Announcement) string {
+	return fmt.Sprintf("%s#%s", f.opts.URL, a.Version)
+}
+
+// addFeedEntry puts the entry at the top of the feed while removing an older
+// entry with the same ID and entries beyond maxEntries.
+func addFeedEntry(feed *atomFeed, id string, entry atomEntry, maxEntries int) {
+	entry.ID = id
+	entries := make([]atomEntry, 0, len(feed.Entries)+1)
+	entries = append(entries, entry)
+	for _, e := range feed.Entries {
+		if e.ID != id {
+			entries = append(entries, e)
+		}
+	}
+	if len(entries) > maxEntries {
+		entries = entries[:maxEntries]
+	}
+	feed.Entries = entries
+}
@@ -0,0 +1,96 @@
+package announce
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/xml"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v50/github"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeContentsClient struct {
+	files map[string]string
+	shas  map[string]string
+}
+
+func (c *fakeContentsClient) GetContents(ctx context.Context, owner, repo, path string, opts *github.RepositoryContentGetOptions) (*github.RepositoryContent, []*github.RepositoryContent, *github.Response, error) {
+	content, ok := c.files[path]
+	if !ok {
+		resp := &github.Response{Response: &http.Response{StatusCode: http.StatusNotFound}}
+		return nil, nil, resp, &github.ErrorResponse{Response: resp.Response}
+	}
+	return &github.RepositoryContent{
+		Content:  github.String(base64.StdEncoding.EncodeToString([]byte(content))),
+		Encoding: github.String("base64"),
+		SHA:      github.String(c.shas[path]),
+	}, nil, &github.Response{Response: &http.Response{StatusCode: http.StatusOK}}, nil
+}
+
+func (c *fakeContentsClient) CreateFile(ctx context.Context, owner, repo, path string, opts *github.RepositoryContentFileOptions) (*github.RepositoryContentResponse, *github.Response, error) {
+	c.files[path] = string(opts.Content)
+	c.shas[path] = "1"
+	return &github.RepositoryContentResponse{}, nil, nil
+}
+
+func (c *fakeContentsClient) UpdateFile(ctx context.Context, owner, repo, path string, opts *github.RepositoryContentFileOptions) (*github.RepositoryContentResponse, *github.Response, error) {
+	if opts.GetSHA() != c.shas[path] {
+		return nil, nil, &github.ErrorResponse{Message: "sha mismatch"}
+	}
+	c.files[path] = string(opts.Content)
+	c.shas[path] = opts.GetSHA() + "1"
+	return &github.RepositoryContentResponse{}, nil, nil
+}
+
+func TestFeedAnnouncer(t *testing.T) {
+	ctx := context.Background()
+	client := &fakeContentsClient{files: map[string]string{}, shas: map[string]string{}}
+	announcer := NewFeedAnnouncer(client, FeedOptions{
+		Owner:      "grafana",
+		Repo:       "website",
+		Path:       "releases.xml",
+		Title:      "Grafana releases",
+		URL:        "https://example.com/releases.xml",
+		MaxEntries: 2,
+		Now: func() time.Time {
+			return time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+		},
+	})
+	announce := func(version string) {
+		a := testAnnouncement
+		a.Version = version
+		a.Title = "Grafana " + version
+		require.NoError(t, announcer.Announce(ctx, a))
+	}
+	announce("1.0.0")
+	require.Equal(t, `<?xml version="1.0" encoding="UTF-8"?>
+<feed xmlns="http://www.w3.org/2005/Atom">
+  <title>Grafana releases</title>
+  <id>https://example.com/releases.xml</id>
+  <updated>2024-01-02T03:04:05Z</updated>
+  <link href="https://example.com/releases.xml" rel="self"></link>
+  <entry>
+    <title>Grafana 1.0.0</title>
+    <id>https://example.com/releases.xml#1.0.0</id>
+    <updated>2024-01-02T03:04:05Z</updated>
+    <link href="https://github.com/grafana/grafana/releases/tag/v1.0.0"></link>
+    <content type="text">### Features and enhancements&#xA;&#xA;- **Alerting:** Add something. [#123](https://github.com/grafana/grafana/issues/123)</content>
+  </entry>
+</feed>
+`, client.files["releases.xml"])
+
+	// Entries of the same version are replaced and old entries are
+	// dropped:
+	announce("1.0.1")
+	announce("1.0.0")
+	announce("1.0.2")
+	feed := atomFeed{}
+	require.NoError(t, xml.Unmarshal([]byte(client.files["releases.xml"]), &feed))
+	require.Len(t, feed.Entries, 2)
+	require.Equal(t, "Grafana 1.0.2", feed.Entries[0].Title)
+	require.Equal(t, "Grafana 1.0.0", feed.Entries[1].Title)
+	require.Equal(t, "1111", client.shas["releases.xml"])
+}
@@ -0,0 +1,63 @@
+package announce
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"unicode/utf8"
+)
+
+// mastodonStatusLimit is the default maximum length of a status.
+const mastodonStatusLimit = 500
+
+type mastodonAnnouncer struct {
+	instanceURL string
+	token       string
+	visibility  string
+	httpClient  *http.Client
+}
+
+// NewMastodonAnnouncer publishes a status with the title and URL of the
+// announcement on a Mastodon (or compatible) instance. If visibility is
+// empty, the default visibility of the account is used.
+func NewMastodonAnnouncer(instanceURL string, token string, visibility string, httpClient *http.Client) Announcer {
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	return &mastodonAnnouncer{
+		instanceURL: strings.TrimSuffix(instanceURL, "/"),
+		token:       token,
+		visibility:  visibility,
+		httpClient:  httpClient,
+	}
+}
+
+func (m *mastodonAnnouncer) Name() string {
+	return TargetMastodon
+}
+
+func (m *mastodonAnnouncer) Announce(ctx context.Context, a Announcement) error {
+	payload := map[string]string{
+		"status": mastodonStatus(a),
+	}
+	if m.visibility != "" {
+		payload["visibility"] = m.visibility
+	}
+	return postJSON(ctx, m.httpClient, m.instanceURL+"/api/v1/statuses", map[string]string{
+		"Authorization": "Bearer " + m.token,
+		// Retries of the action must not result in duplicate statuses:
+		"Idempotency-Key": a.Title + "@" + a.Version,
+	}, payload)
+}
+
+func mastodonStatus(a Announcement) string {
+	status := a.Title
+	if a.URL != "" {
+		status += "\n\n" + a.URL
+	}
+	if utf8.RuneCountInString(status) > mastodonStatusLimit {
+		runes := []rune(status)
+		status = string(runes[:mastodonStatusLimit-1]) + "…"
+	}
+	return status
+}
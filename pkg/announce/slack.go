@@ -0,0 +1,91 @@
+package announce
+
+import (
+	"context"
+	"net/http"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// slackTextLimit keeps the message below the limit of Slack for the text of
+// a message.
+const slackTextLimit = 3000
+
+type slackAnnouncer struct {
+	webhookURL string
+	httpClient *http.Client
+}
+
+// NewSlackAnnouncer posts the announcement to a Slack incoming webhook.
+func NewSlackAnnouncer(webhookURL string, httpClient *http.Client) Announcer {
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	return &slackAnnouncer{
+		webhookURL: webhookURL,
+		httpClient: httpClient,
+	}
+}
+
+func (s *slackAnnouncer) Name() string {
+	return TargetSlack
+}
+
+func (s *slackAnnouncer) Announce(ctx context.Context, a Announcement) error {
+	return postJSON(ctx, s.httpClient, s.webhookURL, nil, map[string]string{
+		"text": slackMessage(a),
+	})
+}
+
+func slackMessage(a Announcement) string {
+	out := strings.Builder{}
+	out.WriteString("*")
+	out.WriteString(a.Title)
+	out.WriteString("*\n")
+	if a.URL != "" {
+		out.WriteString(a.URL)
+		out.WriteString("\n")
+	}
+	body := toSlackMarkdown(a.Body)
+	if body != "" {
+		out.WriteString("\n")
+		out.WriteString(truncateSlackText(body))
+	}
+	return strings.TrimSpace(out.String())
+}
+
+// truncateSlackText cuts text that is longer than slackTextLimit at the last
+// line break before the limit. Text without such a line break is cut at the
+// last rune that fits.
+func truncateSlackText(text string) string {
+	if len(text) <= slackTextLimit {
+		return text
+	}
+	cut := strings.LastIndex(text[:slackTextLimit], "\n")
+	if cut <= 0 {
+		cut = slackTextLimit
+		for cut > 0 && !utf8.RuneStart(text[cut]) {
+			cut--
+		}
+	}
+	return strings.TrimSpace(text[:cut]) + "\n…"
+}
+
+var markdownLinkPattern = regexp.MustCompile(`\[([^\]]+)\]\(([^)]+)\)`)
+var markdownBoldPattern = regexp.MustCompile(`\*\*([^*]+)\*\*`)
+
+// toSlackMarkdown converts the elements of the changelog Markdown that Slack
+// renders differently (links, bold text, and headings).
+func toSlackMarkdown(md string) string {
+	lines := strings.Split(strings.TrimSpace(md), "\n")
+	for idx, line := range lines {
+		line = markdownLinkPattern.ReplaceAllString(line, "<$2|$1>")
+		line = markdownBoldPattern.ReplaceAllString(line, "*$1*")
+		if strings.HasPrefix(line, "#") {
+			line = "*" + strings.TrimSpace(strings.TrimLeft(line, "#")) + "*"
+		}
+		lines[idx] = line
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,34 @@
+package announce
+
+import (
+	"context"
+	"net/http"
+)
+
+type webhookAnnouncer struct {
+	url        string
+	headers    map[string]string
+	httpClient *http.Client
+}
+
+// NewWebhookAnnouncer posts the announcement as JSON object (title, version,
+// body, url) to the given URL. The headers are added to the request (e.g. for
+// authentication).
+func NewWebhookAnnouncer(url string, headers map[string]string, httpClient *http.Client) Announcer {
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	return &webhookAnnouncer{
+		url:        url,
+		headers:    headers,
+		httpClient: httpClient,
+	}
+}
+
+func (w *webhookAnnouncer) Name() string {
+	return TargetWebhook
+}
+
+func (w *webhookAnnouncer) Announce(ctx context.Context, a Announcement) error {
+	return postJSON(ctx, w.httpClient, w.url, w.headers, a)
+}
@@ -0,0 +1,285 @@
+package changelog
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/rs/zerolog"
+)
+
+// ChangelogFile is the path of the main changelog file relative to the root
+// of the repository.
+const ChangelogFile = "CHANGELOG.md"
+
+// ArchiveFile returns the path of the changelog archive of the given major
+// version relative to the root of the repository.
+func ArchiveFile(major int64) string {
+	return filepath.Join(".changelog-archive", fmt.Sprintf("CHANGELOG.%d.md", major))
+}
+
+type UpdateFilesOptions struct {
+	// KeepMajors is the number of major versions that should be kept inside
+	// CHANGELOG.md. Entries of older major versions are moved into their
+	// archive file. 0 disables the archiving.
+	KeepMajors int
+	// FoldPrereleases is passed on to UpdateFile.
+	FoldPrereleases bool
+}
+
+// UpdateFiles inserts the rendered changelog into the changelog files of the
+// repository located at root. Entries of a major version that has already
+// been archived are inserted directly into its archive file. The paths of all
+// files that were changed are returned relative to root.
+func UpdateFiles(ctx context.Context, root string, rendered string, body *ChangelogBody, opts *UpdateFilesOptions) ([]string, error) {
+	if opts == nil {
+		opts = &UpdateFilesOptions{}
+	}
+	logger := zerolog.Ctx(ctx)
+	newVersion, err := parseChangelogVersion(body.Version)
+	if err != nil {
+		return nil, err
+	}
+
+	mainDoc, err := readChangelogDocument(filepath.Join(root, ChangelogFile))
+	if err != nil {
+		return nil, err
+	}
+	target := ChangelogFile
+	archive := ArchiveFile(newVersion.Major())
+	if !mainDoc.hasMajor(newVersion.Major()) && fileExists(filepath.Join(root, archive)) {
+		target = archive
+	}
+	logger.Info().Msgf("Inserting changelog for %s into %s", body.Version, target)
+	updateOpts := &UpdateFileOptions{FoldPrereleases: opts.FoldPrereleases}
+	if err := updateFileAtPath(ctx, filepath.Join(root, target), rendered, body, updateOpts); err != nil {
+		return nil, err
+	}
+	changed := []string{target}
+	if target != ChangelogFile || opts.KeepMajors <= 0 {
+		return changed, nil
+	}
+
+	archived, err := rotateChangelog(ctx, root, opts.KeepMajors)
+	if err != nil {
+		return nil, err
+	}
+	return append(changed, archived...), nil
+}
+
+// rotateChangelog moves all entries of major versions exceeding the keep
+// limit from CHANGELOG.md into their archive files. The paths of the archive
+// files that were changed are returned.
+func rotateChangelog(ctx context.Context, root string, keep int) ([]string, error) {
+	logger := zerolog.Ctx(ctx)
+	mainPath := filepath.Join(root, ChangelogFile)
+	mainDoc, err := readChangelogDocument(mainPath)
+	if err != nil {
+		return nil, err
+	}
+	majors := mainDoc.majors()
+	if len(majors) <= keep {
+		return nil, nil
+	}
+	changed := make([]string, 0, len(majors)-keep)
+	for _, major := range majors[keep:] {
+		archive := ArchiveFile(major)
+		logger.Info().Msgf("Moving entries of major version %d into %s", major, archive)
+		archivePath := filepath.Join(root, archive)
+		archiveDoc, err := readChangelogDocument(archivePath)
+		if err != nil {
+			return nil, err
+		}
+		moved := mainDoc.removeMajor(major)
+		archiveDoc.merge(moved)
+		archiveDoc.sort()
+		if err := os.MkdirAll(filepath.Dir(archivePath), 0o755); err != nil {
+			return nil, err
+		}
+		if err := archiveDoc.writeFile(archivePath); err != nil {
+			return nil, err
+		}
+		changed = append(changed, archive)
+	}
+	if err := mainDoc.writeFile(mainPath); err != nil {
+		return nil, err
+	}
+	return changed, nil
+}
+
+func updateFileAtPath(ctx context.Context, path string, rendered string, body *ChangelogBody, opts *UpdateFileOptions) error {
+	if !fileExists(path) {
+		if err := os.WriteFile(path, []byte{}, 0o644); err != nil {
+			return err
+		}
+	}
+	return UpdateFileAtPath(ctx, path, rendered, body, opts)
+}
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+// changelogBlock is the content of a changelog file between the start marker
+// of a version and the start marker of the next one.
+type changelogBlock struct {
+	Version string
+	Lines   []string
+}
+
+// changelogDocument is a changelog file split into the individual version
+// blocks.
+type changelogDocument struct {
+	// Preamble contains all lines before the first version block.
+	Preamble []string
+	Blocks   []changelogBlock
+}
+
+func readChangelogDocument(path string) (*changelogDocument, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return &changelogDocument{}, nil
+		}
+		return nil, err
+	}
+	return parseChangelogDocument(bytes.NewReader(content))
+}
+
+func parseChangelogDocument(in io.Reader) (*changelogDocument, error) {
+	doc := &changelogDocument{}
+	scanner := bufio.NewScanner(in)
+	scanner.Split(bufio.ScanLines)
+	var current *changelogBlock
+	for scanner.Scan() {
+		line := scanner.Text()
+		if match := versionStartLinePattern.FindStringSubmatch(line); len(match) > 1 {
+			doc.Blocks = append(doc.Blocks, changelogBlock{Version: match[1]})
+			current = &doc.Blocks[len(doc.Blocks)-1]
+		}
+		if current == nil {
+			doc.Preamble = append(doc.Preamble, line)
+		} else {
+			current.Lines = append(current.Lines, line)
+		}
+	}
+	return doc, scanner.Err()
+}
+
+func (d *changelogDocument) hasMajor(major int64) bool {
+	for _, block := range d.Blocks {
+		v, err := parseChangelogVersion(block.Version)
+		if err != nil {
+			continue
+		}
+		if v.Major() == major {
+			return true
+		}
+	}
+	return false
+}
+
+// majors returns all major versions with blocks inside the document in
+// descending order. Blocks without a valid version are ignored.
+func (d *changelogDocument) majors() []int64 {
+	seen := make(map[int64]struct{})
+	result := make([]int64, 0, 5)
+	for _, block := range d.Blocks {
+		v, err := parseChangelogVersion(block.Version)
+		if err != nil {
+			continue
+		}
+		if _, ok := seen[v.Major()]; ok {
+			continue
+		}
+		seen[v.Major()] = struct{}{}
+		result = append(result, v.Major())
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i] > result[j]
+	})
+	return result
+}
+
+// removeMajor removes all blocks of the given major version from the document
+// and returns them.
+func (d *changelogDocument) removeMajor(major int64) []changelogBlock {
+	kept := make([]changelogBlock, 0, len(d.Blocks))
+	removed := make([]changelogBlock, 0, 10)
+	for _, block := range d.Blocks {
+		v, err := parseChangelogVersion(block.Version)
+		if err == nil && v.Major() == major {
+			removed = append(removed, block)
+		} else {
+			kept = append(kept, block)
+		}
+	}
+	d.Blocks = kept
+	return removed
+}
+
+// merge adds the provided blocks to the document. Blocks of versions that are
+// already present are replaced.
+func (d *changelogDocument) merge(blocks []changelogBlock) {
+	for _, block := range blocks {
+		replaced := false
+		for idx, existing := range d.Blocks {
+			if existing.Version == block.Version {
+				d.Blocks[idx] = block
+				replaced = true
+				break
+			}
+		}
+		if !replaced {
+			d.Blocks = append(d.Blocks, block)
+		}
+	}
+}
+
+// sort orders the blocks by descending version. Blocks without a valid
+// version are moved to the end.
+func (d *changelogDocument) sort() {
+	parsed := make(map[string]changelogVersion, len(d.Blocks))
+	for _, block := range d.Blocks {
+		if v, err := parseChangelogVersion(block.Version); err == nil {
+			parsed[block.Version] = v
+		}
+	}
+	sort.SliceStable(d.Blocks, func(i, j int) bool {
+		a, aOK := parsed[d.Blocks[i].Version]
+		b, bOK := parsed[d.Blocks[j].Version]
+		if !aOK || !bOK {
+			return aOK
+		}
+		return b.LessThan(a)
+	})
+}
+
+func (d *changelogDocument) writeFile(path string) error {
+	out := bytes.Buffer{}
+	if err := d.write(&out); err != nil {
+		return err
+	}
+	return os.WriteFile(path, out.Bytes(), 0o644)
+}
+
+func (d *changelogDocument) write(out io.Writer) error {
+	lines := make([]string, 0, len(d.Preamble)+len(d.Blocks)*10)
+	lines = append(lines, d.Preamble...)
+	for _, block := range d.Blocks {
+		lines = append(lines, block.Lines...)
+	}
+	if len(lines) == 0 {
+		return nil
+	}
+	_, err := io.WriteString(out, strings.Join(lines, "\n")+"\n")
+	return err
+}
@@ -0,0 +1,84 @@
+package changelog
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func block(version string) string {
+	return "<!-- " + version + " START -->\n\n# " + version + "\n\n<!-- " + version + " END -->\n"
+}
+
+func renderVersion(t *testing.T, version string) (string, *ChangelogBody) {
+	t.Helper()
+	body := &ChangelogBody{Version: version}
+	rendered, err := NewRenderer(nil).Render(context.Background(), body)
+	require.NoError(t, err)
+	return rendered, body
+}
+
+func writeTestFile(t *testing.T, root string, path string, content string) {
+	t.Helper()
+	require.NoError(t, os.MkdirAll(filepath.Dir(filepath.Join(root, path)), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(root, path), []byte(content), 0o644))
+}
+
+func readTestFile(t *testing.T, root string, path string) string {
+	t.Helper()
+	content, err := os.ReadFile(filepath.Join(root, path))
+	require.NoError(t, err)
+	return string(content)
+}
+
+func TestUpdateFiles(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("no-archiving", func(t *testing.T) {
+		root := t.TempDir()
+		writeTestFile(t, root, ChangelogFile, block("10.0.0")+block("9.5.0"))
+		rendered, body := renderVersion(t, "11.0.0")
+		changed, err := UpdateFiles(ctx, root, rendered, body, nil)
+		require.NoError(t, err)
+		require.Equal(t, []string{ChangelogFile}, changed)
+		require.Equal(t, block("11.0.0")+block("10.0.0")+block("9.5.0"), readTestFile(t, root, ChangelogFile))
+	})
+
+	t.Run("rotate-on-new-major", func(t *testing.T) {
+		root := t.TempDir()
+		writeTestFile(t, root, ChangelogFile, block("10.0.0")+block("9.5.1")+block("9.5.0"))
+		writeTestFile(t, root, ArchiveFile(9), block("9.5.0")+block("9.4.0"))
+		rendered, body := renderVersion(t, "11.0.0")
+		changed, err := UpdateFiles(ctx, root, rendered, body, &UpdateFilesOptions{KeepMajors: 2})
+		require.NoError(t, err)
+		require.Equal(t, []string{ChangelogFile, ArchiveFile(9)}, changed)
+		require.Equal(t, block("11.0.0")+block("10.0.0"), readTestFile(t, root, ChangelogFile))
+		require.Equal(t, block("9.5.1")+block("9.5.0")+block("9.4.0"), readTestFile(t, root, ArchiveFile(9)))
+	})
+
+	t.Run("insert-into-archive", func(t *testing.T) {
+		root := t.TempDir()
+		writeTestFile(t, root, ChangelogFile, block("11.0.0")+block("10.0.0"))
+		writeTestFile(t, root, ArchiveFile(9), block("9.5.1")+block("9.4.0"))
+		rendered, body := renderVersion(t, "9.5.2")
+		changed, err := UpdateFiles(ctx, root, rendered, body, &UpdateFilesOptions{KeepMajors: 2})
+		require.NoError(t, err)
+		require.Equal(t, []string{ArchiveFile(9)}, changed)
+		require.Equal(t, block("11.0.0")+block("10.0.0"), readTestFile(t, root, ChangelogFile))
+		require.Equal(t, block("9.5.2")+block("9.5.1")+block("9.4.0"), readTestFile(t, root, ArchiveFile(9)))
+	})
+
+	t.Run("new-archive", func(t *testing.T) {
+		root := t.TempDir()
+		writeTestFile(t, root, ChangelogFile, block("10.0.0")+block("9.5.0"))
+		rendered, body := renderVersion(t, "10.0.1")
+		changed, err := UpdateFiles(ctx, root, rendered, body, &UpdateFilesOptions{KeepMajors: 1})
+		require.NoError(t, err)
+		require.Equal(t, []string{ChangelogFile, ArchiveFile(9)}, changed)
+		require.Equal(t, block("10.0.1")+block("10.0.0"), readTestFile(t, root, ChangelogFile))
+		require.Equal(t, block("9.5.0"), readTestFile(t, root, ArchiveFile(9)))
+	})
+}
@@ -0,0 +1,96 @@
+package changelog
+
+import (
+	"context"
+	"strings"
+
+	"github.com/grafana/grafana-github-actions-go/pkg/ghgql"
+	"github.com/rs/zerolog"
+)
+
+// authorsClient is used to look up the authors of pull requests. It is
+// implemented by *ghgql.Client.
+type authorsClient interface {
+	GetPullRequestAuthors(ctx context.Context, repoOwner string, repoName string, numbers []int) (map[int]ghgql.PullRequestAuthors, error)
+}
+
+// resolveAuthors populates the Authors field of all the provided pull requests
+// that are not enterprise-only. For backports the authors of the original pull
+// request are used. All pull requests of a repository are looked up at once.
+// If the authors cannot be retrieved, a warning is logged and the renderer
+// falls back to the author of the pull request itself.
+func resolveAuthors(ctx context.Context, client authorsClient, issues []ghgql.PullRequest) {
+	logger := zerolog.Ctx(ctx)
+	numbersByRepo := make(map[string][]int)
+	seen := make(map[string]map[int]struct{})
+	for _, issue := range issues {
+		if isEnterprisePR(issue) {
+			continue
+		}
+		repo := issue.GetRepoOwner() + "/" + issue.GetRepoName()
+		num := getOriginalPRNumber(issue)
+		if _, ok := seen[repo]; !ok {
+			seen[repo] = make(map[int]struct{})
+		}
+		if _, ok := seen[repo][num]; ok {
+			continue
+		}
+		seen[repo][num] = struct{}{}
+		numbersByRepo[repo] = append(numbersByRepo[repo], num)
+	}
+
+	authorsByRepo := make(map[string]map[int]ghgql.PullRequestAuthors)
+	for repo, numbers := range numbersByRepo {
+		owner, name, _ := strings.Cut(repo, "/")
+		authors, err := client.GetPullRequestAuthors(ctx, owner, name, numbers)
+		if err != nil {
+			logger.Warn().Err(err).Msgf("Failed to retrieve authors of %d pull requests in %s", len(numbers), repo)
+			continue
+		}
+		authorsByRepo[repo] = authors
+	}
+
+	for idx, issue := range issues {
+		if isEnterprisePR(issue) {
+			continue
+		}
+		repo := issue.GetRepoOwner() + "/" + issue.GetRepoName()
+		authors, ok := authorsByRepo[repo]
+		if !ok {
+			continue
+		}
+		num := getOriginalPRNumber(issue)
+		prAuthors, ok := authors[num]
+		if !ok {
+			if isBotUser(issue) {
+				logger.Warn().Msgf("No author found for PR#%d (original PR#%d)", issue.GetNumber(), num)
+			}
+			continue
+		}
+		issues[idx].Authors = collectAuthors(prAuthors)
+	}
+}
+
+// collectAuthors returns the author of the pull request followed by all the
+// co-authors found in its commits. Bots are excluded.
+func collectAuthors(prAuthors ghgql.PullRequestAuthors) []string {
+	result := make([]string, 0, len(prAuthors.CommitAuthors)+1)
+	seen := make(map[string]struct{})
+	add := func(login string) {
+		if login == "" || isBotLogin(login) {
+			return
+		}
+		if _, ok := seen[login]; ok {
+			return
+		}
+		seen[login] = struct{}{}
+		result = append(result, login)
+	}
+	if !prAuthors.AuthorIsBot {
+		add(prAuthors.Author)
+	}
+	for _, login := range prAuthors.CommitAuthors {
+		add(login)
+	}
+	return result
+}
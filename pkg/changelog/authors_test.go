@@ -0,0 +1,91 @@
+package changelog
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/grafana/grafana-github-actions-go/pkg/ghgql"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeAuthorsClient struct {
+	authors map[int]ghgql.PullRequestAuthors
+	err     error
+	calls   [][]int
+}
+
+func (c *fakeAuthorsClient) GetPullRequestAuthors(ctx context.Context, repoOwner string, repoName string, numbers []int) (map[int]ghgql.PullRequestAuthors, error) {
+	c.calls = append(c.calls, numbers)
+	if c.err != nil {
+		return nil, c.err
+	}
+	return c.authors, nil
+}
+
+func TestResolveAuthors(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("backports-and-co-authors", func(t *testing.T) {
+		client := &fakeAuthorsClient{
+			authors: map[int]ghgql.PullRequestAuthors{
+				100: {Number: 100, Author: "author", CommitAuthors: []string{"author", "co-author", "dependabot[bot]"}},
+				200: {Number: 200, Author: "other"},
+				300: {Number: 300, Author: "renovate", AuthorIsBot: true, CommitAuthors: []string{"maintainer"}},
+			},
+		}
+		issues := []ghgql.PullRequest{
+			{Number: pointerOf(101), RepoOwner: pointerOf("grafana"), RepoName: pointerOf("grafana"), AuthorLogin: pointerOf("grafanabot"), HeadRefName: pointerOf("backport-100-to-v10.0.x")},
+			{Number: pointerOf(102), RepoOwner: pointerOf("grafana"), RepoName: pointerOf("grafana"), AuthorLogin: pointerOf("grafanabot"), Body: pointerOf("Backport abc from #100")},
+			{Number: pointerOf(200), RepoOwner: pointerOf("grafana"), RepoName: pointerOf("grafana"), AuthorLogin: pointerOf("other")},
+			{Number: pointerOf(300), RepoOwner: pointerOf("grafana"), RepoName: pointerOf("grafana"), AuthorLogin: pointerOf("renovate")},
+			{Number: pointerOf(400), RepoOwner: pointerOf("grafana"), RepoName: pointerOf("grafana-enterprise"), AuthorLogin: pointerOf("secret")},
+		}
+		resolveAuthors(ctx, client, issues)
+		require.Len(t, client.calls, 1)
+		require.Equal(t, []int{100, 200, 300}, client.calls[0])
+		require.Equal(t, []string{"author", "co-author"}, issues[0].Authors)
+		require.Equal(t, []string{"author", "co-author"}, issues[1].Authors)
+		require.Equal(t, []string{"other"}, issues[2].Authors)
+		require.Equal(t, []string{"maintainer"}, issues[3].Authors)
+		require.Nil(t, issues[4].Authors)
+	})
+
+	t.Run("lookup-failure", func(t *testing.T) {
+		client := &fakeAuthorsClient{err: fmt.Errorf("boom")}
+		issues := []ghgql.PullRequest{
+			{Number: pointerOf(200), RepoOwner: pointerOf("grafana"), RepoName: pointerOf("grafana"), AuthorLogin: pointerOf("other")},
+		}
+		resolveAuthors(ctx, client, issues)
+		require.Nil(t, issues[0].Authors)
+	})
+}
+
+func TestGetUserLinks(t *testing.T) {
+	tests := []struct {
+		name     string
+		issue    ghgql.PullRequest
+		expected string
+	}{
+		{
+			name:     "unresolved",
+			issue:    ghgql.PullRequest{AuthorLogin: pointerOf("author")},
+			expected: "[@author](https://github.com/author)",
+		},
+		{
+			name:     "unresolved-bot",
+			issue:    ghgql.PullRequest{AuthorLogin: pointerOf("grafanabot")},
+			expected: "",
+		},
+		{
+			name:     "multiple-authors",
+			issue:    ghgql.PullRequest{AuthorLogin: pointerOf("grafanabot"), Authors: []string{"a", "b"}},
+			expected: "[@a](https://github.com/a), [@b](https://github.com/b)",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			require.Equal(t, test.expected, getUserLinks(test.issue))
+		})
+	}
+}
@@ -0,0 +1,73 @@
+package changelog
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+
+	"github.com/grafana/grafana-github-actions-go/pkg/ghgql"
+)
+
+// backportBranchPatterns match the head branches created by the various
+// backport tools in use:
+//
+//   - backport-<number>-to-<target> (this repository's backport action)
+//   - backport/<target>/pr-<number> (sqren/backport)
+//   - backport/<number>/<target>
+var backportBranchPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`^backport-(\d+)-to-.+$`),
+	regexp.MustCompile(`^backport/.+/pr-(\d+)$`),
+	regexp.MustCompile(`^backport/(\d+)/.+$`),
+}
+
+// backportBodyPatterns match references to the original pull request in the
+// body of a backport like "Backport <sha> from #<number>" (generated by the
+// backport action) or "Backport of #<number>".
+var backportBodyPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`(?m)^Backport [0-9a-f]+ from #(\d+)`),
+	regexp.MustCompile(`(?mi)^Backport(?:s| of)? #(\d+)`),
+}
+
+func getPRNumberFromBackportBranch(ref string) (int, error) {
+	num, ok := findFirstNumber(backportBranchPatterns, ref)
+	if !ok {
+		return -1, fmt.Errorf("no number found in ref")
+	}
+	return num, nil
+}
+
+func getPRNumberFromBackportBody(body string) (int, error) {
+	num, ok := findFirstNumber(backportBodyPatterns, body)
+	if !ok {
+		return -1, fmt.Errorf("no backport reference found in body")
+	}
+	return num, nil
+}
+
+func findFirstNumber(patterns []*regexp.Regexp, input string) (int, bool) {
+	for _, pattern := range patterns {
+		match := pattern.FindStringSubmatch(input)
+		if len(match) < 2 {
+			continue
+		}
+		result, err := strconv.Atoi(match[1])
+		if err != nil {
+			continue
+		}
+		return result, true
+	}
+	return -1, false
+}
+
+// getOriginalPRNumber returns the number of the pull request the provided one
+// was backported from. If the pull request doesn't look like a backport, its
+// own number is returned.
+func getOriginalPRNumber(issue ghgql.PullRequest) int {
+	if num, err := getPRNumberFromBackportBranch(issue.GetHeadRefName()); err == nil {
+		return num
+	}
+	if num, err := getPRNumberFromBackportBody(issue.GetBody()); err == nil {
+		return num
+	}
+	return issue.GetNumber()
+}
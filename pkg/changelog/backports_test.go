@@ -0,0 +1,67 @@
+package changelog
+
+import (
+	"testing"
+
+	"github.com/grafana/grafana-github-actions-go/pkg/ghgql"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetOriginalPRNumber(t *testing.T) {
+	tests := []struct {
+		name     string
+		issue    ghgql.PullRequest
+		expected int
+	}{
+		{
+			name:     "no-backport",
+			issue:    ghgql.PullRequest{Number: pointerOf(123), HeadRefName: pointerOf("some-feature")},
+			expected: 123,
+		},
+		{
+			name:     "backport-action-branch",
+			issue:    ghgql.PullRequest{Number: pointerOf(123), HeadRefName: pointerOf("backport-100-to-v10.0.x")},
+			expected: 100,
+		},
+		{
+			name:     "backport-action-release-branch",
+			issue:    ghgql.PullRequest{Number: pointerOf(123), HeadRefName: pointerOf("backport-100-to-release-10.0.1")},
+			expected: 100,
+		},
+		{
+			name:     "sqren-backport-branch",
+			issue:    ghgql.PullRequest{Number: pointerOf(123), HeadRefName: pointerOf("backport/v10.0.x/pr-100")},
+			expected: 100,
+		},
+		{
+			name:     "number-first-backport-branch",
+			issue:    ghgql.PullRequest{Number: pointerOf(123), HeadRefName: pointerOf("backport/100/v10.0.x")},
+			expected: 100,
+		},
+		{
+			name:     "backport-action-body",
+			issue:    ghgql.PullRequest{Number: pointerOf(123), HeadRefName: pointerOf("manual"), Body: pointerOf("Backport 0123abc from #100\n\n---\n\nBody")},
+			expected: 100,
+		},
+		{
+			name:     "backport-of-body",
+			issue:    ghgql.PullRequest{Number: pointerOf(123), HeadRefName: pointerOf("manual"), Body: pointerOf("Some intro\nBackport of #100")},
+			expected: 100,
+		},
+		{
+			name:     "backports-body",
+			issue:    ghgql.PullRequest{Number: pointerOf(123), HeadRefName: pointerOf("manual"), Body: pointerOf("backports #100")},
+			expected: 100,
+		},
+		{
+			name:     "mention-in-body",
+			issue:    ghgql.PullRequest{Number: pointerOf(123), HeadRefName: pointerOf("manual"), Body: pointerOf("This should be backported after #100")},
+			expected: 123,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			require.Equal(t, test.expected, getOriginalPRNumber(test.issue))
+		})
+	}
+}
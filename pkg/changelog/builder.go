@@ -10,7 +10,6 @@ import (
 
 	"github.com/coreos/go-semver/semver"
 	"github.com/grafana/grafana-github-actions-go/pkg/ghgql"
-	"github.com/grafana/grafana-github-actions-go/pkg/toolkit"
 	"github.com/rs/zerolog"
 
 	"github.com/google/go-github/v50/github"
@@ -23,28 +22,42 @@ const LabelRuntime = "area/grafana/runtime"
 const LabelBug = "type/bug"
 const milestoneAgeDiffThreshold = time.Hour * 24
 
-func Build(ctx context.Context, version string, tk *toolkit.Toolkit) (*ChangelogBody, error) {
+type BuildOptions struct {
+	// IncludePending adds open pull requests of the milestone that have
+	// already been approved. The resulting changelog is marked as unreleased.
+	IncludePending bool
+	// DetectNewContributors determines which authors contributed to the
+	// repository for the first time.
+	DetectNewContributors bool
+}
+
+// Build collects all the pull requests of the given version from the data
+// source and sorts them into the sections of the changelog.
+func Build(ctx context.Context, version string, ds DataSource, opts *BuildOptions) (*ChangelogBody, error) {
+	if opts == nil {
+		opts = &BuildOptions{}
+	}
 	logger := zerolog.Ctx(ctx)
 	body := newChangelogBody()
 
-	milestone, err := getMilestone(ctx, tk, "grafana/grafana", version)
+	milestone, err := getMilestone(ctx, ds, "grafana/grafana", version)
 	if err != nil {
 		return nil, fmt.Errorf("failed to retrieve OSS milestone: %w", err)
 	}
 	if milestone == nil {
 		return nil, fmt.Errorf("milestone for `%s` not found", version)
 	}
-	enterpriseMilestone, err := getMilestone(ctx, tk, "grafana/grafana-enterprise", version)
+	enterpriseMilestone, err := getMilestone(ctx, ds, "grafana/grafana-enterprise", version)
 	if err != nil {
 		return nil, fmt.Errorf("failed to retrieve OSS milestone: %w", err)
 	}
 
-	ossIssues, err := tk.GitHubGQLClient().GetMilestonedPRsForChangelog(ctx, "grafana", "grafana", milestone.GetNumber())
+	ossIssues, err := ds.GetMilestonedPRsForChangelog(ctx, "grafana", "grafana", milestone.GetNumber())
 	if err != nil {
 		return nil, fmt.Errorf("failed to retrieve OSS issues: %w", err)
 	}
 
-	enterpriseIssues, err := tk.GitHubGQLClient().GetMilestonedPRsForChangelog(ctx, "grafana", "grafana-enterprise", enterpriseMilestone.GetNumber())
+	enterpriseIssues, err := ds.GetMilestonedPRsForChangelog(ctx, "grafana", "grafana-enterprise", enterpriseMilestone.GetNumber())
 	if err != nil {
 		return nil, fmt.Errorf("failed to retrieve Enterprise issues: %w", err)
 	}
@@ -53,20 +66,35 @@ func Build(ctx context.Context, version string, tk *toolkit.Toolkit) (*Changelog
 	issues = append(issues, ossIssues...)
 	issues = append(issues, enterpriseIssues...)
 
+	if opts.IncludePending {
+		pendingIssues, err := ds.GetPendingMilestonedPRsForChangelog(ctx, "grafana", "grafana", milestone.GetNumber())
+		if err != nil {
+			return nil, fmt.Errorf("failed to retrieve pending OSS issues: %w", err)
+		}
+		pendingEnterpriseIssues, err := ds.GetPendingMilestonedPRsForChangelog(ctx, "grafana", "grafana-enterprise", enterpriseMilestone.GetNumber())
+		if err != nil {
+			return nil, fmt.Errorf("failed to retrieve pending Enterprise issues: %w", err)
+		}
+		logger.Info().Msgf("%d pending PRs found", len(pendingIssues)+len(pendingEnterpriseIssues))
+		issues = append(issues, pendingIssues...)
+		issues = append(issues, pendingEnterpriseIssues...)
+		body.Unreleased = true
+	}
+
 	// At this point check if the PR is already part of an older release.
 	// Basically any milestone that was part of the stream and the previous one
 	// released before the current milestone should be considered a potential
 	// conflict.
-	milestones, err := getHistoricalMilestones(ctx, tk, milestone, version, milestoneAgeDiffThreshold)
+	milestones, err := getHistoricalMilestones(ctx, ds, milestone, version, milestoneAgeDiffThreshold)
 	if err != nil {
 		return nil, err
 	}
 
-	loader := NewLoader(tk.GitHubClient())
 	previousChangelogs := make(map[string]string)
+	previousIssues := make([]ghgql.PullRequest, 0, 50)
 	for _, milestone := range milestones {
 		logger.Debug().Msgf("Considering %s for duplicates", milestone.GetTitle())
-		msContent, err := loader.LoadContent(ctx, "grafana", "grafana", milestone.GetTitle(), &LoaderOptions{RemoveHeading: true})
+		msContent, err := ds.GetChangelogContent(ctx, "grafana", "grafana", milestone.GetTitle())
 		if err != nil {
 			var noChangelogFound NoChangelogFound
 			if errors.As(err, &noChangelogFound) {
@@ -76,13 +104,40 @@ func Build(ctx context.Context, version string, tk *toolkit.Toolkit) (*Changelog
 			return nil, err
 		}
 		previousChangelogs[milestone.GetTitle()] = msContent
+
+		// The pull requests of the milestone are needed in order to
+		// determine which original pull requests the entries of that
+		// changelog were backported from:
+		msIssues, err := ds.GetMilestonedPRsForChangelog(ctx, "grafana", "grafana", milestone.GetNumber())
+		if err != nil {
+			return nil, fmt.Errorf("failed to retrieve issues of %s: %w", milestone.GetTitle(), err)
+		}
+		previousIssues = append(previousIssues, msIssues...)
 	}
 
-	filteredIssues, err := deduplicateEntries(ctx, issues, previousChangelogs)
+	filteredIssues, duplicates, err := deduplicateEntries(ctx, issues, previousChangelogs, previousIssues)
 	if err != nil {
 		return nil, fmt.Errorf("failed to deduplicate entries")
 	}
+	for _, dup := range duplicates {
+		logger.Info().Msgf("Skipping %s", dup)
+	}
 	logger.Info().Msgf("%d PRs remaining for the changelog", len(filteredIssues))
+	resolveAuthors(ctx, ds, filteredIssues)
+	if opts.DetectNewContributors {
+		body.NewContributors = findNewContributors(ctx, ds, filteredIssues)
+		logger.Info().Msgf("%d new contributors found", len(body.NewContributors))
+	}
+	// Advisories are looked up in the same repositories as the pull
+	// requests:
+	for _, repoName := range []string{"grafana", "grafana-enterprise"} {
+		advisories, err := ds.GetSecurityAdvisories(ctx, "grafana", repoName)
+		if err != nil {
+			logger.Warn().Err(err).Msgf("Failed to retrieve security advisories of grafana/%s", repoName)
+			continue
+		}
+		body.advisories = append(body.advisories, advisories...)
+	}
 	for _, i := range filteredIssues {
 		addToBody(body, i)
 	}
@@ -96,44 +151,112 @@ func Build(ctx context.Context, version string, tk *toolkit.Toolkit) (*Changelog
 	return body, nil
 }
 
+type duplicateReason string
+
+const (
+	duplicateReasonNumber   duplicateReason = "pull request number"
+	duplicateReasonBackport duplicateReason = "backport lineage"
+	duplicateReasonTitle    duplicateReason = "title"
+)
+
+// duplicateEntry describes a pull request that was removed from the changelog
+// because it was already part of a previous release.
+type duplicateEntry struct {
+	PullRequest ghgql.PullRequest
+	Version     string
+	Reason      duplicateReason
+	// MatchedNumber is the pull request number that was found in the previous
+	// changelog (if the match was not done by title).
+	MatchedNumber int
+}
+
+func (d duplicateEntry) String() string {
+	if d.Reason == duplicateReasonTitle {
+		return fmt.Sprintf("`%s` (#%d): already mentioned in `%s` (matched by %s)", d.PullRequest.GetTitle(), d.PullRequest.GetNumber(), d.Version, d.Reason)
+	}
+	return fmt.Sprintf("`%s` (#%d): #%d already mentioned in `%s` (matched by %s)", d.PullRequest.GetTitle(), d.PullRequest.GetNumber(), d.MatchedNumber, d.Version, d.Reason)
+}
+
 // deduplicateEntries removes all pull requests that have been mentioned in the
-// previous changelogs
-func deduplicateEntries(ctx context.Context, pullRequests []ghgql.PullRequest, previousChangelogs map[string]string) ([]ghgql.PullRequest, error) {
+// previous changelogs. Entries are primarily matched using the number of the
+// original pull request (resolved through backport branch names and bodies)
+// with previousPullRequests providing the lineage of the pull requests
+// mentioned in the previous changelogs. Only entries of previous changelogs
+// without any pull request link (e.g. Enterprise entries) are matched by
+// title.
+func deduplicateEntries(ctx context.Context, pullRequests []ghgql.PullRequest, previousChangelogs map[string]string, previousPullRequests []ghgql.PullRequest) ([]ghgql.PullRequest, []duplicateEntry, error) {
 	logger := zerolog.Ctx(ctx)
 	knownTitles := make(map[string]string)
+	knownNumbers := make(map[int]string)
 	parser := NewParser()
 	for version, changelog := range previousChangelogs {
 		sections, err := parser.Parse(ctx, bytes.NewBufferString(changelog))
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 		for _, section := range sections {
 			for _, entry := range section.Entries {
-				knownTitles[entry.Title] = version
+				if len(entry.Numbers) == 0 {
+					knownTitles[entry.Title] = version
+					continue
+				}
+				for _, num := range entry.Numbers {
+					knownNumbers[num] = version
+				}
 			}
 		}
 	}
+
+	// Every pull request number mentioned in a previous changelog might be a
+	// backport of another pull request. Both need to be known as originals:
+	knownOriginals := make(map[int]string)
+	for num, version := range knownNumbers {
+		knownOriginals[num] = version
+	}
+	for _, pr := range previousPullRequests {
+		if isEnterprisePR(pr) {
+			continue
+		}
+		if version, found := knownNumbers[pr.GetNumber()]; found {
+			knownOriginals[getOriginalPRNumber(pr)] = version
+		}
+	}
+
 	result := make([]ghgql.PullRequest, 0, len(pullRequests))
-	numDups := 0
+	duplicates := make([]duplicateEntry, 0, 10)
 	for _, i := range pullRequests {
-		// If the PR already seems to be present in a previous release, we can
-		// skip it here:
-		newTitle := PreparePRTitle(i)
-		if version, found := knownTitles[strings.TrimSpace(newTitle)]; found {
-			logger.Debug().Msgf("`%s` (#%d) was already mentioned in `%s`", i.GetTitle(), i.GetNumber(), version)
-			numDups++
+		if dup, found := findDuplicate(i, knownNumbers, knownOriginals, knownTitles); found {
+			duplicates = append(duplicates, dup)
 			continue
 		}
 		result = append(result, i)
 	}
-	logger.Info().Msgf("%d duplicates skipped", numDups)
-	return result, nil
+	logger.Info().Msgf("%d duplicates skipped", len(duplicates))
+	return result, duplicates, nil
+}
+
+func findDuplicate(pr ghgql.PullRequest, knownNumbers map[int]string, knownOriginals map[int]string, knownTitles map[string]string) (duplicateEntry, bool) {
+	// Enterprise entries are rendered without a link and their numbers
+	// would collide with those in the OSS repository:
+	if !isEnterprisePR(pr) {
+		if version, found := knownNumbers[pr.GetNumber()]; found {
+			return duplicateEntry{PullRequest: pr, Version: version, Reason: duplicateReasonNumber, MatchedNumber: pr.GetNumber()}, true
+		}
+		original := getOriginalPRNumber(pr)
+		if version, found := knownOriginals[original]; found {
+			return duplicateEntry{PullRequest: pr, Version: version, Reason: duplicateReasonBackport, MatchedNumber: original}, true
+		}
+	}
+	if version, found := knownTitles[strings.TrimSpace(PreparePRTitle(pr))]; found {
+		return duplicateEntry{PullRequest: pr, Version: version, Reason: duplicateReasonTitle}, true
+	}
+	return duplicateEntry{}, false
 }
 
 // getHistoricalMilestones retrieves all the milestones of the current and
 // previous release stream that were closed n-days before the milestone
 // matching `version`.
-func getHistoricalMilestones(ctx context.Context, tk *toolkit.Toolkit, currentMilestone *github.Milestone, version string, ageDiffThreshold time.Duration) ([]*github.Milestone, error) {
+func getHistoricalMilestones(ctx context.Context, ds DataSource, currentMilestone *github.Milestone, version string, ageDiffThreshold time.Duration) ([]*github.Milestone, error) {
 	if strings.HasSuffix(version, ".x") {
 		version = strings.Replace(version, ".x", ".0", 1)
 	}
@@ -141,7 +264,7 @@ func getHistoricalMilestones(ctx context.Context, tk *toolkit.Toolkit, currentMi
 	if err != nil {
 		return nil, err
 	}
-	allMilestones, err := getAllMilestones(ctx, tk, "grafana", "grafana")
+	allMilestones, err := ds.GetMilestones(ctx, "grafana", "grafana")
 	if err != nil {
 		return nil, err
 	}
@@ -207,25 +330,6 @@ func isInMinorRelease(milestone *github.Milestone, version *semver.Version) bool
 	return v.Major == version.Major && v.Minor == version.Minor
 }
 
-func getAllMilestones(ctx context.Context, tk *toolkit.Toolkit, owner, repo string) ([]*github.Milestone, error) {
-	opts := &github.MilestoneListOptions{}
-	opts.State = "all"
-	opts.Page = 1
-	result := make([]*github.Milestone, 0, 20)
-	for {
-		milestones, resp, err := tk.GitHubClient().Issues.ListMilestones(ctx, owner, repo, opts)
-		if err != nil {
-			return nil, err
-		}
-		result = append(result, milestones...)
-		if resp.NextPage <= opts.Page {
-			break
-		}
-		opts.Page = resp.NextPage
-	}
-	return result, nil
-}
-
 // getPreviousMinorRelease tries to find the previous minor release of the provided version
 func getPreviousMinorRelease(ctx context.Context, allMilestones []*github.Milestone, version semver.Version) (*semver.Version, error) {
 	currentMinor := version
@@ -254,26 +358,48 @@ func getPreviousMinorRelease(ctx context.Context, allMilestones []*github.Milest
 }
 
 type ChangelogBody struct {
-	Version            string
-	ReleaseDate        string
+	Version     string
+	ReleaseDate string
+	// Unreleased is set if the changelog is a preview of an upcoming release
+	// that might also contain pending pull requests.
+	Unreleased bool
+	// Highlights are listed at the top of the changelog in addition to
+	// their regular entry.
+	Highlights         []Highlight
 	DeprecationChanges []string
 	BreakingChanges    []string
 	PluginDevChanges   []ghgql.PullRequest
 	Bugfixes           []ghgql.PullRequest
 	Features           []ghgql.PullRequest
+	SecurityFixes      []SecurityFix
+	DependencyUpdates  []DependencyUpdate
+	NewContributors    []NewContributor
+
+	// advisories are used to link security fixes to their advisory.
+	advisories []SecurityAdvisory
 }
 
 func newChangelogBody() *ChangelogBody {
 	return &ChangelogBody{
+		Highlights:         make([]Highlight, 0, 5),
 		DeprecationChanges: make([]string, 0, 10),
 		BreakingChanges:    make([]string, 0, 10),
 		PluginDevChanges:   make([]ghgql.PullRequest, 0, 10),
 		Bugfixes:           make([]ghgql.PullRequest, 0, 10),
 		Features:           make([]ghgql.PullRequest, 0, 10),
+		SecurityFixes:      make([]SecurityFix, 0, 5),
+		DependencyUpdates:  make([]DependencyUpdate, 0, 10),
 	}
 }
 
 func addToBody(body *ChangelogBody, issue ghgql.PullRequest) {
+	override := getEntryOverride(issue)
+	if override.Exclude {
+		return
+	}
+	if isHighlight(issue) {
+		addHighlight(body, issue)
+	}
 	if notice := getBreakingChangeNotice(issue); notice != "" {
 		body.BreakingChanges = append(body.BreakingChanges, notice)
 	}
@@ -281,6 +407,27 @@ func addToBody(body *ChangelogBody, issue ghgql.PullRequest) {
 		body.DeprecationChanges = append(body.DeprecationChanges, notice)
 	}
 
+	if override.Security || (override.Section == "" && isSecurityFix(issue, body.advisories)) {
+		addSecurityFix(body, issue)
+		return
+	}
+	switch override.Section {
+	case sectionFeatures:
+		body.Features = append(body.Features, issue)
+		return
+	case sectionBugfixes:
+		body.Bugfixes = append(body.Bugfixes, issue)
+		return
+	case sectionPluginDev:
+		body.PluginDevChanges = append(body.PluginDevChanges, issue)
+		return
+	}
+
+	if isDependencyUpdate(issue) {
+		addDependencyUpdate(body, issue)
+		return
+	}
+
 	if issueHasLabel(issue, LabelToolkit) || issueHasLabel(issue, LabelUI) || issueHasLabel(issue, LabelRuntime) {
 		body.PluginDevChanges = append(body.PluginDevChanges, issue)
 		return
@@ -294,7 +441,7 @@ func addToBody(body *ChangelogBody, issue ghgql.PullRequest) {
 }
 
 func isBug(issue ghgql.PullRequest) bool {
-	title := issue.GetTitle()
+	title := entryTitle(issue)
 	if strings.Contains(strings.ToLower(title), "fix") {
 		return true
 	}
@@ -304,29 +451,19 @@ func isBug(issue ghgql.PullRequest) bool {
 	return false
 }
 
-func getMilestone(ctx context.Context, tk *toolkit.Toolkit, repo string, version string) (*github.Milestone, error) {
-	page := 1
+func getMilestone(ctx context.Context, ds DataSource, repo string, version string) (*github.Milestone, error) {
 	repoElems := strings.SplitN(repo, "/", 2)
 	if len(repoElems) != 2 {
 		return nil, fmt.Errorf("invalid repo provided: %s", repo)
 	}
-	owner := repoElems[0]
-	repo = repoElems[1]
-	for page > 0 {
-		opts := github.MilestoneListOptions{State: "all"}
-		opts.PerPage = 100
-		opts.Page = page
-		tk.IncrRequestCount()
-		milestones, resp, err := tk.GitHubClient().Issues.ListMilestones(ctx, owner, repo, &opts)
-		if err != nil {
-			return nil, err
-		}
-		for _, ms := range milestones {
-			if ms.GetTitle() == version {
-				return ms, nil
-			}
+	milestones, err := ds.GetMilestones(ctx, repoElems[0], repoElems[1])
+	if err != nil {
+		return nil, err
+	}
+	for _, ms := range milestones {
+		if ms.GetTitle() == version {
+			return ms, nil
 		}
-		page = resp.NextPage
 	}
 	return nil, nil
 }
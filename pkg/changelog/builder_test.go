@@ -153,6 +153,16 @@ func TestIssueLine(t *testing.T) {
 			},
 			expectedOutput: "- hello &lt;summary&gt; world. [#123](https://github.com/grafana/grafana/issues/123), [@author](https://github.com/author)\n",
 		},
+		{
+			name: "pending-pull-request",
+			issue: func(i *ghgql.PullRequest) {
+				i.Title = pointerOf("hello")
+				i.Number = pointerOf(123)
+				i.AuthorLogin = pointerOf("author")
+				i.State = pointerOf("OPEN")
+			},
+			expectedOutput: "- hello. [#123](https://github.com/grafana/grafana/issues/123), [@author](https://github.com/author) (pending)\n",
+		},
 	}
 
 	for _, test := range tests {
@@ -168,11 +178,13 @@ func TestIssueLine(t *testing.T) {
 
 func TestDeduplicateEntries(t *testing.T) {
 	tests := []struct {
-		name                string
-		currentPullRequests []ghgql.PullRequest
-		previousChangelogs  map[string]string
-		expectError         bool
-		expectResult        []ghgql.PullRequest
+		name                 string
+		currentPullRequests  []ghgql.PullRequest
+		previousChangelogs   map[string]string
+		previousPullRequests []ghgql.PullRequest
+		expectError          bool
+		expectResult         []ghgql.PullRequest
+		expectReasons        []duplicateReason
 	}{
 		{
 			name:                "empty",
@@ -249,17 +261,111 @@ func TestDeduplicateEntries(t *testing.T) {
 				"10.0.0": "### Bug fixes\n\n- **Category:** Title 2. (Enterprise)\n",
 			},
 		},
+		{
+			name:        "matching-number-with-reworded-title",
+			expectError: false,
+			expectResult: []ghgql.PullRequest{
+				{
+					Number: pointerOf(1),
+					Title:  pointerOf("Category: Title 1"),
+				},
+			},
+			expectReasons: []duplicateReason{duplicateReasonNumber},
+			currentPullRequests: []ghgql.PullRequest{
+				{
+					Number: pointerOf(1),
+					Title:  pointerOf("Category: Title 1"),
+				},
+				{
+					Number: pointerOf(2),
+					Title:  pointerOf("Category: Title 2 with a typo fixed"),
+				},
+			},
+			previousChangelogs: map[string]string{
+				"10.0.0": "### Bug fixes\n\n- **Category:** Title 2 with a typpo fixed. [#2](https://github.com/grafana/grafana/issues/2), [@user](https://github.com/user)\n",
+			},
+		},
+		{
+			// Two unrelated PRs that happen to share a title should not be
+			// considered duplicates if the previous entry has a number:
+			name:        "same-title-different-number",
+			expectError: false,
+			expectResult: []ghgql.PullRequest{
+				{
+					Number: pointerOf(3),
+					Title:  pointerOf("Chore: Update dependencies"),
+				},
+			},
+			currentPullRequests: []ghgql.PullRequest{
+				{
+					Number: pointerOf(3),
+					Title:  pointerOf("Chore: Update dependencies"),
+				},
+			},
+			previousChangelogs: map[string]string{
+				"10.0.0": "### Features and enhancements\n\n- **Chore:** Update dependencies. [#2](https://github.com/grafana/grafana/issues/2), [@user](https://github.com/user)\n",
+			},
+		},
+		{
+			// The current PR is a backport (by branch name) of a PR that
+			// was released previously:
+			name:          "backport-of-released-original",
+			expectError:   false,
+			expectResult:  []ghgql.PullRequest{},
+			expectReasons: []duplicateReason{duplicateReasonBackport},
+			currentPullRequests: []ghgql.PullRequest{
+				{
+					Number:      pointerOf(20),
+					Title:       pointerOf("[v10.0.x] Category: Reworded title"),
+					HeadRefName: pointerOf("backport-10-to-v10.0.x"),
+				},
+			},
+			previousChangelogs: map[string]string{
+				"10.1.0": "### Bug fixes\n\n- **Category:** Title. [#10](https://github.com/grafana/grafana/issues/10), [@user](https://github.com/user)\n",
+			},
+		},
+		{
+			// The current PR is the original of a backport (identified by
+			// its body) that was part of a previous release:
+			name:          "original-of-released-backport",
+			expectError:   false,
+			expectResult:  []ghgql.PullRequest{},
+			expectReasons: []duplicateReason{duplicateReasonBackport},
+			currentPullRequests: []ghgql.PullRequest{
+				{
+					Number: pointerOf(10),
+					Title:  pointerOf("Category: Title"),
+				},
+			},
+			previousChangelogs: map[string]string{
+				"10.0.2": "### Bug fixes\n\n- **Category:** Title. [#20](https://github.com/grafana/grafana/issues/20), [@user](https://github.com/user)\n",
+			},
+			previousPullRequests: []ghgql.PullRequest{
+				{
+					Number: pointerOf(20),
+					Title:  pointerOf("[v10.0.x] Category: Title"),
+					Body:   pointerOf("Backport 0123abc from #10\n\n---\n\nSomething"),
+				},
+			},
+		},
 	}
 
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
 			ctx := context.Background()
-			output, err := deduplicateEntries(ctx, test.currentPullRequests, test.previousChangelogs)
+			output, duplicates, err := deduplicateEntries(ctx, test.currentPullRequests, test.previousChangelogs, test.previousPullRequests)
 			if test.expectError {
 				require.Error(t, err)
 			} else {
 				require.NoError(t, err)
 				require.Equal(t, test.expectResult, output)
+				if test.expectReasons != nil {
+					reasons := make([]duplicateReason, 0, len(duplicates))
+					for _, dup := range duplicates {
+						reasons = append(reasons, dup.Reason)
+					}
+					require.Equal(t, test.expectReasons, reasons)
+				}
 			}
 		})
 	}
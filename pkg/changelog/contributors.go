@@ -0,0 +1,146 @@
+package changelog
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-github-actions-go/pkg/ghgql"
+	"github.com/rs/zerolog"
+)
+
+// NewContributor is a user whose first merged pull request in the repository
+// is part of the release.
+type NewContributor struct {
+	Login       string
+	PullRequest ghgql.PullRequest
+}
+
+const (
+	// NewContributorsNone doesn't highlight first-time contributors.
+	NewContributorsNone = "none"
+	// NewContributorsSection adds a "New contributors" section.
+	NewContributorsSection = "section"
+	// NewContributorsMark marks the entries of first-time contributors.
+	NewContributorsMark = "mark"
+	// NewContributorsBoth adds the section and marks the entries.
+	NewContributorsBoth = "both"
+)
+
+// NewContributorsRendererOptions returns the renderer options for the given
+// mode of highlighting first-time contributors. An empty mode is treated as
+// NewContributorsNone.
+func NewContributorsRendererOptions(mode string) ([]RendererOption, error) {
+	switch mode {
+	case "", NewContributorsNone:
+		return nil, nil
+	case NewContributorsSection:
+		return []RendererOption{WithNewContributorsSection()}, nil
+	case NewContributorsMark:
+		return []RendererOption{WithNewContributorMarkers()}, nil
+	case NewContributorsBoth:
+		return []RendererOption{WithNewContributorsSection(), WithNewContributorMarkers()}, nil
+	default:
+		return nil, fmt.Errorf("unsupported new contributors mode: %s", mode)
+	}
+}
+
+type contributionsClient interface {
+	CountMergedPullRequestsBefore(ctx context.Context, repoOwner string, repoName string, authors map[string]time.Time) (map[string]int, error)
+}
+
+// findNewContributors determines which authors of the provided pull requests
+// have never had a pull request merged into the repository before. Members of
+// the organization, bots, backports and enterprise pull requests are ignored.
+// Pull requests of new contributors are marked using their FirstContribution
+// field.
+func findNewContributors(ctx context.Context, client contributionsClient, issues []ghgql.PullRequest) []NewContributor {
+	logger := zerolog.Ctx(ctx)
+	// For every repository and author only the earliest pull request is
+	// relevant:
+	candidates := make(map[string]map[string]int)
+	for idx, issue := range issues {
+		if !isNewContributorCandidate(issue) {
+			continue
+		}
+		repo := issue.GetRepoOwner() + "/" + issue.GetRepoName()
+		if _, ok := candidates[repo]; !ok {
+			candidates[repo] = make(map[string]int)
+		}
+		login := issue.GetAuthorLogin()
+		if prev, ok := candidates[repo][login]; ok && !issue.GetMergedAt().Before(issues[prev].GetMergedAt()) {
+			continue
+		}
+		candidates[repo][login] = idx
+	}
+
+	result := make([]NewContributor, 0, 5)
+	for repo, authors := range candidates {
+		owner, name, _ := strings.Cut(repo, "/")
+		mergedAt := make(map[string]time.Time, len(authors))
+		for login, idx := range authors {
+			mergedAt[login] = issues[idx].GetMergedAt()
+		}
+		counts, err := client.CountMergedPullRequestsBefore(ctx, owner, name, mergedAt)
+		if err != nil {
+			logger.Warn().Err(err).Msgf("Failed to determine new contributors in %s", repo)
+			continue
+		}
+		for login, idx := range authors {
+			count, ok := counts[login]
+			if !ok || count > 0 {
+				continue
+			}
+			issues[idx].FirstContribution = true
+			result = append(result, NewContributor{
+				Login:       login,
+				PullRequest: issues[idx],
+			})
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return strings.ToLower(result[i].Login) < strings.ToLower(result[j].Login)
+	})
+	return result
+}
+
+func isNewContributorCandidate(issue ghgql.PullRequest) bool {
+	if isEnterprisePR(issue) || isBotUser(issue) || issue.GetAuthorLogin() == "" {
+		return false
+	}
+	if issue.GetMergedAt().IsZero() {
+		return false
+	}
+	// Backports credit the author of the original pull request which has
+	// already been merged and released before.
+	if getOriginalPRNumber(issue) != issue.GetNumber() {
+		return false
+	}
+	switch ghgql.CommentAuthorAssociation(issue.GetAuthorAssociation()) {
+	case ghgql.CommentAuthorAssociationMember, ghgql.CommentAuthorAssociationOwner:
+		return false
+	}
+	return true
+}
+
+func (r *defaultRenderer) writeNewContributors(out *strings.Builder, contributors []NewContributor) {
+	if !r.opts.newContributorsSection || len(contributors) == 0 {
+		return
+	}
+	out.WriteString("### New contributors\n\n")
+	for _, contributor := range contributors {
+		out.WriteString("- [@")
+		out.WriteString(contributor.Login)
+		out.WriteString("](https://github.com/")
+		out.WriteString(contributor.Login)
+		out.WriteString(") made their first contribution in [#")
+		out.WriteString(strconv.Itoa(contributor.PullRequest.GetNumber()))
+		out.WriteString("](https://github.com/grafana/grafana/issues/")
+		out.WriteString(strconv.Itoa(contributor.PullRequest.GetNumber()))
+		out.WriteString(")\n")
+	}
+	out.WriteString("\n")
+}
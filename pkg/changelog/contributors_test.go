@@ -0,0 +1,96 @@
+package changelog
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-github-actions-go/pkg/ghgql"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeContributionsClient struct {
+	counts   map[string]int
+	requests map[string]time.Time
+}
+
+func (c *fakeContributionsClient) CountMergedPullRequestsBefore(ctx context.Context, repoOwner string, repoName string, authors map[string]time.Time) (map[string]int, error) {
+	c.requests = authors
+	result := make(map[string]int)
+	for login := range authors {
+		result[login] = c.counts[login]
+	}
+	return result, nil
+}
+
+func TestFindNewContributors(t *testing.T) {
+	early := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	late := time.Date(2023, 1, 2, 0, 0, 0, 0, time.UTC)
+	pr := func(number int, login string, association string, mergedAt time.Time) ghgql.PullRequest {
+		return ghgql.PullRequest{
+			Number:            pointerOf(number),
+			Title:             pointerOf("Area: Something"),
+			RepoOwner:         pointerOf("grafana"),
+			RepoName:          pointerOf("grafana"),
+			AuthorLogin:       pointerOf(login),
+			AuthorAssociation: pointerOf(association),
+			MergedAt:          pointerOf(mergedAt),
+		}
+	}
+	issues := []ghgql.PullRequest{
+		pr(1, "newbie", "CONTRIBUTOR", late),
+		pr(2, "newbie", "FIRST_TIME_CONTRIBUTOR", early),
+		pr(3, "regular", "CONTRIBUTOR", early),
+		pr(4, "employee", "MEMBER", early),
+		pr(5, "dependabot[bot]", "NONE", early),
+		pr(6, "another", "NONE", early),
+	}
+	client := &fakeContributionsClient{
+		counts: map[string]int{
+			"regular": 12,
+		},
+	}
+	result := findNewContributors(context.Background(), client, issues)
+	require.Len(t, client.requests, 3)
+	require.Equal(t, early, client.requests["newbie"])
+	require.Len(t, result, 2)
+	require.Equal(t, "another", result[0].Login)
+	require.Equal(t, "newbie", result[1].Login)
+	require.Equal(t, 2, result[1].PullRequest.GetNumber())
+	require.False(t, issues[0].FirstContribution)
+	require.True(t, issues[1].FirstContribution)
+	require.False(t, issues[2].FirstContribution)
+}
+
+func TestRenderNewContributors(t *testing.T) {
+	ctx := context.Background()
+	body := newChangelogBody()
+	body.Version = "10.1.0"
+	issue := ghgql.PullRequest{
+		Number:            pointerOf(2),
+		Title:             pointerOf("Area: Something"),
+		AuthorLogin:       pointerOf("newbie"),
+		FirstContribution: true,
+	}
+	body.Features = append(body.Features, issue)
+	body.NewContributors = []NewContributor{{Login: "newbie", PullRequest: issue}}
+
+	t.Run("disabled", func(t *testing.T) {
+		output, err := NewRenderer(nil).Render(ctx, body)
+		require.NoError(t, err)
+		require.Equal(t, "# 10.1.0\n\n### Features and enhancements\n\n- **Area:** Something. [#2](https://github.com/grafana/grafana/issues/2), [@newbie](https://github.com/newbie)\n\n", output)
+	})
+
+	t.Run("section-and-markers", func(t *testing.T) {
+		opts, err := NewContributorsRendererOptions(NewContributorsBoth)
+		require.NoError(t, err)
+		output, err := NewRenderer(nil, opts...).Render(ctx, body)
+		require.NoError(t, err)
+		require.Equal(t, "# 10.1.0\n\n### Features and enhancements\n\n- **Area:** Something. [#2](https://github.com/grafana/grafana/issues/2), [@newbie](https://github.com/newbie) (first contribution)\n\n### New contributors\n\n- [@newbie](https://github.com/newbie) made their first contribution in [#2](https://github.com/grafana/grafana/issues/2)\n\n", output)
+	})
+
+	t.Run("invalid-mode", func(t *testing.T) {
+		_, err := NewContributorsRendererOptions("everything")
+		require.Error(t, err)
+	})
+}
@@ -0,0 +1,393 @@
+package changelog
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/google/go-github/v50/github"
+	"github.com/grafana/grafana-github-actions-go/pkg/ghgql"
+	"github.com/grafana/grafana-github-actions-go/pkg/toolkit"
+)
+
+// DataSource provides all the data that is required by Build.
+type DataSource interface {
+	// GetMilestones returns all (open and closed) milestones of a repository.
+	GetMilestones(ctx context.Context, repoOwner string, repoName string) ([]*github.Milestone, error)
+	// GetMilestonedPRsForChangelog returns the merged pull requests of a
+	// milestone that are labelled for the changelog.
+	GetMilestonedPRsForChangelog(ctx context.Context, repoOwner string, repoName string, milestoneNumber int) ([]ghgql.PullRequest, error)
+	// GetPendingMilestonedPRsForChangelog returns the open but approved pull
+	// requests of a milestone that are labelled for the changelog.
+	GetPendingMilestonedPRsForChangelog(ctx context.Context, repoOwner string, repoName string, milestoneNumber int) ([]ghgql.PullRequest, error)
+	// GetChangelogContent returns the changelog of a previous release
+	// without its heading. If there is none, NoChangelogFound is returned.
+	GetChangelogContent(ctx context.Context, repoOwner string, repoName string, version string) (string, error)
+	// GetSecurityAdvisories returns the published security advisories of a
+	// repository.
+	GetSecurityAdvisories(ctx context.Context, repoOwner string, repoName string) ([]SecurityAdvisory, error)
+	authorsClient
+	contributionsClient
+}
+
+type gitHubDataSource struct {
+	tk         *toolkit.Toolkit
+	loader     *Loader
+	lock       sync.Mutex
+	milestones map[string][]*github.Milestone
+}
+
+// NewGitHubDataSource returns a DataSource that retrieves all data from the
+// GitHub REST and GraphQL APIs.
+func NewGitHubDataSource(tk *toolkit.Toolkit) DataSource {
+	return &gitHubDataSource{
+		tk:         tk,
+		loader:     NewLoader(tk.GitHubClient()),
+		milestones: make(map[string][]*github.Milestone),
+	}
+}
+
+func (ds *gitHubDataSource) GetMilestones(ctx context.Context, repoOwner string, repoName string) ([]*github.Milestone, error) {
+	key := repoOwner + "/" + repoName
+	ds.lock.Lock()
+	defer ds.lock.Unlock()
+	if cached, ok := ds.milestones[key]; ok {
+		return cached, nil
+	}
+	opts := &github.MilestoneListOptions{}
+	opts.State = "all"
+	opts.PerPage = 100
+	opts.Page = 1
+	result := make([]*github.Milestone, 0, 20)
+	for {
+		ds.tk.IncrRequestCount()
+		milestones, resp, err := ds.tk.GitHubClient().Issues.ListMilestones(ctx, repoOwner, repoName, opts)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, milestones...)
+		if resp.NextPage <= opts.Page {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+	ds.milestones[key] = result
+	return result, nil
+}
+
+func (ds *gitHubDataSource) GetMilestonedPRsForChangelog(ctx context.Context, repoOwner string, repoName string, milestoneNumber int) ([]ghgql.PullRequest, error) {
+	return ds.tk.GitHubGQLClient().GetMilestonedPRsForChangelog(ctx, repoOwner, repoName, milestoneNumber)
+}
+
+func (ds *gitHubDataSource) GetPendingMilestonedPRsForChangelog(ctx context.Context, repoOwner string, repoName string, milestoneNumber int) ([]ghgql.PullRequest, error) {
+	return ds.tk.GitHubGQLClient().GetPendingMilestonedPRsForChangelog(ctx, repoOwner, repoName, milestoneNumber)
+}
+
+func (ds *gitHubDataSource) GetChangelogContent(ctx context.Context, repoOwner string, repoName string, version string) (string, error) {
+	return ds.loader.LoadContent(ctx, repoOwner, repoName, version, &LoaderOptions{RemoveHeading: true})
+}
+
+func (ds *gitHubDataSource) GetPullRequestAuthors(ctx context.Context, repoOwner string, repoName string, numbers []int) (map[int]ghgql.PullRequestAuthors, error) {
+	return ds.tk.GitHubGQLClient().GetPullRequestAuthors(ctx, repoOwner, repoName, numbers)
+}
+
+func (ds *gitHubDataSource) CountMergedPullRequestsBefore(ctx context.Context, repoOwner string, repoName string, authors map[string]time.Time) (map[string]int, error) {
+	return ds.tk.GitHubGQLClient().CountMergedPullRequestsBefore(ctx, repoOwner, repoName, authors)
+}
+
+func (ds *gitHubDataSource) GetSecurityAdvisories(ctx context.Context, repoOwner string, repoName string) ([]SecurityAdvisory, error) {
+	// go-github doesn't support repository security advisories in the
+	// version we use and so the request is done manually:
+	result := make([]SecurityAdvisory, 0, 10)
+	page := 1
+	for page > 0 {
+		req, err := ds.tk.GitHubClient().NewRequest("GET", fmt.Sprintf("repos/%s/%s/security-advisories?state=published&per_page=100&page=%d", repoOwner, repoName, page), nil)
+		if err != nil {
+			return nil, err
+		}
+		advisories := make([]SecurityAdvisory, 0, 100)
+		ds.tk.IncrRequestCount()
+		resp, err := ds.tk.GitHubClient().Do(ctx, req, &advisories)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, advisories...)
+		page = resp.NextPage
+	}
+	return result, nil
+}
+
+// Fixture contains all the data a DataSource provided during a build. It can
+// be stored as JSON and replayed later in order to reproduce a changelog
+// without access to GitHub.
+type Fixture struct {
+	// Milestones are indexed by "owner/repo".
+	Milestones map[string][]*github.Milestone `json:"milestones"`
+	// PullRequests are indexed by "owner/repo#milestoneNumber".
+	PullRequests map[string][]ghgql.PullRequest `json:"pullRequests"`
+	// PendingPullRequests are indexed by "owner/repo#milestoneNumber".
+	PendingPullRequests map[string][]ghgql.PullRequest `json:"pendingPullRequests"`
+	// Changelogs are indexed by "owner/repo@version". Versions without a
+	// changelog are not included.
+	Changelogs map[string]string `json:"changelogs"`
+	// Authors are indexed by "owner/repo".
+	Authors map[string]map[int]ghgql.PullRequestAuthors `json:"authors"`
+	// MergedPullRequestCounts are indexed by "owner/repo" and login.
+	MergedPullRequestCounts map[string]map[string]int `json:"mergedPullRequestCounts"`
+	// SecurityAdvisories are indexed by "owner/repo".
+	SecurityAdvisories map[string][]SecurityAdvisory `json:"securityAdvisories"`
+}
+
+func NewFixture() *Fixture {
+	return &Fixture{
+		Milestones:              make(map[string][]*github.Milestone),
+		PullRequests:            make(map[string][]ghgql.PullRequest),
+		PendingPullRequests:     make(map[string][]ghgql.PullRequest),
+		Changelogs:              make(map[string]string),
+		Authors:                 make(map[string]map[int]ghgql.PullRequestAuthors),
+		MergedPullRequestCounts: make(map[string]map[string]int),
+		SecurityAdvisories:      make(map[string][]SecurityAdvisory),
+	}
+}
+
+// LoadFixture reads a fixture previously stored using Save.
+func LoadFixture(path string) (*Fixture, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	fixture := NewFixture()
+	if err := json.Unmarshal(raw, fixture); err != nil {
+		return nil, fmt.Errorf("failed to decode fixture: %w", err)
+	}
+	return fixture, nil
+}
+
+func (f *Fixture) Save(path string) error {
+	raw, err := json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, raw, 0o644)
+}
+
+func repoKey(repoOwner string, repoName string) string {
+	return repoOwner + "/" + repoName
+}
+
+func milestoneKey(repoOwner string, repoName string, milestoneNumber int) string {
+	return fmt.Sprintf("%s/%s#%d", repoOwner, repoName, milestoneNumber)
+}
+
+func changelogKey(repoOwner string, repoName string, version string) string {
+	return fmt.Sprintf("%s/%s@%s", repoOwner, repoName, version)
+}
+
+type missingFixtureData struct {
+	key string
+}
+
+func (e missingFixtureData) Error() string {
+	return fmt.Sprintf("`%s` is not part of the fixture", e.key)
+}
+
+// fixtureDataSource replays a Fixture.
+type fixtureDataSource struct {
+	fixture *Fixture
+}
+
+// NewFixtureDataSource returns a DataSource that only provides the data
+// stored inside the fixture.
+func NewFixtureDataSource(fixture *Fixture) DataSource {
+	return &fixtureDataSource{fixture: fixture}
+}
+
+func (ds *fixtureDataSource) GetMilestones(ctx context.Context, repoOwner string, repoName string) ([]*github.Milestone, error) {
+	key := repoKey(repoOwner, repoName)
+	result, ok := ds.fixture.Milestones[key]
+	if !ok {
+		return nil, missingFixtureData{key: key}
+	}
+	return result, nil
+}
+
+func (ds *fixtureDataSource) GetMilestonedPRsForChangelog(ctx context.Context, repoOwner string, repoName string, milestoneNumber int) ([]ghgql.PullRequest, error) {
+	key := milestoneKey(repoOwner, repoName, milestoneNumber)
+	result, ok := ds.fixture.PullRequests[key]
+	if !ok {
+		return nil, missingFixtureData{key: key}
+	}
+	return result, nil
+}
+
+func (ds *fixtureDataSource) GetPendingMilestonedPRsForChangelog(ctx context.Context, repoOwner string, repoName string, milestoneNumber int) ([]ghgql.PullRequest, error) {
+	key := milestoneKey(repoOwner, repoName, milestoneNumber)
+	result, ok := ds.fixture.PendingPullRequests[key]
+	if !ok {
+		return nil, missingFixtureData{key: key}
+	}
+	return result, nil
+}
+
+func (ds *fixtureDataSource) GetChangelogContent(ctx context.Context, repoOwner string, repoName string, version string) (string, error) {
+	result, ok := ds.fixture.Changelogs[changelogKey(repoOwner, repoName, version)]
+	if !ok {
+		return "", NoChangelogFound{Version: version}
+	}
+	return result, nil
+}
+
+func (ds *fixtureDataSource) GetPullRequestAuthors(ctx context.Context, repoOwner string, repoName string, numbers []int) (map[int]ghgql.PullRequestAuthors, error) {
+	key := repoKey(repoOwner, repoName)
+	authors, ok := ds.fixture.Authors[key]
+	if !ok {
+		return nil, missingFixtureData{key: key}
+	}
+	result := make(map[int]ghgql.PullRequestAuthors, len(numbers))
+	for _, num := range numbers {
+		if a, ok := authors[num]; ok {
+			result[num] = a
+		}
+	}
+	return result, nil
+}
+
+func (ds *fixtureDataSource) CountMergedPullRequestsBefore(ctx context.Context, repoOwner string, repoName string, authors map[string]time.Time) (map[string]int, error) {
+	key := repoKey(repoOwner, repoName)
+	counts, ok := ds.fixture.MergedPullRequestCounts[key]
+	if !ok {
+		return nil, missingFixtureData{key: key}
+	}
+	result := make(map[string]int, len(authors))
+	for login := range authors {
+		count, ok := counts[login]
+		if !ok {
+			return nil, missingFixtureData{key: key + ":" + login}
+		}
+		result[login] = count
+	}
+	return result, nil
+}
+
+func (ds *fixtureDataSource) GetSecurityAdvisories(ctx context.Context, repoOwner string, repoName string) ([]SecurityAdvisory, error) {
+	key := repoKey(repoOwner, repoName)
+	result, ok := ds.fixture.SecurityAdvisories[key]
+	if !ok {
+		return nil, missingFixtureData{key: key}
+	}
+	return result, nil
+}
+
+// RecordingDataSource passes all calls through to another DataSource and
+// stores the responses inside a Fixture.
+type RecordingDataSource struct {
+	upstream DataSource
+	lock     sync.Mutex
+	fixture  *Fixture
+}
+
+func NewRecordingDataSource(upstream DataSource) *RecordingDataSource {
+	return &RecordingDataSource{
+		upstream: upstream,
+		fixture:  NewFixture(),
+	}
+}
+
+// Fixture returns all the data recorded so far.
+func (ds *RecordingDataSource) Fixture() *Fixture {
+	return ds.fixture
+}
+
+func (ds *RecordingDataSource) GetMilestones(ctx context.Context, repoOwner string, repoName string) ([]*github.Milestone, error) {
+	result, err := ds.upstream.GetMilestones(ctx, repoOwner, repoName)
+	if err != nil {
+		return nil, err
+	}
+	ds.lock.Lock()
+	defer ds.lock.Unlock()
+	ds.fixture.Milestones[repoKey(repoOwner, repoName)] = result
+	return result, nil
+}
+
+func (ds *RecordingDataSource) GetMilestonedPRsForChangelog(ctx context.Context, repoOwner string, repoName string, milestoneNumber int) ([]ghgql.PullRequest, error) {
+	result, err := ds.upstream.GetMilestonedPRsForChangelog(ctx, repoOwner, repoName, milestoneNumber)
+	if err != nil {
+		return nil, err
+	}
+	ds.lock.Lock()
+	defer ds.lock.Unlock()
+	ds.fixture.PullRequests[milestoneKey(repoOwner, repoName, milestoneNumber)] = result
+	return result, nil
+}
+
+func (ds *RecordingDataSource) GetPendingMilestonedPRsForChangelog(ctx context.Context, repoOwner string, repoName string, milestoneNumber int) ([]ghgql.PullRequest, error) {
+	result, err := ds.upstream.GetPendingMilestonedPRsForChangelog(ctx, repoOwner, repoName, milestoneNumber)
+	if err != nil {
+		return nil, err
+	}
+	ds.lock.Lock()
+	defer ds.lock.Unlock()
+	ds.fixture.PendingPullRequests[milestoneKey(repoOwner, repoName, milestoneNumber)] = result
+	return result, nil
+}
+
+func (ds *RecordingDataSource) GetChangelogContent(ctx context.Context, repoOwner string, repoName string, version string) (string, error) {
+	result, err := ds.upstream.GetChangelogContent(ctx, repoOwner, repoName, version)
+	if err != nil {
+		// Missing changelogs are represented by their absence inside the
+		// fixture.
+		return "", err
+	}
+	ds.lock.Lock()
+	defer ds.lock.Unlock()
+	ds.fixture.Changelogs[changelogKey(repoOwner, repoName, version)] = result
+	return result, nil
+}
+
+func (ds *RecordingDataSource) GetPullRequestAuthors(ctx context.Context, repoOwner string, repoName string, numbers []int) (map[int]ghgql.PullRequestAuthors, error) {
+	result, err := ds.upstream.GetPullRequestAuthors(ctx, repoOwner, repoName, numbers)
+	if err != nil {
+		return nil, err
+	}
+	ds.lock.Lock()
+	defer ds.lock.Unlock()
+	key := repoKey(repoOwner, repoName)
+	if _, ok := ds.fixture.Authors[key]; !ok {
+		ds.fixture.Authors[key] = make(map[int]ghgql.PullRequestAuthors)
+	}
+	for num, authors := range result {
+		ds.fixture.Authors[key][num] = authors
+	}
+	return result, nil
+}
+
+func (ds *RecordingDataSource) CountMergedPullRequestsBefore(ctx context.Context, repoOwner string, repoName string, authors map[string]time.Time) (map[string]int, error) {
+	result, err := ds.upstream.CountMergedPullRequestsBefore(ctx, repoOwner, repoName, authors)
+	if err != nil {
+		return nil, err
+	}
+	ds.lock.Lock()
+	defer ds.lock.Unlock()
+	key := repoKey(repoOwner, repoName)
+	if _, ok := ds.fixture.MergedPullRequestCounts[key]; !ok {
+		ds.fixture.MergedPullRequestCounts[key] = make(map[string]int)
+	}
+	for login, count := range result {
+		ds.fixture.MergedPullRequestCounts[key][login] = count
+	}
+	return result, nil
+}
+
+func (ds *RecordingDataSource) GetSecurityAdvisories(ctx context.Context, repoOwner string, repoName string) ([]SecurityAdvisory, error) {
+	result, err := ds.upstream.GetSecurityAdvisories(ctx, repoOwner, repoName)
+	if err != nil {
+		return nil, err
+	}
+	ds.lock.Lock()
+	defer ds.lock.Unlock()
+	ds.fixture.SecurityAdvisories[repoKey(repoOwner, repoName)] = result
+	return result, nil
+}
@@ -0,0 +1,76 @@
+package changelog
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v50/github"
+	"github.com/grafana/grafana-github-actions-go/pkg/ghgql"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestFixture() *Fixture {
+	fixture := NewFixture()
+	fixture.Milestones["grafana/grafana"] = []*github.Milestone{
+		{
+			Number: pointerOf(1),
+			Title:  pointerOf("10.1.1"),
+			DueOn:  &github.Timestamp{Time: time.Date(2023, 8, 1, 0, 0, 0, 0, time.UTC)},
+		},
+	}
+	fixture.Milestones["grafana/grafana-enterprise"] = []*github.Milestone{
+		{
+			Number: pointerOf(2),
+			Title:  pointerOf("10.1.1"),
+		},
+	}
+	fixture.PullRequests["grafana/grafana#1"] = []ghgql.PullRequest{
+		{
+			Number:      pointerOf(100),
+			Title:       pointerOf("Alerting: Fix something"),
+			RepoOwner:   pointerOf("grafana"),
+			RepoName:    pointerOf("grafana"),
+			AuthorLogin: pointerOf("grafanabot"),
+			HeadRefName: pointerOf("backport-90-to-v10.1.x"),
+		},
+	}
+	fixture.PullRequests["grafana/grafana-enterprise#2"] = []ghgql.PullRequest{}
+	fixture.Authors["grafana/grafana"] = map[int]ghgql.PullRequestAuthors{
+		90: {Number: 90, Author: "author"},
+	}
+	return fixture
+}
+
+func TestBuildFromFixture(t *testing.T) {
+	ctx := context.Background()
+	body, err := Build(ctx, "10.1.1", NewFixtureDataSource(newTestFixture()), nil)
+	require.NoError(t, err)
+	require.Equal(t, "2023-08-01", body.ReleaseDate)
+	require.Len(t, body.Bugfixes, 1)
+	require.Equal(t, []string{"author"}, body.Bugfixes[0].Authors)
+
+	t.Run("missing-data", func(t *testing.T) {
+		fixture := newTestFixture()
+		delete(fixture.PullRequests, "grafana/grafana#1")
+		_, err := Build(ctx, "10.1.1", NewFixtureDataSource(fixture), nil)
+		require.ErrorContains(t, err, "grafana/grafana#1")
+	})
+}
+
+func TestRecordingDataSource(t *testing.T) {
+	ctx := context.Background()
+	recorder := NewRecordingDataSource(NewFixtureDataSource(newTestFixture()))
+	recorded, err := Build(ctx, "10.1.1", recorder, nil)
+	require.NoError(t, err)
+
+	path := filepath.Join(t.TempDir(), "fixture.json")
+	require.NoError(t, recorder.Fixture().Save(path))
+	fixture, err := LoadFixture(path)
+	require.NoError(t, err)
+
+	replayed, err := Build(ctx, "10.1.1", NewFixtureDataSource(fixture), nil)
+	require.NoError(t, err)
+	require.Equal(t, recorded, replayed)
+}
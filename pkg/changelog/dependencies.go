@@ -0,0 +1,169 @@
+package changelog
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/coreos/go-semver/semver"
+	"github.com/grafana/grafana-github-actions-go/pkg/ghgql"
+)
+
+const LabelDependencies = "dependencies"
+
+// DependencyUpdate aggregates all pull requests of a release that updated the
+// same package.
+type DependencyUpdate struct {
+	// Package is empty if the package could not be determined from the
+	// title. In that case only a single pull request is part of the update.
+	Package      string
+	From         string
+	To           string
+	PullRequests []ghgql.PullRequest
+}
+
+var dependencyPrefixPattern = regexp.MustCompile(`(?i)^(chore|fix|build)\(deps(-dev)?\)!?:\s*`)
+var dependencyTitlePatterns = []*regexp.Regexp{
+	regexp.MustCompile(`(?i)^bump (?P<package>\S+) from (?P<from>\S+) to (?P<to>\S+)`),
+	regexp.MustCompile(`(?i)^update (?:dependency |module )?(?P<package>\S+) (?:from (?P<from>\S+) )?to (?P<to>\S+)`),
+}
+
+// parseDependencyTitle extracts the package and versions from the title of a
+// dependency update pull request as created by Dependabot or Renovate.
+func parseDependencyTitle(title string) (pkg string, from string, to string, ok bool) {
+	title = stripReleaseStreamPrefix(title)
+	title = dependencyPrefixPattern.ReplaceAllString(title, "")
+	for _, pattern := range dependencyTitlePatterns {
+		match := pattern.FindStringSubmatch(title)
+		if match == nil {
+			continue
+		}
+		return match[pattern.SubexpIndex("package")], match[pattern.SubexpIndex("from")], strings.TrimSuffix(match[pattern.SubexpIndex("to")], "."), true
+	}
+	return "", "", "", false
+}
+
+// isDependencyUpdate returns true for pull requests that only update a
+// dependency. These are either created by a bot, use a `chore(deps):`-style
+// prefix or start with `Bump X from A to B`.
+func isDependencyUpdate(issue ghgql.PullRequest) bool {
+	title := stripReleaseStreamPrefix(issue.GetTitle())
+	if dependencyPrefixPattern.MatchString(title) {
+		return true
+	}
+	if issueHasLabel(issue, LabelDependencies) {
+		return true
+	}
+	_, _, _, ok := parseDependencyTitle(title)
+	if !ok {
+		return false
+	}
+	if isBotUser(issue) {
+		return true
+	}
+	return dependencyTitlePatterns[0].MatchString(title)
+}
+
+func addDependencyUpdate(body *ChangelogBody, issue ghgql.PullRequest) {
+	pkg, from, to, ok := parseDependencyTitle(issue.GetTitle())
+	if ok {
+		for idx, existing := range body.DependencyUpdates {
+			if existing.Package != pkg {
+				continue
+			}
+			existing.PullRequests = append(existing.PullRequests, issue)
+			existing.From = lowerVersion(existing.From, from)
+			existing.To = higherVersion(existing.To, to)
+			body.DependencyUpdates[idx] = existing
+			return
+		}
+	}
+	body.DependencyUpdates = append(body.DependencyUpdates, DependencyUpdate{
+		Package:      pkg,
+		From:         from,
+		To:           to,
+		PullRequests: []ghgql.PullRequest{issue},
+	})
+}
+
+func lowerVersion(a, b string) string {
+	if a == "" {
+		return b
+	}
+	if b == "" {
+		return a
+	}
+	va, errA := semver.NewVersion(strings.TrimPrefix(a, "v"))
+	vb, errB := semver.NewVersion(strings.TrimPrefix(b, "v"))
+	if errA != nil || errB != nil {
+		return a
+	}
+	if vb.LessThan(*va) {
+		return b
+	}
+	return a
+}
+
+func higherVersion(a, b string) string {
+	if a == "" {
+		return b
+	}
+	if b == "" {
+		return a
+	}
+	va, errA := semver.NewVersion(strings.TrimPrefix(a, "v"))
+	vb, errB := semver.NewVersion(strings.TrimPrefix(b, "v"))
+	if errA != nil || errB != nil {
+		return b
+	}
+	if va.LessThan(*vb) {
+		return b
+	}
+	return a
+}
+
+func (r *defaultRenderer) writeDependencyUpdates(out *strings.Builder, updates []DependencyUpdate) {
+	for _, update := range updates {
+		if update.Package == "" {
+			for _, issue := range update.PullRequests {
+				out.WriteString(r.issueAsMarkdown(issue))
+			}
+			continue
+		}
+		prs := make([]ghgql.PullRequest, len(update.PullRequests))
+		copy(prs, update.PullRequests)
+		sort.Slice(prs, func(i, j int) bool {
+			return prs[i].GetNumber() < prs[j].GetNumber()
+		})
+
+		out.WriteString("- `")
+		out.WriteString(update.Package)
+		out.WriteString("`")
+		if update.From != "" {
+			out.WriteString(" from ")
+			out.WriteString(escapeMarkdown(update.From))
+		}
+		if update.To != "" {
+			out.WriteString(" to ")
+			out.WriteString(escapeMarkdown(update.To))
+		}
+		out.WriteString(".")
+		links := make([]string, 0, len(prs))
+		enterprise := false
+		for _, issue := range prs {
+			if isEnterprisePR(issue) {
+				enterprise = true
+				continue
+			}
+			links = append(links, r.getIssueLink(issue))
+		}
+		if enterprise {
+			out.WriteString(" (Enterprise)")
+		}
+		if len(links) > 0 {
+			out.WriteString(" ")
+			out.WriteString(strings.Join(links, ", "))
+		}
+		out.WriteString("\n")
+	}
+}
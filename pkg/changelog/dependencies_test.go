@@ -0,0 +1,83 @@
+package changelog
+
+import (
+	"context"
+	"testing"
+
+	"github.com/grafana/grafana-github-actions-go/pkg/ghgql"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseDependencyTitle(t *testing.T) {
+	tests := []struct {
+		title      string
+		expectOK   bool
+		expectPkg  string
+		expectFrom string
+		expectTo   string
+	}{
+		{title: "Bump lodash from 4.17.20 to 4.17.21 in /ui", expectOK: true, expectPkg: "lodash", expectFrom: "4.17.20", expectTo: "4.17.21"},
+		{title: "chore(deps): bump github.com/foo/bar from 1.0.0 to 1.1.0", expectOK: true, expectPkg: "github.com/foo/bar", expectFrom: "1.0.0", expectTo: "1.1.0"},
+		{title: "chore(deps): update dependency @grafana/ui to v10.2.3", expectOK: true, expectPkg: "@grafana/ui", expectTo: "v10.2.3"},
+		{title: "[v10.0.x] fix(deps): update module golang.org/x/net to v0.17.0", expectOK: true, expectPkg: "golang.org/x/net", expectTo: "v0.17.0"},
+		{title: "Alerting: Fix something", expectOK: false},
+	}
+	for _, test := range tests {
+		t.Run(test.title, func(t *testing.T) {
+			pkg, from, to, ok := parseDependencyTitle(test.title)
+			require.Equal(t, test.expectOK, ok)
+			require.Equal(t, test.expectPkg, pkg)
+			require.Equal(t, test.expectFrom, from)
+			require.Equal(t, test.expectTo, to)
+		})
+	}
+}
+
+func TestIsDependencyUpdate(t *testing.T) {
+	t.Run("dependabot", func(t *testing.T) {
+		issue := ghgql.PullRequest{
+			Title:              pointerOf("Bump lodash from 4.17.20 to 4.17.21"),
+			AuthorResourcePath: pointerOf("/apps/dependabot"),
+		}
+		require.True(t, isDependencyUpdate(issue))
+	})
+	t.Run("deps-prefix", func(t *testing.T) {
+		issue := ghgql.PullRequest{
+			Title: pointerOf("chore(deps): Bump the npm group with 3 updates"),
+		}
+		require.True(t, isDependencyUpdate(issue))
+	})
+	t.Run("human-update", func(t *testing.T) {
+		issue := ghgql.PullRequest{
+			Title:       pointerOf("Chore: Update something to use something else"),
+			AuthorLogin: pointerOf("user"),
+		}
+		require.False(t, isDependencyUpdate(issue))
+	})
+}
+
+func TestDependencyUpdatesSection(t *testing.T) {
+	body := newChangelogBody()
+	body.Version = "1.0.0"
+	addToBody(body, ghgql.PullRequest{
+		Number:             pointerOf(2),
+		Title:              pointerOf("Bump lodash from 4.17.20 to 4.17.21"),
+		AuthorResourcePath: pointerOf("/apps/dependabot"),
+	})
+	addToBody(body, ghgql.PullRequest{
+		Number:             pointerOf(1),
+		Title:              pointerOf("Bump lodash from 4.17.19 to 4.17.20"),
+		AuthorResourcePath: pointerOf("/apps/dependabot"),
+	})
+	addToBody(body, ghgql.PullRequest{
+		Number: pointerOf(3),
+		Title:  pointerOf("chore(deps): Bump the npm group with 3 updates"),
+	})
+	require.Len(t, body.Features, 0)
+	require.Len(t, body.Bugfixes, 0)
+	require.Len(t, body.DependencyUpdates, 2)
+
+	output, err := NewRenderer(nil).Render(context.Background(), body)
+	require.NoError(t, err)
+	require.Equal(t, "# 1.0.0\n\n### Dependency updates\n\n- `lodash` from 4.17.19 to 4.17.21. [#1](https://github.com/grafana/grafana/issues/1), [#2](https://github.com/grafana/grafana/issues/2)\n- **chore(deps):** Bump the npm group with 3 updates. [#3](https://github.com/grafana/grafana/issues/3)\n\n", output)
+}
@@ -0,0 +1,193 @@
+package changelog
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/rs/zerolog"
+)
+
+// EntryChange describes an entry that differs between two versions of the
+// same changelog.
+type EntryChange struct {
+	Entry   Entry
+	Section string
+	// PreviousSection is only set for moved entries.
+	PreviousSection string
+}
+
+func (c EntryChange) String() string {
+	out := strings.Builder{}
+	out.WriteString(c.Entry.Title)
+	for _, num := range c.Entry.Numbers {
+		out.WriteString(" #")
+		out.WriteString(strconv.Itoa(num))
+	}
+	return out.String()
+}
+
+// ChangelogDiff contains all the entries that were added, removed, or moved
+// to a different section compared to a previously generated changelog.
+type ChangelogDiff struct {
+	Added   []EntryChange
+	Removed []EntryChange
+	Moved   []EntryChange
+}
+
+func (d *ChangelogDiff) IsEmpty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Moved) == 0
+}
+
+// Log writes all changes to the logger found in the context.
+func (d *ChangelogDiff) Log(ctx context.Context) {
+	logger := zerolog.Ctx(ctx)
+	if d.IsEmpty() {
+		logger.Info().Msg("No changes compared to the previous changelog")
+		return
+	}
+	for _, c := range d.Added {
+		logger.Info().Msgf("Added to %s: %s", c.Section, c)
+	}
+	for _, c := range d.Removed {
+		logger.Info().Msgf("Removed from %s: %s", c.Section, c)
+	}
+	for _, c := range d.Moved {
+		logger.Info().Msgf("Moved from %s to %s: %s", c.PreviousSection, c.Section, c)
+	}
+}
+
+// Markdown renders the changes so that they can be used e.g. inside the body
+// of a pull request.
+func (d *ChangelogDiff) Markdown() string {
+	out := strings.Builder{}
+	out.WriteString("### Changes compared to the previous changelog\n\n")
+	if d.IsEmpty() {
+		out.WriteString("No entries were added, removed, or moved.\n")
+		return out.String()
+	}
+	writeChanges := func(title string, changes []EntryChange, moved bool) {
+		if len(changes) == 0 {
+			return
+		}
+		out.WriteString("**")
+		out.WriteString(title)
+		out.WriteString(":**\n\n")
+		for _, c := range changes {
+			out.WriteString("- ")
+			out.WriteString(c.String())
+			out.WriteString(" (")
+			if moved {
+				out.WriteString(c.PreviousSection)
+				out.WriteString(" → ")
+			}
+			out.WriteString(c.Section)
+			out.WriteString(")\n")
+		}
+		out.WriteString("\n")
+	}
+	writeChanges("Added", d.Added, false)
+	writeChanges("Removed", d.Removed, false)
+	writeChanges("Moved", d.Moved, true)
+	return out.String()
+}
+
+// DiffChangelogs compares the entries of two rendered changelogs of the same
+// version.
+func DiffChangelogs(ctx context.Context, previous string, current string) (*ChangelogDiff, error) {
+	parser := NewParser()
+	previousSections, err := parser.Parse(ctx, strings.NewReader(previous))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse previous changelog: %w", err)
+	}
+	currentSections, err := parser.Parse(ctx, strings.NewReader(current))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse new changelog: %w", err)
+	}
+	return diffSections(previousSections, currentSections), nil
+}
+
+type sectionEntry struct {
+	Entry   Entry
+	Section string
+}
+
+func indexSections(sections []Section) ([]string, map[string]sectionEntry) {
+	keys := make([]string, 0, 20)
+	result := make(map[string]sectionEntry)
+	for _, section := range sections {
+		for _, entry := range section.Entries {
+			key := entryKey(entry)
+			if _, ok := result[key]; ok {
+				continue
+			}
+			keys = append(keys, key)
+			result[key] = sectionEntry{Entry: entry, Section: section.Title}
+		}
+	}
+	return keys, result
+}
+
+// entryKey identifies an entry by its pull request number and falls back to
+// the title for entries without link.
+func entryKey(entry Entry) string {
+	if len(entry.Numbers) > 0 {
+		return "#" + strconv.Itoa(entry.Numbers[0])
+	}
+	return "title:" + entry.Title
+}
+
+func diffSections(previous []Section, current []Section) *ChangelogDiff {
+	result := &ChangelogDiff{}
+	previousKeys, previousEntries := indexSections(previous)
+	currentKeys, currentEntries := indexSections(current)
+	for _, key := range currentKeys {
+		c := currentEntries[key]
+		p, found := previousEntries[key]
+		if !found {
+			result.Added = append(result.Added, EntryChange{Entry: c.Entry, Section: c.Section})
+			continue
+		}
+		if p.Section != c.Section {
+			result.Moved = append(result.Moved, EntryChange{Entry: c.Entry, Section: c.Section, PreviousSection: p.Section})
+		}
+	}
+	for _, key := range previousKeys {
+		if _, found := currentEntries[key]; !found {
+			p := previousEntries[key]
+			result.Removed = append(result.Removed, EntryChange{Entry: p.Entry, Section: p.Section})
+		}
+	}
+	return result
+}
+
+// LoadLocalContent returns the changelog of the given version from either
+// CHANGELOG.md or the archive file of its major version inside the repository
+// located at root.
+func LoadLocalContent(ctx context.Context, root string, version string) (string, bool, error) {
+	v, err := parseChangelogVersion(version)
+	if err != nil {
+		return "", false, err
+	}
+	for _, path := range []string{ChangelogFile, ArchiveFile(v.Major())} {
+		f, err := os.Open(filepath.Join(root, path))
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return "", false, err
+		}
+		content, found, err := ExtractContentForVersion(ctx, f, version, nil)
+		f.Close()
+		if err != nil {
+			return "", false, err
+		}
+		if found {
+			return content, true, nil
+		}
+	}
+	return "", false, nil
+}
@@ -0,0 +1,67 @@
+package changelog
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiffChangelogs(t *testing.T) {
+	ctx := context.Background()
+	previous := `# 10.0.1 (2023-06-01)
+
+### Features and enhancements
+
+- **Alerting:** Add something. [#1](https://github.com/grafana/grafana/issues/1), [@a](https://github.com/a)
+- **Auth:** Fix the login. [#2](https://github.com/grafana/grafana/issues/2), [@b](https://github.com/b)
+- **Enterprise:** Old entry. (Enterprise)
+
+### Bug fixes
+
+- **Dashboards:** Fix removed. [#3](https://github.com/grafana/grafana/issues/3), [@c](https://github.com/c)
+`
+	current := `# 10.0.1 (2023-06-01)
+
+### Features and enhancements
+
+- **Alerting:** Add something better. [#1](https://github.com/grafana/grafana/issues/1), [@a](https://github.com/a)
+- **Enterprise:** Old entry. (Enterprise)
+- **Panels:** Add new. [#4](https://github.com/grafana/grafana/issues/4), [@d](https://github.com/d)
+
+### Bug fixes
+
+- **Auth:** Fix the login. [#2](https://github.com/grafana/grafana/issues/2), [@b](https://github.com/b)
+`
+	diff, err := DiffChangelogs(ctx, previous, current)
+	require.NoError(t, err)
+	require.False(t, diff.IsEmpty())
+	require.Len(t, diff.Added, 1)
+	require.Equal(t, []int{4}, diff.Added[0].Entry.Numbers)
+	require.Equal(t, "Features and enhancements", diff.Added[0].Section)
+	require.Len(t, diff.Removed, 1)
+	require.Equal(t, []int{3}, diff.Removed[0].Entry.Numbers)
+	require.Len(t, diff.Moved, 1)
+	require.Equal(t, "Features and enhancements", diff.Moved[0].PreviousSection)
+	require.Equal(t, "Bug fixes", diff.Moved[0].Section)
+
+	require.Equal(t, `### Changes compared to the previous changelog
+
+**Added:**
+
+- Panels: Add new. #4 (Features and enhancements)
+
+**Removed:**
+
+- Dashboards: Fix removed. #3 (Bug fixes)
+
+**Moved:**
+
+- Auth: Fix the login. #2 (Features and enhancements → Bug fixes)
+
+`, diff.Markdown())
+
+	unchanged, err := DiffChangelogs(ctx, previous, previous)
+	require.NoError(t, err)
+	require.True(t, unchanged.IsEmpty())
+}
@@ -1,28 +1,35 @@
 package changelog
 
 import (
-	"bufio"
 	"bytes"
 	"context"
 	"fmt"
 	"io"
 	"os"
 	"regexp"
+	"strings"
 
-	"github.com/coreos/go-semver/semver"
+	"github.com/rs/zerolog"
 )
 
 var versionEndLinePattern = regexp.MustCompile("<!-- (.*) END -->")
 var versionStartLinePattern = regexp.MustCompile("<!-- (.*) START -->")
 
-func UpdateFileAtPath(ctx context.Context, file string, rendered string, body *ChangelogBody) error {
+type UpdateFileOptions struct {
+	// FoldPrereleases merges the entries of all pre-releases (e.g. 11.0.0-beta1)
+	// into the entry of their final release (e.g. 11.0.0) once that one is
+	// inserted. The pre-release entries are removed from the file.
+	FoldPrereleases bool
+}
+
+func UpdateFileAtPath(ctx context.Context, file string, rendered string, body *ChangelogBody, opts *UpdateFileOptions) error {
 	input, err := os.Open(file)
 	if err != nil {
 		return fmt.Errorf("failed to open input file: %w", err)
 	}
 	defer input.Close()
 	output := bytes.Buffer{}
-	if err := UpdateFile(ctx, &output, input, rendered, body); err != nil {
+	if err := UpdateFile(ctx, &output, input, rendered, body, opts); err != nil {
 		return err
 	}
 	return os.WriteFile(file, output.Bytes(), 0o644)
@@ -30,75 +37,212 @@ func UpdateFileAtPath(ctx context.Context, file string, rendered string, body *C
 
 // UpdateFile receives the original changelog data via the `in` parameter and
 // writes it back to the `out` parameter with the `body` behing inserted.
-func UpdateFile(ctx context.Context, out io.Writer, in io.Reader, rendered string, body *ChangelogBody) error {
-	newVersion := semver.New(body.Version)
-	scanner := bufio.NewScanner(in)
-	scanner.Split(bufio.ScanLines)
-	var inserted bool
+// Entries are sorted by version with pre-releases placed below their final
+// release. Markers that don't contain a valid version are left where they
+// are.
+func UpdateFile(ctx context.Context, out io.Writer, in io.Reader, rendered string, body *ChangelogBody, opts *UpdateFileOptions) error {
+	if opts == nil {
+		opts = &UpdateFileOptions{}
+	}
+	logger := zerolog.Ctx(ctx)
+	newVersion, err := parseChangelogVersion(body.Version)
+	if err != nil {
+		return err
+	}
+	doc, err := parseChangelogDocument(in)
+	if err != nil {
+		return fmt.Errorf("failed to parse changelog: %w", err)
+	}
 
-	insertEntry := func() {
-		if inserted {
-			return
+	blocks := make([]changelogBlock, 0, len(doc.Blocks)+1)
+	prereleases := make([]changelogBlock, 0, 5)
+	for _, block := range doc.Blocks {
+		v, err := parseChangelogVersion(block.Version)
+		if err != nil {
+			logger.Warn().Err(err).Msg("Ignoring changelog marker")
+			blocks = append(blocks, block)
+			continue
 		}
-		out.Write([]byte("<!-- "))
-		out.Write([]byte(body.Version))
-		out.Write([]byte(" START -->\n\n"))
-
-		out.Write([]byte(rendered))
+		if opts.FoldPrereleases && v.IsPrereleaseOf(newVersion) {
+			logger.Info().Msgf("Folding %s into %s", block.Version, body.Version)
+			prereleases = append(prereleases, block)
+			continue
+		}
+		blocks = append(blocks, block)
+	}
+	if len(prereleases) > 0 {
+		rendered = foldPrereleases(rendered, prereleases)
+	}
+	newBlock := newChangelogBlock(body.Version, rendered)
 
-		out.Write([]byte("<!-- "))
-		out.Write([]byte(body.Version))
-		out.Write([]byte(" END -->\n"))
+	inserted := false
+	for idx, block := range blocks {
+		v, err := parseChangelogVersion(block.Version)
+		if err != nil {
+			continue
+		}
+		cmp := v.Compare(newVersion)
+		if cmp > 0 {
+			continue
+		}
+		if cmp == 0 {
+			blocks[idx] = newBlock
+		} else {
+			blocks = append(blocks[:idx], append([]changelogBlock{newBlock}, blocks[idx:]...)...)
+		}
 		inserted = true
+		break
+	}
+	if !inserted {
+		blocks = append(blocks, newBlock)
+	}
+	doc.Blocks = blocks
+	return doc.write(out)
+}
+
+func newChangelogBlock(version string, rendered string) changelogBlock {
+	content := strings.Builder{}
+	content.WriteString("<!-- ")
+	content.WriteString(version)
+	content.WriteString(" START -->\n\n")
+	content.WriteString(rendered)
+	if !strings.HasSuffix(rendered, "\n") {
+		content.WriteString("\n")
+	}
+	content.WriteString("<!-- ")
+	content.WriteString(version)
+	content.WriteString(" END -->")
+	return changelogBlock{
+		Version: version,
+		Lines:   strings.Split(content.String(), "\n"),
+	}
+}
+
+// content returns the lines between the start and end marker of the block.
+func (b changelogBlock) content() []string {
+	result := make([]string, 0, len(b.Lines))
+	for _, line := range b.Lines {
+		if versionStartLinePattern.MatchString(line) {
+			continue
+		}
+		if versionEndLinePattern.MatchString(line) {
+			break
+		}
+		result = append(result, line)
+	}
+	return result
+}
+
+// markdownSection is a third-level section of a rendered changelog.
+type markdownSection struct {
+	Title string
+	Lines []string
+}
+
+func splitSections(lines []string) ([]string, []markdownSection) {
+	head := make([]string, 0, 3)
+	sections := make([]markdownSection, 0, 5)
+	for _, line := range lines {
+		if strings.HasPrefix(line, "### ") {
+			sections = append(sections, markdownSection{Title: strings.TrimPrefix(line, "### ")})
+			continue
+		}
+		if len(sections) == 0 {
+			head = append(head, line)
+		} else {
+			sections[len(sections)-1].Lines = append(sections[len(sections)-1].Lines, line)
+		}
 	}
+	return head, sections
+}
 
-	lines := make([]string, 0, 50)
-	insertAfterIdx := -1
-	replaceAfterIdx := -1
-	replaceBeforeIdx := -1
-	idx := 0
-	for scanner.Scan() {
-		line := string(scanner.Bytes())
-		lines = append(lines, line)
-		if match := versionStartLinePattern.FindStringSubmatch(line); len(match) > 1 {
-			version := match[1]
-			v := semver.New(version)
-			if v.Equal(*newVersion) {
-				replaceAfterIdx = idx
+// foldPrereleases adds all list entries of the given pre-release blocks to
+// the matching sections of the rendered changelog unless they are already
+// present there.
+func foldPrereleases(rendered string, prereleases []changelogBlock) string {
+	head, sections := splitSections(strings.Split(strings.TrimRight(rendered, "\n"), "\n"))
+	known := make(map[string]struct{})
+	knownNumbers := make(map[int]struct{})
+	for _, section := range sections {
+		for _, line := range section.Lines {
+			known[line] = struct{}{}
+			for _, num := range parseEntryNumbers(line) {
+				knownNumbers[num] = struct{}{}
 			}
 		}
-		if match := versionEndLinePattern.FindStringSubmatch(line); len(match) > 1 {
-			version := match[1]
-			v := semver.New(version)
-			if v.Equal(*newVersion) {
-				replaceBeforeIdx = idx
-			} else if !v.LessThan(*newVersion) {
-				insertAfterIdx = idx
+	}
+	isKnown := func(line string) bool {
+		if _, ok := known[line]; ok {
+			return true
+		}
+		numbers := parseEntryNumbers(line)
+		for _, num := range numbers {
+			if _, ok := knownNumbers[num]; !ok {
+				return false
 			}
 		}
-		idx++
+		return len(numbers) > 0
 	}
 
-	for idx, line := range lines {
-		// If we are in the range that we want to replace, skip those lines:
-		if replaceAfterIdx != -1 && replaceBeforeIdx != -1 && idx <= replaceBeforeIdx && idx >= replaceAfterIdx {
-			continue
+	for _, block := range prereleases {
+		_, preSections := splitSections(block.content())
+		for _, preSection := range preSections {
+			idx := -1
+			for i, section := range sections {
+				if section.Title == preSection.Title {
+					idx = i
+					break
+				}
+			}
+			if idx == -1 {
+				sections = append(sections, markdownSection{Title: preSection.Title, Lines: []string{""}})
+				idx = len(sections) - 1
+			}
+			for _, line := range preSection.Lines {
+				if !strings.HasPrefix(line, "- ") || isKnown(line) {
+					continue
+				}
+				sections[idx].Lines = appendEntryLine(sections[idx].Lines, line)
+				known[line] = struct{}{}
+			}
 		}
-		if replaceAfterIdx != -1 && replaceAfterIdx == idx {
-			insertEntry()
+	}
+
+	out := strings.Builder{}
+	for _, line := range head {
+		out.WriteString(line)
+		out.WriteString("\n")
+	}
+	for _, section := range sections {
+		out.WriteString("### ")
+		out.WriteString(section.Title)
+		out.WriteString("\n")
+		lines := section.Lines
+		for len(lines) > 0 && lines[len(lines)-1] == "" {
+			lines = lines[:len(lines)-1]
 		}
-		if insertAfterIdx < idx {
-			insertEntry()
+		for _, line := range lines {
+			out.WriteString(line)
+			out.WriteString("\n")
 		}
+		out.WriteString("\n")
+	}
+	return out.String()
+}
 
-		out.Write([]byte(line))
-		out.Write([]byte("\n"))
-		if insertAfterIdx == idx {
-			insertEntry()
+// appendEntryLine adds the line after the last list entry of a section.
+func appendEntryLine(lines []string, line string) []string {
+	last := -1
+	for idx, l := range lines {
+		if strings.HasPrefix(l, "- ") {
+			last = idx
 		}
 	}
-	if !inserted {
-		insertEntry()
+	if last == -1 {
+		return append(lines, line)
 	}
-	return nil
+	result := make([]string, 0, len(lines)+1)
+	result = append(result, lines[:last+1]...)
+	result = append(result, line)
+	return append(result, lines[last+1:]...)
 }
@@ -50,8 +50,81 @@ func TestFileUpdater(t *testing.T) {
 		t.Run(test.name, func(t *testing.T) {
 			in := bytes.NewBufferString(test.input)
 			out := bytes.Buffer{}
-			require.NoError(t, UpdateFile(context.Background(), &out, in, renderedMarkdown, &body))
+			require.NoError(t, UpdateFile(context.Background(), &out, in, renderedMarkdown, &body, nil))
 			require.Equal(t, test.expectedOutput, out.String())
 		})
 	}
 }
+
+func TestFileUpdaterVersions(t *testing.T) {
+	tests := []struct {
+		name           string
+		version        string
+		input          string
+		expectedOutput string
+	}{
+		{
+			name:           "prerelease-below-final",
+			version:        "11.0.0-beta1",
+			input:          block("11.0.0") + block("10.4.0"),
+			expectedOutput: block("11.0.0") + block("11.0.0-beta1") + block("10.4.0"),
+		},
+		{
+			name:           "prerelease-order",
+			version:        "11.0.0-beta2",
+			input:          block("11.0.0-rc1") + block("11.0.0-beta10") + block("11.0.0-beta1") + block("11.0.0-preview"),
+			expectedOutput: block("11.0.0-rc1") + block("11.0.0-beta10") + block("11.0.0-beta2") + block("11.0.0-beta1") + block("11.0.0-preview"),
+		},
+		{
+			name:           "final-above-prereleases",
+			version:        "11.0.0",
+			input:          block("11.0.0-beta1") + block("10.4.0"),
+			expectedOutput: block("11.0.0") + block("11.0.0-beta1") + block("10.4.0"),
+		},
+		{
+			name:           "release-stream",
+			version:        "10.4.x",
+			input:          block("11.0.0") + block("10.4.2") + block("10.3.0"),
+			expectedOutput: block("11.0.0") + block("10.4.x") + block("10.4.2") + block("10.3.0"),
+		},
+		{
+			name:           "stray-marker",
+			version:        "10.4.1",
+			input:          block("10.4.2") + "<!-- something START -->\n" + block("10.4.0"),
+			expectedOutput: block("10.4.2") + "<!-- something START -->\n" + block("10.4.1") + block("10.4.0"),
+		},
+		{
+			name:           "preamble",
+			version:        "10.4.1",
+			input:          "# Changelog\n\n" + block("10.4.0"),
+			expectedOutput: "# Changelog\n\n" + block("10.4.1") + block("10.4.0"),
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			rendered, body := renderVersion(t, test.version)
+			out := bytes.Buffer{}
+			require.NoError(t, UpdateFile(context.Background(), &out, bytes.NewBufferString(test.input), rendered, body, nil))
+			require.Equal(t, test.expectedOutput, out.String())
+		})
+	}
+
+	t.Run("invalid-version", func(t *testing.T) {
+		rendered, body := renderVersion(t, "latest")
+		out := bytes.Buffer{}
+		require.Error(t, UpdateFile(context.Background(), &out, bytes.NewBufferString(block("10.4.0")), rendered, body, nil))
+	})
+}
+
+func TestFileUpdaterFoldPrereleases(t *testing.T) {
+	input := "<!-- 11.0.0-beta2 START -->\n\n# 11.0.0-beta2\n\n### Bug fixes\n\n- **Alerting:** Fix beta. [#2](https://github.com/grafana/grafana/issues/2)\n\n<!-- 11.0.0-beta2 END -->\n" +
+		"<!-- 11.0.0-beta1 START -->\n\n# 11.0.0-beta1\n\n### Features and enhancements\n\n- **Alerting:** Add something. [#1](https://github.com/grafana/grafana/issues/1)\n- **Alerting:** Add other. [#3](https://github.com/grafana/grafana/issues/3)\n\n<!-- 11.0.0-beta1 END -->\n" +
+		block("10.4.0")
+	rendered := "# 11.0.0 (2024-05-14)\n\n### Features and enhancements\n\n- **Alerting:** Add something. [#1](https://github.com/grafana/grafana/issues/1)\n- **Alerting:** Add final. [#4](https://github.com/grafana/grafana/issues/4)\n\n"
+	body := &ChangelogBody{Version: "11.0.0"}
+	out := bytes.Buffer{}
+	require.NoError(t, UpdateFile(context.Background(), &out, bytes.NewBufferString(input), rendered, body, &UpdateFileOptions{FoldPrereleases: true}))
+	expected := "<!-- 11.0.0 START -->\n\n# 11.0.0 (2024-05-14)\n\n### Features and enhancements\n\n- **Alerting:** Add something. [#1](https://github.com/grafana/grafana/issues/1)\n- **Alerting:** Add final. [#4](https://github.com/grafana/grafana/issues/4)\n- **Alerting:** Add other. [#3](https://github.com/grafana/grafana/issues/3)\n\n### Bug fixes\n\n- **Alerting:** Fix beta. [#2](https://github.com/grafana/grafana/issues/2)\n\n<!-- 11.0.0 END -->\n" +
+		block("10.4.0")
+	require.Equal(t, expected, out.String())
+}
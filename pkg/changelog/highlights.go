@@ -0,0 +1,129 @@
+package changelog
+
+import (
+	"strings"
+
+	"github.com/grafana/grafana-github-actions-go/pkg/ghgql"
+)
+
+// LabelHighlight marks pull requests that should be mentioned in the
+// highlights at the top of the changelog.
+const LabelHighlight = "changelog/highlight"
+
+// highlightHeading is the heading inside the body of a pull request that
+// introduces the summary used for the highlights. If it is missing, the title
+// of the changelog entry is used on its own.
+const highlightHeading = "Changelog highlight"
+
+const highlightsSectionTitle = "Highlights"
+
+// Highlight is a pull request that is mentioned at the top of the changelog
+// together with a short summary.
+type Highlight struct {
+	PullRequest ghgql.PullRequest
+	Summary     string
+}
+
+func isHighlight(issue ghgql.PullRequest) bool {
+	return issueHasLabel(issue, LabelHighlight)
+}
+
+// getHighlightSummary returns the paragraphs following the highlight heading
+// up until the next heading. HTML comments (e.g. instructions of the pull
+// request template) are dropped.
+func getHighlightSummary(issue ghgql.PullRequest) string {
+	lines := make([]string, 0, 5)
+	inSection := false
+	inComment := false
+	for _, line := range strings.Split(issue.GetBody(), "\n") {
+		l := strings.TrimSpace(line)
+		if !inSection {
+			if strings.HasPrefix(l, "#") && strings.Contains(l, highlightHeading) {
+				inSection = true
+			}
+			continue
+		}
+		if inComment {
+			if strings.Contains(l, "-->") {
+				inComment = false
+			}
+			continue
+		}
+		if strings.HasPrefix(l, "<!--") {
+			inComment = !strings.Contains(l, "-->")
+			continue
+		}
+		if strings.HasPrefix(l, "#") {
+			break
+		}
+		lines = append(lines, l)
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
+
+func addHighlight(body *ChangelogBody, issue ghgql.PullRequest) {
+	body.Highlights = append(body.Highlights, Highlight{
+		PullRequest: issue,
+		Summary:     getHighlightSummary(issue),
+	})
+}
+
+func (r *defaultRenderer) writeHighlights(out *strings.Builder, highlights []Highlight) {
+	if len(highlights) == 0 {
+		return
+	}
+	out.WriteString("### ")
+	out.WriteString(highlightsSectionTitle)
+	out.WriteString("\n\n")
+	for _, highlight := range highlights {
+		out.WriteString(r.highlightAsMarkdown(highlight))
+		out.WriteString("\n\n")
+	}
+}
+
+func (r *defaultRenderer) highlightAsMarkdown(highlight Highlight) string {
+	issue := highlight.PullRequest
+	out := strings.Builder{}
+	out.WriteString("**")
+	out.WriteString(strings.TrimSpace(PreparePRTitle(issue)))
+	out.WriteString("**")
+	if highlight.Summary != "" {
+		out.WriteString(" ")
+		out.WriteString(highlight.Summary)
+	}
+	if !isEnterprisePR(issue) {
+		out.WriteString(" (")
+		out.WriteString(r.getIssueLink(issue))
+		out.WriteString(")")
+	}
+	return out.String()
+}
+
+// SplitHighlights removes the highlights section from a rendered changelog
+// and returns its content without the heading separately so that it can be
+// used as the opening of a release announcement.
+func SplitHighlights(content string) (string, string) {
+	lines := strings.Split(content, "\n")
+	start := -1
+	end := len(lines)
+	for idx, line := range lines {
+		if start == -1 {
+			if strings.TrimSpace(line) == "### "+highlightsSectionTitle {
+				start = idx
+			}
+			continue
+		}
+		if strings.HasPrefix(line, "#") {
+			end = idx
+			break
+		}
+	}
+	if start == -1 {
+		return "", content
+	}
+	highlights := strings.TrimSpace(strings.Join(lines[start+1:end], "\n"))
+	rest := make([]string, 0, len(lines))
+	rest = append(rest, lines[:start]...)
+	rest = append(rest, lines[end:]...)
+	return highlights, strings.TrimSpace(strings.Join(rest, "\n"))
+}
@@ -0,0 +1,69 @@
+package changelog
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/grafana/grafana-github-actions-go/pkg/ghgql"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetHighlightSummary(t *testing.T) {
+	tests := []struct {
+		name     string
+		body     string
+		expected string
+	}{
+		{
+			name:     "no-section",
+			body:     "Hello world",
+			expected: "",
+		},
+		{
+			name:     "section",
+			body:     "Hello\n\n## Changelog highlight\n\n<!-- Describe the change for the release notes -->\nTeams can now be notified.\n\nWith cards.\n\n## Other\n\nSomething",
+			expected: "Teams can now be notified.\n\nWith cards.",
+		},
+		{
+			name:     "multiline-comment",
+			body:     "## Changelog highlight\n<!--\nInstructions\n-->\nTeams can now be notified.",
+			expected: "Teams can now be notified.",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			require.Equal(t, test.expected, getHighlightSummary(ghgql.PullRequest{Body: pointerOf(test.body)}))
+		})
+	}
+}
+
+func TestRenderHighlights(t *testing.T) {
+	body := newChangelogBody()
+	body.Version = "10.1.0"
+	addToBody(body, ghgql.PullRequest{
+		Number: pointerOf(123),
+		Title:  pointerOf("Alerting: Add Teams contact point"),
+		Body:   pointerOf("## Changelog highlight\n\nTeams can now be notified."),
+		Labels: []string{LabelHighlight},
+	})
+	require.Len(t, body.Highlights, 1)
+	require.Len(t, body.Features, 1)
+
+	output, err := NewRenderer(nil).Render(context.Background(), body)
+	require.NoError(t, err)
+	require.Equal(t, "# 10.1.0\n\n### Highlights\n\n"+
+		"**Alerting: Add Teams contact point.** Teams can now be notified. ([#123](https://github.com/grafana/grafana/issues/123))\n\n"+
+		"### Features and enhancements\n\n"+
+		"- **Alerting:** Add Teams contact point. [#123](https://github.com/grafana/grafana/issues/123)\n\n", output)
+
+	highlights, rest := SplitHighlights(output)
+	require.Equal(t, "**Alerting: Add Teams contact point.** Teams can now be notified. ([#123](https://github.com/grafana/grafana/issues/123))", highlights)
+	require.Equal(t, "# 10.1.0\n\n### Features and enhancements\n\n- **Alerting:** Add Teams contact point. [#123](https://github.com/grafana/grafana/issues/123)", rest)
+
+	// The highlights are not treated as regular entries when parsing the
+	// changelog again:
+	sections, err := NewParser().Parse(context.Background(), strings.NewReader(output))
+	require.NoError(t, err)
+	require.Len(t, sections, 1)
+}
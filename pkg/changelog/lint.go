@@ -0,0 +1,137 @@
+package changelog
+
+import (
+	"context"
+	"regexp"
+	"strings"
+
+	"github.com/grafana/grafana-github-actions-go/pkg/ghgql"
+	"github.com/grafana/grafana-github-actions-go/pkg/toolkit"
+)
+
+const (
+	LintFieldTitle = "title"
+	LintFieldBody  = "body"
+)
+
+// LintProblem describes a way in which a pull request doesn't follow the
+// conventions the changelog generation relies on.
+type LintProblem struct {
+	// Field is either LintFieldTitle or LintFieldBody.
+	Field   string
+	Message string
+}
+
+// LintResult contains all problems found for a pull request together with a
+// preview of how it would be rendered inside the changelog.
+type LintResult struct {
+	Problems []LintProblem
+	// Section is the title of the section the entry would be listed in.
+	Section string
+	// Preview is the rendered list item of the entry.
+	Preview string
+	// Excluded is set if the body of the pull request excludes it from the
+	// changelog.
+	Excluded bool
+}
+
+var trailingReleaseStreamPattern = regexp.MustCompile(`[\[(]v?\d+\.\d+\.(x|\d+)[\])]\s*$`)
+var leadingReleaseStreamPattern = regexp.MustCompile(`^\[[^]]+\]`)
+
+// Lint checks the pull request against the conventions used by the changelog
+// generation (area prefix in the title, well-formed release notices, ...). If
+// a toolkit is provided, the authors of the entry are resolved for the preview.
+func Lint(ctx context.Context, tk *toolkit.Toolkit, issue ghgql.PullRequest) LintResult {
+	result := LintResult{
+		Problems: make([]LintProblem, 0, 5),
+	}
+	title := stripReleaseStreamPrefix(entryTitle(issue))
+	if !hasAreaPrefix(title) {
+		result.Problems = append(result.Problems, LintProblem{
+			Field:   LintFieldTitle,
+			Message: "The title should start with the affected area followed by a colon, e.g. `Alerting: Fix something`.",
+		})
+	}
+	if leadingReleaseStreamPattern.MatchString(title) || trailingReleaseStreamPattern.MatchString(title) {
+		result.Problems = append(result.Problems, LintProblem{
+			Field:   LintFieldTitle,
+			Message: "The title contains a release-stream marker (e.g. `[v10.0.x]`) that would end up in the changelog.",
+		})
+	}
+	if hasEmptyNotice(issue, "Release notice breaking change") {
+		result.Problems = append(result.Problems, LintProblem{
+			Field:   LintFieldBody,
+			Message: "The breaking change section is empty. Either describe the breaking change or remove the heading.",
+		})
+	}
+	if hasEmptyNotice(issue, "Deprecation notice") {
+		result.Problems = append(result.Problems, LintProblem{
+			Field:   LintFieldBody,
+			Message: "The deprecation notice section is empty. Either describe the deprecation or remove the heading.",
+		})
+	}
+
+	override, overrideProblems := parseEntryOverride(issue.GetBody())
+	for _, problem := range overrideProblems {
+		result.Problems = append(result.Problems, LintProblem{
+			Field:   LintFieldBody,
+			Message: problem,
+		})
+	}
+	if override.Exclude {
+		result.Excluded = true
+		return result
+	}
+
+	if tk != nil && tk.GitHubGQLClient() != nil {
+		issues := []ghgql.PullRequest{issue}
+		resolveAuthors(ctx, tk.GitHubGQLClient(), issues)
+		issue = issues[0]
+	}
+
+	r := &defaultRenderer{tk: tk}
+	result.Section = entrySection(issue)
+	result.Preview = strings.TrimSpace(r.issueAsMarkdown(issue))
+	return result
+}
+
+func hasAreaPrefix(title string) bool {
+	match := titleHeadlinePattern.FindString(title)
+	if match == "" {
+		return false
+	}
+	area := strings.TrimSpace(strings.TrimSuffix(match, ":"))
+	description := strings.TrimSpace(strings.TrimPrefix(title, match))
+	return area != "" && description != ""
+}
+
+// hasEmptyNotice returns true if the body of the pull request contains the
+// heading of a notice but no content for it.
+func hasEmptyNotice(issue ghgql.PullRequest, sectionStart string) bool {
+	if !strings.Contains(issue.GetBody(), sectionStart) {
+		return false
+	}
+	notice := getNotice(issue, sectionStart)
+	// If the notice is directly followed by another heading, it is empty as
+	// well:
+	return notice == "" || strings.HasPrefix(notice, "#")
+}
+
+// entrySection returns the title of the section the pull request would be
+// listed in by the default renderer.
+func entrySection(issue ghgql.PullRequest) string {
+	body := newChangelogBody()
+	addToBody(body, issue)
+	switch {
+	case len(body.SecurityFixes) > 0:
+		return "Security fixes"
+	case len(body.DependencyUpdates) > 0:
+		return "Dependency updates"
+	case len(body.PluginDevChanges) > 0:
+		return "Plugin development fixes & changes"
+	case len(body.Bugfixes) > 0:
+		return "Bug fixes"
+	default:
+		return "Features and enhancements"
+	}
+}
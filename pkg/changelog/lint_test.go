@@ -0,0 +1,106 @@
+package changelog
+
+import (
+	"context"
+	"testing"
+
+	"github.com/grafana/grafana-github-actions-go/pkg/ghgql"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLint(t *testing.T) {
+	tests := []struct {
+		name             string
+		issue            ghgql.PullRequest
+		expectedProblems []string
+		expectedSection  string
+		expectedPreview  string
+	}{
+		{
+			name: "valid",
+			issue: ghgql.PullRequest{
+				Number: pointerOf(123),
+				Title:  pointerOf("Alerting: Fix something"),
+			},
+			expectedProblems: []string{},
+			expectedSection:  "Bug fixes",
+			expectedPreview:  "- **Alerting:** Fix something. [#123](https://github.com/grafana/grafana/issues/123)",
+		},
+		{
+			name: "missing-area",
+			issue: ghgql.PullRequest{
+				Number: pointerOf(123),
+				Title:  pointerOf("Add something"),
+			},
+			expectedProblems: []string{LintFieldTitle},
+			expectedSection:  "Features and enhancements",
+			expectedPreview:  "- Add something. [#123](https://github.com/grafana/grafana/issues/123)",
+		},
+		{
+			name: "trailing-release-stream",
+			issue: ghgql.PullRequest{
+				Number: pointerOf(123),
+				Title:  pointerOf("Alerting: Add something [v10.0.x]"),
+			},
+			expectedProblems: []string{LintFieldTitle},
+			expectedSection:  "Features and enhancements",
+			expectedPreview:  "- **Alerting:** Add something [v10.0.x]. [#123](https://github.com/grafana/grafana/issues/123)",
+		},
+		{
+			name: "empty-breaking-change",
+			issue: ghgql.PullRequest{
+				Number: pointerOf(123),
+				Title:  pointerOf("Alerting: Add something"),
+				Body:   pointerOf("Hello\n\n## Release notice breaking change\n\n"),
+			},
+			expectedProblems: []string{LintFieldBody},
+			expectedSection:  "Features and enhancements",
+			expectedPreview:  "- **Alerting:** Add something. [#123](https://github.com/grafana/grafana/issues/123)",
+		},
+		{
+			name: "breaking-change-followed-by-heading",
+			issue: ghgql.PullRequest{
+				Number: pointerOf(123),
+				Title:  pointerOf("Alerting: Add something"),
+				Body:   pointerOf("Hello\n\n## Release notice breaking change\n\n## Other\n\nSomething"),
+			},
+			expectedProblems: []string{LintFieldBody},
+			expectedSection:  "Features and enhancements",
+			expectedPreview:  "- **Alerting:** Add something. [#123](https://github.com/grafana/grafana/issues/123)",
+		},
+		{
+			name: "title-override",
+			issue: ghgql.PullRequest{
+				Number: pointerOf(123),
+				Title:  pointerOf("Add something"),
+				Body:   pointerOf("Hello\n\n```changelog\ntitle: Alerting: Add something\nsection: security\n```"),
+			},
+			expectedProblems: []string{},
+			expectedSection:  "Security fixes",
+			expectedPreview:  "- **Alerting:** Add something. [#123](https://github.com/grafana/grafana/issues/123)",
+		},
+		{
+			name: "invalid-override",
+			issue: ghgql.PullRequest{
+				Number: pointerOf(123),
+				Title:  pointerOf("Alerting: Add something"),
+				Body:   pointerOf("```changelog\nsection: misc\n```"),
+			},
+			expectedProblems: []string{LintFieldBody},
+			expectedSection:  "Features and enhancements",
+			expectedPreview:  "- **Alerting:** Add something. [#123](https://github.com/grafana/grafana/issues/123)",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result := Lint(context.Background(), nil, test.issue)
+			fields := make([]string, 0, len(result.Problems))
+			for _, p := range result.Problems {
+				fields = append(fields, p.Field)
+			}
+			require.Equal(t, test.expectedProblems, fields)
+			require.Equal(t, test.expectedSection, result.Section)
+			require.Equal(t, test.expectedPreview, result.Preview)
+		})
+	}
+}
@@ -10,6 +10,7 @@ import (
 	"strings"
 
 	"github.com/google/go-github/v50/github"
+	"github.com/grafana/grafana-github-actions-go/pkg/toolkit"
 	"github.com/grafana/grafana-github-actions-go/pkg/versions"
 	"github.com/rs/zerolog"
 )
@@ -39,7 +40,6 @@ func NewLoader(gh *github.Client) *Loader {
 }
 
 func (l *Loader) LoadContent(ctx context.Context, repoOwner string, repoName string, version string, opts *LoaderOptions) (string, error) {
-	logger := zerolog.Ctx(ctx)
 	vel := strings.Split(strings.TrimPrefix(version, "v"), ".")
 	if len(vel) < 1 {
 		return "", fmt.Errorf("unsupported version provided")
@@ -54,36 +54,83 @@ func (l *Loader) LoadContent(ctx context.Context, repoOwner string, repoName str
 	if err != nil {
 		return "", err
 	}
+	refs := []string{versionBranch}
+	// Security releases might not have a release branch of their own and so
+	// the branch of the release they are based on is checked as well:
+	if parsed, err := versions.Parse(version); err == nil && parsed.IsSecurityRelease() {
+		baseBranch, err := versions.ReleaseBranch(parsed.WithoutBuildmeta().String())
+		if err != nil {
+			return "", err
+		}
+		refs = append(refs, baseBranch)
+	}
 
 	// Loads the CHANGELOG from the v branch instead of main
+	for _, ref := range refs {
+		content, found, err := l.loadContentFromRef(ctx, repoOwner, repoName, ref, fileCandidates, version, opts)
+		if err != nil {
+			return "", err
+		}
+		if found {
+			return content, nil
+		}
+	}
+
+	return "", NoChangelogFound{Version: version}
+}
+
+func (l *Loader) loadContentFromRef(ctx context.Context, repoOwner string, repoName string, ref string, fileCandidates []string, version string, opts *LoaderOptions) (string, bool, error) {
+	logger := zerolog.Ctx(ctx)
 	for _, clPath := range fileCandidates {
 		fc, _, resp, err := l.gh.Repositories.GetContents(ctx, repoOwner, repoName, clPath, &github.RepositoryContentGetOptions{
-			Ref: versionBranch,
+			Ref: ref,
 		})
-		if resp.StatusCode == http.StatusNotFound {
-			logger.Warn().Msgf("Changelog file not found: %s", clPath)
+		if resp != nil && resp.StatusCode == http.StatusNotFound {
+			logger.Warn().Msgf("Changelog file not found: %s@%s", clPath, ref)
 			continue
 		}
 		if err != nil {
-			return "", err
+			return "", false, err
 		}
 		rawContent, err := fc.GetContent()
 		if err != nil {
-			return "", err
+			return "", false, err
 		}
 		buf := bytes.NewBufferString(rawContent)
 		clContent, found, err := ExtractContentForVersion(ctx, buf, version, &ExtractContentOptions{
 			RemoveHeadling: opts.RemoveHeading,
 		})
 		if err != nil {
-			return "", err
+			return "", false, err
 		}
 		if found {
-			return clContent, nil
+			return clContent, true, nil
 		}
 	}
+	return "", false, nil
+}
 
-	return "", NoChangelogFound{Version: version}
+// LoadOrBuild returns the changelog of the given version without its
+// heading. For the default format, the existing changelog is loaded from the
+// repository. Any other format renders the changelog from the milestone
+// instead.
+func LoadOrBuild(ctx context.Context, tk *toolkit.Toolkit, ds DataSource, repoOwner string, repoName string, version string, format string) (string, error) {
+	if format == "" || format == FormatDefault {
+		return ds.GetChangelogContent(ctx, repoOwner, repoName, version)
+	}
+	renderer, err := NewRendererForFormat(tk, format)
+	if err != nil {
+		return "", err
+	}
+	body, err := Build(ctx, version, ds, nil)
+	if err != nil {
+		return "", err
+	}
+	output, err := renderer.Render(ctx, body)
+	if err != nil {
+		return "", err
+	}
+	return StripHeading(output), nil
 }
 
 type ExtractContentOptions struct {
@@ -133,3 +180,20 @@ func ExtractContentForVersion(ctx context.Context, fileContent io.Reader, versio
 	}
 	return output.String(), false, nil
 }
+
+// StripHeading removes the first non-empty line of the rendered changelog if
+// it is a first-level heading, just like ExtractContentForVersion does with
+// RemoveHeadling set.
+func StripHeading(content string) string {
+	lines := strings.Split(content, "\n")
+	for idx, line := range lines {
+		if line == "" {
+			continue
+		}
+		if strings.HasPrefix(line, "# ") {
+			return strings.TrimSpace(strings.Join(lines[idx+1:], "\n"))
+		}
+		break
+	}
+	return strings.TrimSpace(content)
+}
@@ -3,6 +3,7 @@ package changelog
 import (
 	"bytes"
 	"context"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -77,3 +78,34 @@ func TestExtractContentForVersion(t *testing.T) {
 		})
 	}
 }
+
+func TestStripHeading(t *testing.T) {
+	require.Equal(t, "content", StripHeading("\n# 1.2.3 (2023-07-08)\n\ncontent\n"))
+	require.Equal(t, "### Section\n\ncontent", StripHeading("### Section\n\ncontent"))
+}
+
+func TestLoadOrBuild(t *testing.T) {
+	ctx := context.Background()
+	fixture := newTestFixture()
+	fixture.Changelogs[changelogKey("grafana", "grafana", "10.1.1")] = "### Bug fixes\n\n- Existing entry"
+	ds := NewFixtureDataSource(fixture)
+
+	t.Run("default-format-loads-changelog", func(t *testing.T) {
+		output, err := LoadOrBuild(ctx, nil, ds, "grafana", "grafana", "10.1.1", FormatDefault)
+		require.NoError(t, err)
+		require.Equal(t, "### Bug fixes\n\n- Existing entry", output)
+
+		_, err = LoadOrBuild(ctx, nil, ds, "grafana", "grafana", "10.1.2", "")
+		require.ErrorAs(t, err, &NoChangelogFound{})
+	})
+	t.Run("other-format-builds-changelog", func(t *testing.T) {
+		output, err := LoadOrBuild(ctx, nil, ds, "grafana", "grafana", "10.1.1", FormatKeepAChangelog)
+		require.NoError(t, err)
+		require.True(t, strings.HasPrefix(output, "### Fixed"), output)
+		require.Contains(t, output, "**Alerting:** Fix something.")
+	})
+	t.Run("unsupported-format", func(t *testing.T) {
+		_, err := LoadOrBuild(ctx, nil, ds, "grafana", "grafana", "10.1.1", "unknown")
+		require.Error(t, err)
+	})
+}
@@ -0,0 +1,143 @@
+package changelog
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/grafana/grafana-github-actions-go/pkg/ghgql"
+)
+
+const (
+	// SortByNumber lists the entries with the most recent pull requests
+	// first. Enterprise entries follow the OSS ones.
+	SortByNumber = "number"
+	// SortByArea lists the entries alphabetically by the area prefix of
+	// their title. Entries without an area come last.
+	SortByArea = "area"
+	// SortByMergeDate lists the most recently merged pull requests first.
+	SortByMergeDate = "merged"
+)
+
+// areaFallbackTitle is the heading for entries without area when grouping
+// by area.
+const areaFallbackTitle = "Other"
+
+// WithSortOrder sorts the entries within every section. Use
+// SortRendererOptions for validating user input.
+func WithSortOrder(order string) RendererOption {
+	return func(o *rendererOptions) {
+		o.sortOrder = order
+	}
+}
+
+// WithGroupByArea groups the entries of every section under sub-headings
+// derived from the area prefix of their title (e.g. `Alerting:`).
+func WithGroupByArea() RendererOption {
+	return func(o *rendererOptions) {
+		o.groupByArea = true
+	}
+}
+
+// SortRendererOptions returns the renderer options for the given sort order
+// and grouping. With an empty order the entries are kept in the order they
+// were provided in by the data source.
+func SortRendererOptions(order string, groupByArea bool) ([]RendererOption, error) {
+	result := make([]RendererOption, 0, 2)
+	switch order {
+	case "":
+	case SortByNumber, SortByArea, SortByMergeDate:
+		result = append(result, WithSortOrder(order))
+	default:
+		return nil, fmt.Errorf("unsupported sort order: %s", order)
+	}
+	if groupByArea {
+		result = append(result, WithGroupByArea())
+	}
+	return result, nil
+}
+
+// entryArea returns the area prefix of the changelog entry title without the
+// trailing colon or an empty string if there is none.
+func entryArea(issue ghgql.PullRequest) string {
+	match := titleHeadlinePattern.FindString(stripReleaseStreamPrefix(entryTitle(issue)))
+	return strings.TrimSpace(strings.TrimSuffix(match, ":"))
+}
+
+// sortIssues returns a sorted copy of the given pull requests. An empty order
+// keeps the original order.
+func sortIssues(issues []ghgql.PullRequest, order string) []ghgql.PullRequest {
+	result := make([]ghgql.PullRequest, len(issues))
+	copy(result, issues)
+	if order == "" {
+		return result
+	}
+	byNumber := func(a ghgql.PullRequest, b ghgql.PullRequest) bool {
+		if isEnterprisePR(a) != isEnterprisePR(b) {
+			return !isEnterprisePR(a)
+		}
+		return a.GetNumber() > b.GetNumber()
+	}
+	switch order {
+	case SortByArea:
+		sort.SliceStable(result, func(i, j int) bool {
+			a := strings.ToLower(entryArea(result[i]))
+			b := strings.ToLower(entryArea(result[j]))
+			if a != b {
+				// Entries without area are listed last:
+				if a == "" || b == "" {
+					return b == ""
+				}
+				return a < b
+			}
+			return byNumber(result[i], result[j])
+		})
+	case SortByMergeDate:
+		sort.SliceStable(result, func(i, j int) bool {
+			a := result[i].GetMergedAt()
+			b := result[j].GetMergedAt()
+			if !a.Equal(b) {
+				return a.After(b)
+			}
+			return byNumber(result[i], result[j])
+		})
+	case SortByNumber:
+		sort.SliceStable(result, func(i, j int) bool {
+			return byNumber(result[i], result[j])
+		})
+	}
+	return result
+}
+
+type areaGroup struct {
+	Area   string
+	Issues []ghgql.PullRequest
+}
+
+// groupIssuesByArea groups the pull requests by their area while keeping
+// their order within each group. Groups are ordered alphabetically with
+// entries without area last.
+func groupIssuesByArea(issues []ghgql.PullRequest) []areaGroup {
+	index := make(map[string]int)
+	result := make([]areaGroup, 0, 10)
+	for _, issue := range issues {
+		area := entryArea(issue)
+		key := strings.ToLower(area)
+		idx, ok := index[key]
+		if !ok {
+			idx = len(result)
+			index[key] = idx
+			result = append(result, areaGroup{Area: area})
+		}
+		result[idx].Issues = append(result[idx].Issues, issue)
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		a := strings.ToLower(result[i].Area)
+		b := strings.ToLower(result[j].Area)
+		if a == "" || b == "" {
+			return b == "" && a != ""
+		}
+		return a < b
+	})
+	return result
+}
@@ -0,0 +1,97 @@
+package changelog
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-github-actions-go/pkg/ghgql"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSortIssues(t *testing.T) {
+	now := time.Now()
+	issue := func(number int, title string, mergedAgo time.Duration) ghgql.PullRequest {
+		mergedAt := now.Add(-mergedAgo)
+		return ghgql.PullRequest{
+			Number:   pointerOf(number),
+			Title:    pointerOf(title),
+			MergedAt: &mergedAt,
+		}
+	}
+	issues := []ghgql.PullRequest{
+		issue(2, "Dashboards: Fix panel", time.Hour),
+		issue(3, "Fix something", 3*time.Hour),
+		issue(1, "alerting: Fix rule", 2*time.Hour),
+		issue(4, "Alerting: Add rule", 4*time.Hour),
+	}
+	numbers := func(issues []ghgql.PullRequest) []int {
+		result := make([]int, 0, len(issues))
+		for _, i := range issues {
+			result = append(result, i.GetNumber())
+		}
+		return result
+	}
+	require.Equal(t, []int{2, 3, 1, 4}, numbers(sortIssues(issues, "")))
+	require.Equal(t, []int{4, 3, 2, 1}, numbers(sortIssues(issues, SortByNumber)))
+	require.Equal(t, []int{4, 1, 2, 3}, numbers(sortIssues(issues, SortByArea)))
+	require.Equal(t, []int{2, 1, 3, 4}, numbers(sortIssues(issues, SortByMergeDate)))
+	// The input is not modified:
+	require.Equal(t, []int{2, 3, 1, 4}, numbers(issues))
+}
+
+func TestSortRendererOptions(t *testing.T) {
+	opts, err := SortRendererOptions("", false)
+	require.NoError(t, err)
+	require.Empty(t, opts)
+
+	opts, err = SortRendererOptions(SortByArea, true)
+	require.NoError(t, err)
+	require.Len(t, opts, 2)
+
+	_, err = SortRendererOptions("random", false)
+	require.Error(t, err)
+}
+
+func TestRenderGroupByArea(t *testing.T) {
+	body := newChangelogBody()
+	body.Version = "1.0.0"
+	body.Features = []ghgql.PullRequest{
+		{Number: pointerOf(3), Title: pointerOf("Dashboards: Add panel")},
+		{Number: pointerOf(2), Title: pointerOf("Add something")},
+		{Number: pointerOf(1), Title: pointerOf("Alerting: Add rule")},
+	}
+	body.Bugfixes = []ghgql.PullRequest{
+		{Number: pointerOf(4), Title: pointerOf("Fix something")},
+	}
+	output, err := NewRenderer(nil, WithGroupByArea()).Render(context.Background(), body)
+	require.NoError(t, err)
+	require.Equal(t, `# 1.0.0
+
+### Features and enhancements
+
+#### Alerting
+
+- **Alerting:** Add rule. [#1](https://github.com/grafana/grafana/issues/1)
+
+#### Dashboards
+
+- **Dashboards:** Add panel. [#3](https://github.com/grafana/grafana/issues/3)
+
+#### Other
+
+- Add something. [#2](https://github.com/grafana/grafana/issues/2)
+
+### Bug fixes
+
+- Fix something. [#4](https://github.com/grafana/grafana/issues/4)
+
+`, output)
+
+	// The area sub-headings don't affect parsing the changelog again:
+	sections, err := NewParser().Parse(context.Background(), strings.NewReader(output))
+	require.NoError(t, err)
+	require.Len(t, sections, 2)
+	require.Len(t, sections[0].Entries, 3)
+}
@@ -0,0 +1,195 @@
+package changelog
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/grafana/grafana-github-actions-go/pkg/ghgql"
+)
+
+// entryOverrideHeading is the heading inside the body of a pull request that
+// introduces overrides for its changelog entry. Alternatively, a fenced code
+// block with the info string "changelog" can be used:
+//
+//	```changelog
+//	title: Alerting: Improve the wording of an entry
+//	section: bugfix
+//	security: true
+//	exclude: false
+//	```
+//
+// The keys are only read from inside a code block. A plain line following the
+// heading is used as title, even if it starts with one of the keys (e.g.
+// `Security: Fix XSS`).
+const entryOverrideHeading = "Changelog entry"
+
+const entryOverrideFence = "```changelog"
+
+const (
+	sectionFeatures  = "features"
+	sectionBugfixes  = "bugfixes"
+	sectionPluginDev = "plugindev"
+	sectionSecurity  = "security"
+)
+
+// entryOverrideSections maps the values accepted for the section key to the
+// section of the changelog body.
+var entryOverrideSections = map[string]string{
+	"feature":            sectionFeatures,
+	"features":           sectionFeatures,
+	"enhancement":        sectionFeatures,
+	"bugfix":             sectionBugfixes,
+	"bugfixes":           sectionBugfixes,
+	"fix":                sectionBugfixes,
+	"plugin":             sectionPluginDev,
+	"plugindev":          sectionPluginDev,
+	"plugin-development": sectionPluginDev,
+	"security":           sectionSecurity,
+}
+
+// entryOverride contains the changes to the changelog entry of a pull request
+// requested in its body.
+type entryOverride struct {
+	Title    string
+	Section  string
+	Security bool
+	Exclude  bool
+}
+
+func getEntryOverride(issue ghgql.PullRequest) entryOverride {
+	override, _ := parseEntryOverride(issue.GetBody())
+	return override
+}
+
+// parseEntryOverride extracts the overrides from the given pull request body.
+// Problems with the provided values are returned so that they can be
+// reported by the linter, but they don't prevent the other values from being
+// used.
+func parseEntryOverride(body string) (entryOverride, []string) {
+	result := entryOverride{}
+	problems := make([]string, 0, 2)
+	lines := getEntryOverrideLines(body)
+	if len(lines.Title) > 0 {
+		// Titles often start with an area like `Security:` and so plain
+		// lines are never interpreted as keys:
+		result.Title = lines.Title[0]
+		if len(lines.Title) > 1 {
+			problems = append(problems, "The changelog entry override contains more than one line outside of a code block; only the first one is used as title.")
+		}
+	}
+	for _, line := range lines.Values {
+		key, value, found := strings.Cut(line, ":")
+		if !found {
+			problems = append(problems, fmt.Sprintf("The line `%s` of the changelog entry override has no key.", line))
+			continue
+		}
+		key = strings.ToLower(strings.TrimSpace(key))
+		value = strings.TrimSpace(value)
+		switch key {
+		case "title":
+			result.Title = value
+		case "section":
+			section, ok := entryOverrideSections[strings.ToLower(value)]
+			if !ok {
+				problems = append(problems, fmt.Sprintf("The changelog section `%s` is not supported.", value))
+				continue
+			}
+			result.Section = section
+		case "security":
+			result.Security = isTruthy(value)
+		case "exclude":
+			result.Exclude = isTruthy(value)
+		default:
+			problems = append(problems, fmt.Sprintf("The changelog entry override `%s` is not supported.", key))
+		}
+	}
+	if result.Section == sectionSecurity {
+		result.Security = true
+	}
+	return result, problems
+}
+
+// entryOverrideLines contains the non-empty lines of a changelog entry
+// override.
+type entryOverrideLines struct {
+	// Values are the `key: value` lines from inside a code block.
+	Values []string
+	// Title are the plain lines following the "Changelog entry" heading.
+	Title []string
+}
+
+// getEntryOverrideLines returns the non-empty lines of either the changelog
+// fenced code block or the section following the "Changelog entry" heading.
+// Like getNotice, the heading is detected by its text and the section ends at
+// the next heading. Only lines inside a code block are treated as `key: value`
+// pairs.
+func getEntryOverrideLines(body string) entryOverrideLines {
+	result := entryOverrideLines{}
+	inFence := false
+	inSection := false
+	inSectionBlock := false
+	for _, line := range strings.Split(body, "\n") {
+		l := strings.TrimSpace(line)
+		switch {
+		case inFence:
+			if l == "```" {
+				return result
+			}
+		case l == entryOverrideFence:
+			inFence = true
+			result = entryOverrideLines{}
+			continue
+		case inSection:
+			if strings.HasPrefix(l, "```") {
+				inSectionBlock = !inSectionBlock
+				continue
+			}
+			if inSectionBlock {
+				break
+			}
+			if strings.HasPrefix(l, "#") {
+				return result
+			}
+			if strings.HasPrefix(l, "<!--") {
+				// The section may contain instructions from the pull
+				// request template.
+				continue
+			}
+			if l != "" {
+				result.Title = append(result.Title, l)
+			}
+			continue
+		case strings.HasPrefix(l, "#") && strings.Contains(l, entryOverrideHeading):
+			inSection = true
+			continue
+		default:
+			continue
+		}
+		if l != "" {
+			result.Values = append(result.Values, l)
+		}
+	}
+	if inFence {
+		// Unterminated code blocks are ignored:
+		return entryOverrideLines{}
+	}
+	return result
+}
+
+func isTruthy(value string) bool {
+	switch strings.ToLower(value) {
+	case "1", "true", "yes", "y":
+		return true
+	default:
+		return false
+	}
+}
+
+// entryTitle returns the title that should be used for the changelog entry of
+// the given pull request.
+func entryTitle(issue ghgql.PullRequest) string {
+	if override := getEntryOverride(issue); override.Title != "" {
+		return override.Title
+	}
+	return issue.GetTitle()
+}
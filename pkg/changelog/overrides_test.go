@@ -0,0 +1,122 @@
+package changelog
+
+import (
+	"testing"
+
+	"github.com/grafana/grafana-github-actions-go/pkg/ghgql"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseEntryOverride(t *testing.T) {
+	tests := []struct {
+		name             string
+		body             string
+		expected         entryOverride
+		expectedProblems int
+	}{
+		{
+			name:     "no-override",
+			body:     "Some description\n\n**Special notes for your reviewer**:\n",
+			expected: entryOverride{},
+		},
+		{
+			name:     "heading-single-line",
+			body:     "Description\n\n### Changelog entry\n\nAlerting: Better title\n\n### Other heading\n\ntitle: ignored",
+			expected: entryOverride{Title: "Alerting: Better title"},
+		},
+		{
+			name:     "heading-title-starting-with-key",
+			body:     "### Changelog entry\n\nSecurity: Fix XSS in the panel editor\n",
+			expected: entryOverride{Title: "Security: Fix XSS in the panel editor"},
+		},
+		{
+			name:     "heading-title-starting-with-section-key",
+			body:     "### Changelog entry\n\nSection: Rename the section menu\n",
+			expected: entryOverride{Title: "Section: Rename the section menu"},
+		},
+		{
+			name:             "heading-multiple-lines",
+			body:             "### Changelog entry\n\nAlerting: Better title\nMore details\n",
+			expected:         entryOverride{Title: "Alerting: Better title"},
+			expectedProblems: 1,
+		},
+		{
+			name:     "heading-with-keys",
+			body:     "### Changelog entry\n\n<!-- Fill in to override the entry -->\n```\ntitle: Alerting: Better title\nsection: bugfix\n```\n",
+			expected: entryOverride{Title: "Alerting: Better title", Section: sectionBugfixes},
+		},
+		{
+			name:     "empty-heading",
+			body:     "### Changelog entry\n\n<!-- Fill in to override the entry -->\n\n### Other heading\n",
+			expected: entryOverride{},
+		},
+		{
+			name:     "fenced-block",
+			body:     "Description\n\n```changelog\nsection: security\n```\n\nMore text: with colon",
+			expected: entryOverride{Section: sectionSecurity, Security: true},
+		},
+		{
+			name:     "fenced-block-exclude",
+			body:     "```changelog\nexclude: yes\n```",
+			expected: entryOverride{Exclude: true},
+		},
+		{
+			name:             "unterminated-fenced-block",
+			body:             "```changelog\nexclude: yes\n",
+			expected:         entryOverride{},
+			expectedProblems: 0,
+		},
+		{
+			name:             "invalid-values",
+			body:             "```changelog\nsection: misc\ncolour: blue\ntitle: Kept\n```",
+			expected:         entryOverride{Title: "Kept"},
+			expectedProblems: 2,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			override, problems := parseEntryOverride(test.body)
+			require.Equal(t, test.expected, override)
+			require.Len(t, problems, test.expectedProblems)
+		})
+	}
+}
+
+func TestAddToBodyWithOverrides(t *testing.T) {
+	issue := func(title string, body string) ghgql.PullRequest {
+		return ghgql.PullRequest{
+			Number: pointerOf(1),
+			Title:  pointerOf(title),
+			Body:   pointerOf(body),
+		}
+	}
+
+	t.Run("excluded", func(t *testing.T) {
+		body := newChangelogBody()
+		addToBody(body, issue("Alerting: Fix something", "```changelog\nexclude: true\n```\n\n# Release notice breaking change\n\nBroken"))
+		require.Empty(t, body.Bugfixes)
+		require.Empty(t, body.BreakingChanges)
+	})
+
+	t.Run("forced-section", func(t *testing.T) {
+		body := newChangelogBody()
+		addToBody(body, issue("Alerting: Fix something", "```changelog\nsection: feature\n```"))
+		require.Empty(t, body.Bugfixes)
+		require.Len(t, body.Features, 1)
+	})
+
+	t.Run("security", func(t *testing.T) {
+		body := newChangelogBody()
+		addToBody(body, issue("Auth: Fix something", "```changelog\nsecurity: true\n```"))
+		require.Empty(t, body.Bugfixes)
+		require.Len(t, body.SecurityFixes, 1)
+	})
+
+	t.Run("title", func(t *testing.T) {
+		body := newChangelogBody()
+		pr := issue("[v10.0.x] Alerting: Fix typo in something", "### Changelog entry\n\nAlerting: Make something better")
+		addToBody(body, pr)
+		require.Len(t, body.Features, 1)
+		require.Equal(t, "Alerting: Make something better. ", PreparePRTitle(pr))
+	})
+}
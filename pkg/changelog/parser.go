@@ -4,19 +4,26 @@ import (
 	"bufio"
 	"context"
 	"io"
+	"regexp"
+	"strconv"
 	"strings"
 )
 
 type Entry struct {
 	Title string
+	// Numbers contains the pull request numbers linked from the entry. This
+	// is empty for entries without a link (e.g. Enterprise ones).
+	Numbers []int
 }
 
+var entryIssueLinkPattern = regexp.MustCompile(`\[#(\d+)\]\(`)
+
 type Section struct {
 	Title   string
 	Entries []Entry
 }
 
-var defaultIgnoredSections = []string{"breaking changes", "deprecations"}
+var defaultIgnoredSections = []string{"highlights", "breaking changes", "deprecations"}
 
 // Parser provides functionality to parse the entries of a single
 // version-changelog into a collection of sections. Note that the parsing
@@ -80,7 +87,8 @@ func (p *Parser) rawParse(ctx context.Context, content io.Reader) ([]Section, er
 			elems := strings.SplitN(strings.TrimPrefix(line, "- "), "[", 2)
 			title := elems[0]
 			currentSection.Entries = append(currentSection.Entries, Entry{
-				Title: strings.ReplaceAll(strings.TrimSpace(title), "*", ""),
+				Title:   strings.ReplaceAll(strings.TrimSpace(title), "*", ""),
+				Numbers: parseEntryNumbers(line),
 			})
 			continue
 		}
@@ -91,6 +99,18 @@ func (p *Parser) rawParse(ctx context.Context, content io.Reader) ([]Section, er
 	return result, nil
 }
 
+func parseEntryNumbers(line string) []int {
+	var result []int
+	for _, match := range entryIssueLinkPattern.FindAllStringSubmatch(line, -1) {
+		num, err := strconv.Atoi(match[1])
+		if err != nil {
+			continue
+		}
+		result = append(result, num)
+	}
+	return result
+}
+
 func (p *Parser) Parse(ctx context.Context, content io.Reader) ([]Section, error) {
 	return p.rawParse(ctx, content)
 }
@@ -51,5 +51,8 @@ func TestParse(t *testing.T) {
 		for idx, expected := range expectedEntries {
 			require.Equal(t, expected, entries[idx].Title)
 		}
+		require.Equal(t, []int{123}, entries[0].Numbers)
+		require.Equal(t, []int{124}, entries[1].Numbers)
+		require.Empty(t, entries[2].Numbers)
 	})
 }
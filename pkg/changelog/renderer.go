@@ -9,7 +9,6 @@ import (
 
 	"github.com/grafana/grafana-github-actions-go/pkg/ghgql"
 	"github.com/grafana/grafana-github-actions-go/pkg/toolkit"
-	"github.com/rs/zerolog"
 )
 
 // Renderer converts a changelog into a string that can then be posted e.g. to
@@ -18,28 +17,87 @@ type Renderer interface {
 	Render(context.Context, *ChangelogBody) (string, error)
 }
 
+const (
+	// FormatDefault is the Markdown format used by Grafana's changelog.
+	FormatDefault = "default"
+	// FormatKeepAChangelog follows the structure proposed by
+	// https://keepachangelog.com.
+	FormatKeepAChangelog = "keepachangelog"
+	// FormatConventional groups entries by the conventional-commit type
+	// prefix of the pull request titles.
+	FormatConventional = "conventional"
+)
+
+// RendererOption configures optional parts of the output of a renderer.
+type RendererOption func(*rendererOptions)
+
+type rendererOptions struct {
+	newContributorsSection bool
+	newContributorMarkers  bool
+	sortOrder              string
+	groupByArea            bool
+}
+
+// WithNewContributorsSection adds a section listing all the first-time
+// contributors of the release.
+func WithNewContributorsSection() RendererOption {
+	return func(o *rendererOptions) {
+		o.newContributorsSection = true
+	}
+}
+
+// WithNewContributorMarkers marks the entries that are the first
+// contribution of their author.
+func WithNewContributorMarkers() RendererOption {
+	return func(o *rendererOptions) {
+		o.newContributorMarkers = true
+	}
+}
+
 // NewRenderer returns a renderer that produces Markdown as used by Discourse
 // and the changelog.
-func NewRenderer(tk *toolkit.Toolkit) Renderer {
-	return &defaultRenderer{
+func NewRenderer(tk *toolkit.Toolkit, opts ...RendererOption) Renderer {
+	return newDefaultRenderer(tk, opts...)
+}
+
+// NewRendererForFormat returns a renderer for the given format. An empty
+// format is treated as FormatDefault.
+func NewRendererForFormat(tk *toolkit.Toolkit, format string, opts ...RendererOption) (Renderer, error) {
+	switch format {
+	case "", FormatDefault:
+		return NewRenderer(tk, opts...), nil
+	case FormatKeepAChangelog:
+		return &keepAChangelogRenderer{
+			base: newDefaultRenderer(tk, opts...),
+		}, nil
+	case FormatConventional:
+		return &conventionalRenderer{
+			base: newDefaultRenderer(tk, opts...),
+		}, nil
+	default:
+		return nil, fmt.Errorf("unsupported changelog format: %s", format)
+	}
+}
+
+func newDefaultRenderer(tk *toolkit.Toolkit, opts ...RendererOption) *defaultRenderer {
+	r := &defaultRenderer{
 		tk: tk,
 	}
+	for _, opt := range opts {
+		opt(&r.opts)
+	}
+	return r
 }
 
 type defaultRenderer struct {
-	tk *toolkit.Toolkit
+	tk   *toolkit.Toolkit
+	opts rendererOptions
 }
 
 func (r *defaultRenderer) Render(ctx context.Context, body *ChangelogBody) (string, error) {
 	out := strings.Builder{}
-	out.WriteString("# ")
-	out.WriteString(body.Version)
-	if body.ReleaseDate != "" {
-		out.WriteString(" (")
-		out.WriteString(body.ReleaseDate)
-		out.WriteString(")")
-	}
-	out.WriteString("\n\n")
+	writeHeading(&out, body)
+	r.writeHighlights(&out, body.Highlights)
 	if len(body.Features) > 0 {
 		out.WriteString("### Features and enhancements\n\n")
 		r.writeIssueLines(&out, body.Features)
@@ -50,31 +108,83 @@ func (r *defaultRenderer) Render(ctx context.Context, body *ChangelogBody) (stri
 		r.writeIssueLines(&out, body.Bugfixes)
 		out.WriteString("\n")
 	}
+	if len(body.SecurityFixes) > 0 {
+		out.WriteString("### Security fixes\n\n")
+		r.writeSecurityFixes(&out, body.SecurityFixes)
+		out.WriteString("\n")
+	}
 	if len(body.BreakingChanges) > 0 {
 		out.WriteString("### Breaking changes\n\n")
-		for _, notice := range body.BreakingChanges {
-			out.WriteString(notice)
-			out.WriteString("\n\n")
-		}
+		writeNotices(&out, body.BreakingChanges)
 	}
 	if len(body.DeprecationChanges) > 0 {
 		out.WriteString("### Deprecations\n\n")
-		for _, notice := range body.DeprecationChanges {
-			out.WriteString(notice)
-			out.WriteString("\n\n")
-		}
+		writeNotices(&out, body.DeprecationChanges)
 	}
 	if len(body.PluginDevChanges) > 0 {
 		out.WriteString("### Plugin development fixes & changes\n\n")
 		r.writeIssueLines(&out, body.PluginDevChanges)
 		out.WriteString("\n")
 	}
+	if len(body.DependencyUpdates) > 0 {
+		out.WriteString("### Dependency updates\n\n")
+		r.writeDependencyUpdates(&out, body.DependencyUpdates)
+		out.WriteString("\n")
+	}
+	r.writeNewContributors(&out, body.NewContributors)
 	return out.String(), nil
 }
 
 func (r *defaultRenderer) writeIssueLines(out *strings.Builder, issues []ghgql.PullRequest) {
-	for _, issue := range issues {
-		out.WriteString(r.issueAsMarkdown(issue))
+	issues = sortIssues(issues, r.opts.sortOrder)
+	if !r.opts.groupByArea {
+		for _, issue := range issues {
+			out.WriteString(r.issueAsMarkdown(issue))
+		}
+		return
+	}
+	groups := groupIssuesByArea(issues)
+	for idx, group := range groups {
+		if idx > 0 {
+			out.WriteString("\n")
+		}
+		// A single group without area doesn't need a heading:
+		if group.Area != "" || len(groups) > 1 {
+			out.WriteString("#### ")
+			if group.Area != "" {
+				out.WriteString(escapeMarkdown(group.Area))
+			} else {
+				out.WriteString(areaFallbackTitle)
+			}
+			out.WriteString("\n\n")
+		}
+		for _, issue := range group.Issues {
+			out.WriteString(r.issueAsMarkdown(issue))
+		}
+	}
+}
+
+func writeHeading(out *strings.Builder, body *ChangelogBody) {
+	out.WriteString("# ")
+	if body.Unreleased {
+		out.WriteString("Unreleased (")
+		out.WriteString(body.Version)
+		out.WriteString(")\n\n")
+		return
+	}
+	out.WriteString(body.Version)
+	if body.ReleaseDate != "" {
+		out.WriteString(" (")
+		out.WriteString(body.ReleaseDate)
+		out.WriteString(")")
+	}
+	out.WriteString("\n\n")
+}
+
+func writeNotices(out *strings.Builder, notices []string) {
+	for _, notice := range notices {
+		out.WriteString(notice)
+		out.WriteString("\n\n")
 	}
 }
 
@@ -90,13 +200,17 @@ func escapeMarkdown(s string) string {
 // will then be used for rendering it. Since the output of this function can be
 // used to match PRs from various releases it is public.
 func PreparePRTitle(issue ghgql.PullRequest) string {
+	return prepareTitle(stripReleaseStreamPrefix(entryTitle(issue)), issue)
+}
+
+// prepareTitle applies the formatting of PreparePRTitle to an arbitrary
+// title of the given pull request.
+func prepareTitle(title string, issue ghgql.PullRequest) string {
 	out := strings.Builder{}
-	title := issue.GetTitle()
-	title = stripReleaseStreamPrefix(title)
 	title = strings.TrimSuffix(title, ".")
 	title = escapeMarkdown(title)
 	out.WriteString(title)
-	if issueHasLabel(issue, LabelEnterprise) || issue.GetRepoName() == "grafana-enterprise" {
+	if isEnterprisePR(issue) {
 		out.WriteString(". (Enterprise)")
 	} else {
 		out.WriteString(". ")
@@ -105,26 +219,32 @@ func PreparePRTitle(issue ghgql.PullRequest) string {
 }
 
 func (r *defaultRenderer) issueAsMarkdown(issue ghgql.PullRequest) string {
-	ctx := context.Background()
-	out := strings.Builder{}
-
 	title := PreparePRTitle(issue)
 	title = titleHeadlinePattern.ReplaceAllString(title, "**$1**")
+	return r.issueLine(title, issue)
+}
+
+// issueLine renders a list item for the given pull request with an already
+// prepared title.
+func (r *defaultRenderer) issueLine(title string, issue ghgql.PullRequest) string {
+	out := strings.Builder{}
 
 	out.WriteString("- ")
 	out.WriteString(title)
-	if issueHasLabel(issue, LabelEnterprise) || issue.GetRepoName() == "grafana-enterprise" {
+	if isEnterprisePR(issue) {
 	} else {
 		out.WriteString(r.getIssueLink(issue))
-		if issue.GetAuthorLogin() != "" {
-			userLink, err := r.getUserLink(ctx, issue)
-			if err != nil {
-			} else {
-				out.WriteString(", ")
-				out.WriteString(userLink)
-			}
+		if userLinks := getUserLinks(issue); userLinks != "" {
+			out.WriteString(", ")
+			out.WriteString(userLinks)
 		}
 	}
+	if r.opts.newContributorMarkers && issue.FirstContribution {
+		out.WriteString(" (first contribution)")
+	}
+	if issue.IsOpen() {
+		out.WriteString(" (pending)")
+	}
 	out.WriteString("\n")
 	return out.String()
 }
@@ -159,7 +279,14 @@ func isBotUser(issue ghgql.PullRequest) bool {
 	if strings.HasPrefix(issue.GetAuthorResourcePath(), "/apps/") {
 		return true
 	}
-	switch issue.GetAuthorLogin() {
+	return isBotLogin(issue.GetAuthorLogin())
+}
+
+func isBotLogin(login string) bool {
+	if strings.HasSuffix(login, "[bot]") {
+		return true
+	}
+	switch login {
 	case "grafanabot":
 		return true
 	default:
@@ -167,42 +294,35 @@ func isBotUser(issue ghgql.PullRequest) bool {
 	}
 }
 
-func getPRNumberFromBackportBranch(ref string) (int, error) {
-	pat := regexp.MustCompile("^backport-(\\d+)-to-v\\d+\\.\\d+\\.x$")
-	match := pat.FindStringSubmatch(ref)
-	if len(match) < 1 {
-		return -1, fmt.Errorf("no number found in ref")
-	}
-	result, err := strconv.ParseInt(match[1], 10, 64)
-	return int(result), err
-
-}
-
-func (r *defaultRenderer) getUserLink(ctx context.Context, issue ghgql.PullRequest) (string, error) {
-	logger := zerolog.Ctx(ctx)
-	user := issue.GetAuthorLogin()
-	if isBotUser(issue) {
-		logger.Info().Msgf("PR#%d created by bot. Fetching original author from %s", issue.GetNumber(), issue.GetHeadRefName())
-		// If this looks like a bot user, take the author of the original PR if
-		// available:
-		origPrNumber, err := getPRNumberFromBackportBranch(issue.GetHeadRefName())
-		if err != nil {
-			return "", err
+// getUserLinks renders links to all the users who should be credited for the
+// given pull request. If the authors haven't been resolved using
+// resolveAuthors, the author of the pull request itself is used unless it is a
+// bot.
+func getUserLinks(issue ghgql.PullRequest) string {
+	users := issue.Authors
+	if len(users) == 0 {
+		if isBotUser(issue) || issue.GetAuthorLogin() == "" {
+			return ""
 		}
-		origPR, _, err := r.tk.GitHubClient().PullRequests.Get(context.Background(), issue.GetRepoOwner(), issue.GetRepoName(), origPrNumber)
-		if err != nil {
-			return "", err
-		}
-		user = origPR.User.GetLogin()
+		users = []string{issue.GetAuthorLogin()}
 	}
 	out := strings.Builder{}
-	out.WriteString("[@")
-	out.WriteString(user)
-	out.WriteString("]")
-	out.WriteString("(https://github.com/")
-	out.WriteString(user)
-	out.WriteString(")")
-	return out.String(), nil
+	for idx, user := range users {
+		if idx > 0 {
+			out.WriteString(", ")
+		}
+		out.WriteString("[@")
+		out.WriteString(user)
+		out.WriteString("]")
+		out.WriteString("(https://github.com/")
+		out.WriteString(user)
+		out.WriteString(")")
+	}
+	return out.String()
+}
+
+func isEnterprisePR(issue ghgql.PullRequest) bool {
+	return issueHasLabel(issue, LabelEnterprise) || issue.GetRepoName() == "grafana-enterprise"
 }
 
 func issueHasLabel(issue ghgql.PullRequest, label string) bool {
@@ -0,0 +1,148 @@
+package changelog
+
+import (
+	"context"
+	"regexp"
+	"strings"
+
+	"github.com/grafana/grafana-github-actions-go/pkg/ghgql"
+)
+
+// conventionalRenderer groups the entries of the changelog by the
+// conventional-commit type prefix (`type(scope): description`) of the pull
+// request titles. Pull requests without such a prefix fall back to the
+// section they were assigned to by the builder.
+type conventionalRenderer struct {
+	base *defaultRenderer
+}
+
+var conventionalTitlePattern = regexp.MustCompile(`^(?P<type>[a-zA-Z]+)(?:\((?P<scope>[^)]*)\))?(?P<breaking>!)?:\s*(?P<description>.+)$`)
+
+type conventionalGroup struct {
+	Title string
+	Types []string
+}
+
+// conventionalGroups defines the order and titles of the rendered sections.
+var conventionalGroups = []conventionalGroup{
+	{Title: "Features", Types: []string{"feat", "feature"}},
+	{Title: "Bug Fixes", Types: []string{"fix"}},
+	{Title: "Performance Improvements", Types: []string{"perf"}},
+	{Title: "Reverts", Types: []string{"revert"}},
+	{Title: "Documentation", Types: []string{"docs"}},
+	{Title: "Code Refactoring", Types: []string{"refactor"}},
+	{Title: "Miscellaneous Chores", Types: []string{"chore", "build", "ci", "test", "style", "deps"}},
+	{Title: "Plugin development", Types: nil},
+}
+
+type conventionalTitle struct {
+	Type        string
+	Scope       string
+	Breaking    bool
+	Description string
+}
+
+// parseConventionalTitle extracts the conventional-commit prefix from the
+// title. Only known types are accepted so that titles like `Alerting: Fix
+// something` are not mistaken for a conventional-commit title.
+func parseConventionalTitle(title string) (conventionalTitle, bool) {
+	match := conventionalTitlePattern.FindStringSubmatch(stripReleaseStreamPrefix(title))
+	if match == nil {
+		return conventionalTitle{}, false
+	}
+	typ := strings.ToLower(match[1])
+	if conventionalGroupTitle(typ) == "" {
+		return conventionalTitle{}, false
+	}
+	return conventionalTitle{
+		Type:        typ,
+		Scope:       match[2],
+		Breaking:    match[3] == "!",
+		Description: match[4],
+	}, true
+}
+
+func conventionalGroupTitle(typ string) string {
+	for _, group := range conventionalGroups {
+		for _, t := range group.Types {
+			if t == typ {
+				return group.Title
+			}
+		}
+	}
+	return ""
+}
+
+func (r *conventionalRenderer) Render(ctx context.Context, body *ChangelogBody) (string, error) {
+	out := strings.Builder{}
+	writeHeading(&out, body)
+	r.base.writeHighlights(&out, body.Highlights)
+
+	lines := make(map[string][]string)
+	breaking := make([]string, 0, 5)
+	add := func(fallbackGroup string, issues []ghgql.PullRequest) {
+		for _, issue := range sortIssues(issues, r.base.opts.sortOrder) {
+			ct, ok := parseConventionalTitle(entryTitle(issue))
+			if !ok {
+				lines[fallbackGroup] = append(lines[fallbackGroup], r.base.issueAsMarkdown(issue))
+				continue
+			}
+			line := r.issueAsMarkdown(ct, issue)
+			group := conventionalGroupTitle(ct.Type)
+			lines[group] = append(lines[group], line)
+			if ct.Breaking {
+				breaking = append(breaking, line)
+			}
+		}
+	}
+	add("Features", body.Features)
+	add("Bug Fixes", body.Bugfixes)
+	add("Plugin development", body.PluginDevChanges)
+
+	for _, group := range conventionalGroups {
+		if len(lines[group.Title]) == 0 {
+			continue
+		}
+		out.WriteString("### ")
+		out.WriteString(group.Title)
+		out.WriteString("\n\n")
+		for _, line := range lines[group.Title] {
+			out.WriteString(line)
+		}
+		out.WriteString("\n")
+	}
+	if len(body.SecurityFixes) > 0 {
+		out.WriteString("### Security fixes\n\n")
+		r.base.writeSecurityFixes(&out, body.SecurityFixes)
+		out.WriteString("\n")
+	}
+	if len(body.DependencyUpdates) > 0 {
+		out.WriteString("### Dependency updates\n\n")
+		r.base.writeDependencyUpdates(&out, body.DependencyUpdates)
+		out.WriteString("\n")
+	}
+	if len(breaking) > 0 || len(body.BreakingChanges) > 0 {
+		out.WriteString("### Breaking changes\n\n")
+		for _, line := range breaking {
+			out.WriteString(line)
+		}
+		if len(breaking) > 0 {
+			out.WriteString("\n")
+		}
+		writeNotices(&out, body.BreakingChanges)
+	}
+	if len(body.DeprecationChanges) > 0 {
+		out.WriteString("### Deprecations\n\n")
+		writeNotices(&out, body.DeprecationChanges)
+	}
+	r.base.writeNewContributors(&out, body.NewContributors)
+	return out.String(), nil
+}
+
+func (r *conventionalRenderer) issueAsMarkdown(ct conventionalTitle, issue ghgql.PullRequest) string {
+	title := prepareTitle(ct.Description, issue)
+	if ct.Scope != "" {
+		title = "**" + escapeMarkdown(ct.Scope) + ":** " + title
+	}
+	return r.base.issueLine(title, issue)
+}
@@ -0,0 +1,81 @@
+package changelog
+
+import (
+	"context"
+	"testing"
+
+	"github.com/grafana/grafana-github-actions-go/pkg/ghgql"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseConventionalTitle(t *testing.T) {
+	tests := []struct {
+		title       string
+		expectFound bool
+		expected    conventionalTitle
+	}{
+		{
+			title:       "feat(alerting): add something",
+			expectFound: true,
+			expected:    conventionalTitle{Type: "feat", Scope: "alerting", Description: "add something"},
+		},
+		{
+			title:       "[v10.0.x] fix!: break something",
+			expectFound: true,
+			expected:    conventionalTitle{Type: "fix", Breaking: true, Description: "break something"},
+		},
+		{
+			title:       "Alerting: Fix something",
+			expectFound: false,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.title, func(t *testing.T) {
+			ct, found := parseConventionalTitle(test.title)
+			require.Equal(t, test.expectFound, found)
+			if test.expectFound {
+				require.Equal(t, test.expected, ct)
+			}
+		})
+	}
+}
+
+func TestConventionalRenderer(t *testing.T) {
+	ctx := context.Background()
+	r, err := NewRendererForFormat(nil, FormatConventional)
+	require.NoError(t, err)
+
+	body := newChangelogBody()
+	body.Version = "1.2.3"
+	body.Features = []ghgql.PullRequest{
+		{Number: pointerOf(1), Title: pointerOf("feat(area): add something")},
+		{Number: pointerOf(2), Title: pointerOf("chore(deps)!: update something")},
+		{Number: pointerOf(3), Title: pointerOf("Area: Something without prefix")},
+	}
+	body.Bugfixes = []ghgql.PullRequest{
+		{Number: pointerOf(4), Title: pointerOf("fix: something")},
+	}
+
+	output, err := r.Render(ctx, body)
+	require.NoError(t, err)
+	require.Equal(t, `# 1.2.3
+
+### Features
+
+- **area:** add something. [#1](https://github.com/grafana/grafana/issues/1)
+- **Area:** Something without prefix. [#3](https://github.com/grafana/grafana/issues/3)
+
+### Bug Fixes
+
+- something. [#4](https://github.com/grafana/grafana/issues/4)
+
+### Miscellaneous Chores
+
+- **deps:** update something. [#2](https://github.com/grafana/grafana/issues/2)
+
+### Breaking changes
+
+- **deps:** update something. [#2](https://github.com/grafana/grafana/issues/2)
+
+`, output)
+}
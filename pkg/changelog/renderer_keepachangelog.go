@@ -0,0 +1,92 @@
+package changelog
+
+import (
+	"context"
+	"regexp"
+	"strings"
+
+	"github.com/grafana/grafana-github-actions-go/pkg/ghgql"
+)
+
+// keepAChangelogRenderer renders the changelog using the sections proposed by
+// https://keepachangelog.com. The heading is kept as first-level heading so
+// that the output can be used inside the changelog file just like the output
+// of the default renderer.
+type keepAChangelogRenderer struct {
+	base *defaultRenderer
+}
+
+// removalPattern matches pull request titles (without area prefix) that
+// describe the removal of a feature.
+var removalPattern = regexp.MustCompile(`(?i)^(remove|delete|drop)\b`)
+
+func (r *keepAChangelogRenderer) Render(ctx context.Context, body *ChangelogBody) (string, error) {
+	out := strings.Builder{}
+	out.WriteString("# [")
+	if body.Unreleased {
+		out.WriteString("Unreleased")
+	} else {
+		out.WriteString(body.Version)
+	}
+	out.WriteString("]")
+	if body.ReleaseDate != "" && !body.Unreleased {
+		out.WriteString(" - ")
+		out.WriteString(body.ReleaseDate)
+	}
+	out.WriteString("\n\n")
+	r.base.writeHighlights(&out, body.Highlights)
+
+	added := make([]ghgql.PullRequest, 0, len(body.Features))
+	removed := make([]ghgql.PullRequest, 0, 5)
+	for _, issue := range body.Features {
+		if isRemoval(issue) {
+			removed = append(removed, issue)
+		} else {
+			added = append(added, issue)
+		}
+	}
+
+	if len(added) > 0 {
+		out.WriteString("### Added\n\n")
+		r.base.writeIssueLines(&out, added)
+		out.WriteString("\n")
+	}
+	if len(body.BreakingChanges) > 0 || len(body.PluginDevChanges) > 0 || len(body.DependencyUpdates) > 0 {
+		out.WriteString("### Changed\n\n")
+		r.base.writeIssueLines(&out, body.PluginDevChanges)
+		r.base.writeDependencyUpdates(&out, body.DependencyUpdates)
+		if len(body.PluginDevChanges) > 0 || len(body.DependencyUpdates) > 0 {
+			out.WriteString("\n")
+		}
+		writeNotices(&out, body.BreakingChanges)
+	}
+	if len(body.DeprecationChanges) > 0 {
+		out.WriteString("### Deprecated\n\n")
+		writeNotices(&out, body.DeprecationChanges)
+	}
+	if len(removed) > 0 {
+		out.WriteString("### Removed\n\n")
+		r.base.writeIssueLines(&out, removed)
+		out.WriteString("\n")
+	}
+	if len(body.Bugfixes) > 0 {
+		out.WriteString("### Fixed\n\n")
+		r.base.writeIssueLines(&out, body.Bugfixes)
+		out.WriteString("\n")
+	}
+	if len(body.SecurityFixes) > 0 {
+		out.WriteString("### Security\n\n")
+		r.base.writeSecurityFixes(&out, body.SecurityFixes)
+		out.WriteString("\n")
+	}
+	r.base.writeNewContributors(&out, body.NewContributors)
+	return out.String(), nil
+}
+
+func isRemoval(issue ghgql.PullRequest) bool {
+	title := stripReleaseStreamPrefix(entryTitle(issue))
+	if match := titleHeadlinePattern.FindString(title); match != "" {
+		title = strings.TrimPrefix(title, match)
+	}
+	return removalPattern.MatchString(strings.TrimSpace(title))
+}
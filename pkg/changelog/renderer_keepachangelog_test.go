@@ -0,0 +1,57 @@
+package changelog
+
+import (
+	"context"
+	"testing"
+
+	"github.com/grafana/grafana-github-actions-go/pkg/ghgql"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKeepAChangelogRenderer(t *testing.T) {
+	ctx := context.Background()
+	r, err := NewRendererForFormat(nil, FormatKeepAChangelog)
+	require.NoError(t, err)
+
+	body := newChangelogBody()
+	body.Version = "1.2.3"
+	body.ReleaseDate = "2024-01-02"
+	body.Features = []ghgql.PullRequest{
+		{Number: pointerOf(1), Title: pointerOf("Area: Add something")},
+		{Number: pointerOf(2), Title: pointerOf("Area: Remove something old")},
+	}
+	body.Bugfixes = []ghgql.PullRequest{
+		{Number: pointerOf(3), Title: pointerOf("Area: Fix something")},
+	}
+	body.DeprecationChanges = []string{"Deprecated something."}
+
+	output, err := r.Render(ctx, body)
+	require.NoError(t, err)
+	require.Equal(t, `# [1.2.3] - 2024-01-02
+
+### Added
+
+- **Area:** Add something. [#1](https://github.com/grafana/grafana/issues/1)
+
+### Deprecated
+
+Deprecated something.
+
+### Removed
+
+- **Area:** Remove something old. [#2](https://github.com/grafana/grafana/issues/2)
+
+### Fixed
+
+- **Area:** Fix something. [#3](https://github.com/grafana/grafana/issues/3)
+
+`, output)
+}
+
+func TestNewRendererForFormat(t *testing.T) {
+	_, err := NewRendererForFormat(nil, "unknown")
+	require.Error(t, err)
+	r, err := NewRendererForFormat(nil, "")
+	require.NoError(t, err)
+	require.IsType(t, &defaultRenderer{}, r)
+}
@@ -1,6 +1,7 @@
 package changelog
 
 import (
+	"context"
 	"testing"
 
 	"github.com/grafana/grafana-github-actions-go/pkg/ghgql"
@@ -26,3 +27,14 @@ func TestIsBot(t *testing.T) {
 		require.False(t, isBotUser(issue))
 	})
 }
+
+func TestRenderUnreleased(t *testing.T) {
+	r := NewRenderer(nil)
+	body := newChangelogBody()
+	body.Version = "10.1.0"
+	body.ReleaseDate = "2023-01-01"
+	body.Unreleased = true
+	output, err := r.Render(context.Background(), body)
+	require.NoError(t, err)
+	require.Equal(t, "# Unreleased (10.1.0)\n\n", output)
+}
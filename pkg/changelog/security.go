@@ -0,0 +1,123 @@
+package changelog
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/grafana/grafana-github-actions-go/pkg/ghgql"
+)
+
+// LabelSecurity marks pull requests that fix a security issue.
+const LabelSecurity = "type/security"
+
+var cvePattern = regexp.MustCompile(`\bCVE-\d{4}-\d{4,}\b`)
+var ghsaPattern = regexp.MustCompile(`\bGHSA(?:-[23456789cfghjmpqrvwx]{4}){3}\b`)
+
+// SecurityAdvisory is a (published) security advisory of a repository as
+// returned by the GitHub REST API.
+type SecurityAdvisory struct {
+	GHSAID      string `json:"ghsa_id"`
+	CVEID       string `json:"cve_id"`
+	HTMLURL     string `json:"html_url"`
+	Summary     string `json:"summary"`
+	Description string `json:"description"`
+}
+
+// SecurityReference links a security fix to a CVE or advisory.
+type SecurityReference struct {
+	ID  string
+	URL string
+}
+
+// SecurityFix is a pull request listed in the "Security fixes" section
+// together with the advisories it is related to.
+type SecurityFix struct {
+	PullRequest ghgql.PullRequest
+	References  []SecurityReference
+}
+
+// isSecurityFix returns true if the pull request is labelled as security fix
+// or references a CVE or one of the advisories.
+func isSecurityFix(issue ghgql.PullRequest, advisories []SecurityAdvisory) bool {
+	if issueHasLabel(issue, LabelSecurity) {
+		return true
+	}
+	return len(getSecurityReferences(issue, advisories)) > 0
+}
+
+// getSecurityReferences collects all CVEs mentioned in the title or body of
+// the pull request and all advisories that either share an ID with the pull
+// request or link to it.
+func getSecurityReferences(issue ghgql.PullRequest, advisories []SecurityAdvisory) []SecurityReference {
+	text := issue.GetTitle() + "\n" + issue.GetBody()
+	ids := make(map[string]struct{})
+	for _, id := range cvePattern.FindAllString(text, -1) {
+		ids[id] = struct{}{}
+	}
+	for _, id := range ghsaPattern.FindAllString(text, -1) {
+		ids[id] = struct{}{}
+	}
+
+	result := make([]SecurityReference, 0, 2)
+	seen := make(map[string]struct{})
+	add := func(ref SecurityReference) {
+		if _, ok := seen[ref.ID]; ok {
+			return
+		}
+		seen[ref.ID] = struct{}{}
+		result = append(result, ref)
+	}
+	pullURL := fmt.Sprintf("github.com/%s/%s/pull/%d", issue.GetRepoOwner(), issue.GetRepoName(), issue.GetNumber())
+	for _, advisory := range advisories {
+		_, cveMatch := ids[advisory.CVEID]
+		_, ghsaMatch := ids[advisory.GHSAID]
+		linked := issue.GetRepoOwner() != "" && strings.Contains(advisory.Description, pullURL)
+		if (advisory.CVEID != "" && cveMatch) || (advisory.GHSAID != "" && ghsaMatch) || linked {
+			id := advisory.CVEID
+			if id == "" {
+				id = advisory.GHSAID
+			}
+			add(SecurityReference{ID: id, URL: advisory.HTMLURL})
+			if advisory.GHSAID != "" {
+				seen[advisory.GHSAID] = struct{}{}
+			}
+		}
+	}
+	for _, id := range cvePattern.FindAllString(text, -1) {
+		add(SecurityReference{ID: id, URL: "https://www.cve.org/CVERecord?id=" + id})
+	}
+	for _, id := range ghsaPattern.FindAllString(text, -1) {
+		add(SecurityReference{ID: id, URL: "https://github.com/advisories/" + id})
+	}
+	return result
+}
+
+func addSecurityFix(body *ChangelogBody, issue ghgql.PullRequest) {
+	body.SecurityFixes = append(body.SecurityFixes, SecurityFix{
+		PullRequest: issue,
+		References:  getSecurityReferences(issue, body.advisories),
+	})
+}
+
+func (r *defaultRenderer) writeSecurityFixes(out *strings.Builder, fixes []SecurityFix) {
+	for _, fix := range fixes {
+		line := strings.TrimSuffix(r.issueAsMarkdown(fix.PullRequest), "\n")
+		out.WriteString(line)
+		if len(fix.References) > 0 {
+			out.WriteString(" (")
+			for idx, ref := range fix.References {
+				if idx > 0 {
+					out.WriteString(", ")
+				}
+				out.WriteString("[")
+				out.WriteString(ref.ID)
+				out.WriteString("](")
+				out.WriteString(ref.URL)
+				out.WriteString(")")
+			}
+			out.WriteString(")")
+		}
+		out.WriteString("\n")
+	}
+}
@@ -0,0 +1,131 @@
+package changelog
+
+import (
+	"context"
+	"testing"
+
+	"github.com/grafana/grafana-github-actions-go/pkg/ghgql"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetSecurityReferences(t *testing.T) {
+	advisories := []SecurityAdvisory{
+		{
+			GHSAID:  "GHSA-abcd-efgh-ijkl",
+			CVEID:   "CVE-2024-1234",
+			HTMLURL: "https://github.com/grafana/grafana/security/advisories/GHSA-abcd-efgh-ijkl",
+		},
+		{
+			GHSAID:      "GHSA-mnop-qrst-uvwx",
+			HTMLURL:     "https://github.com/grafana/grafana/security/advisories/GHSA-mnop-qrst-uvwx",
+			Description: "Fixed in https://github.com/grafana/grafana/pull/456",
+		},
+	}
+	tests := []struct {
+		name     string
+		issue    ghgql.PullRequest
+		expected []SecurityReference
+	}{
+		{
+			name: "no-reference",
+			issue: ghgql.PullRequest{
+				Number: pointerOf(123),
+				Title:  pointerOf("Alerting: Fix something"),
+			},
+			expected: []SecurityReference{},
+		},
+		{
+			name: "cve-of-advisory",
+			issue: ghgql.PullRequest{
+				Number: pointerOf(123),
+				Title:  pointerOf("Auth: Fix CVE-2024-1234"),
+			},
+			expected: []SecurityReference{
+				{ID: "CVE-2024-1234", URL: "https://github.com/grafana/grafana/security/advisories/GHSA-abcd-efgh-ijkl"},
+			},
+		},
+		{
+			name: "unknown-cve",
+			issue: ghgql.PullRequest{
+				Number: pointerOf(123),
+				Title:  pointerOf("Auth: Fix something"),
+				Body:   pointerOf("This fixes CVE-2023-98765."),
+			},
+			expected: []SecurityReference{
+				{ID: "CVE-2023-98765", URL: "https://www.cve.org/CVERecord?id=CVE-2023-98765"},
+			},
+		},
+		{
+			name: "linked-by-advisory",
+			issue: ghgql.PullRequest{
+				Number:    pointerOf(456),
+				RepoOwner: pointerOf("grafana"),
+				RepoName:  pointerOf("grafana"),
+				Title:     pointerOf("Auth: Fix something"),
+			},
+			expected: []SecurityReference{
+				{ID: "GHSA-mnop-qrst-uvwx", URL: "https://github.com/grafana/grafana/security/advisories/GHSA-mnop-qrst-uvwx"},
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			require.Equal(t, test.expected, getSecurityReferences(test.issue, advisories))
+		})
+	}
+}
+
+func TestRenderSecurityFixes(t *testing.T) {
+	body := newChangelogBody()
+	body.Version = "10.4.1+security-01"
+	addToBody(body, ghgql.PullRequest{
+		Number: pointerOf(123),
+		Title:  pointerOf("Auth: Fix CVE-2024-1234"),
+	})
+	addToBody(body, ghgql.PullRequest{
+		Number: pointerOf(124),
+		Title:  pointerOf("Alerting: Escape labels"),
+		Labels: []string{LabelSecurity},
+	})
+	require.Len(t, body.SecurityFixes, 2)
+	require.Empty(t, body.Features)
+
+	output, err := NewRenderer(nil).Render(context.Background(), body)
+	require.NoError(t, err)
+	require.Equal(t, "# 10.4.1+security-01\n\n### Security fixes\n\n"+
+		"- **Auth:** Fix CVE-2024-1234. [#123](https://github.com/grafana/grafana/issues/123) ([CVE-2024-1234](https://www.cve.org/CVERecord?id=CVE-2024-1234))\n"+
+		"- **Alerting:** Escape labels. [#124](https://github.com/grafana/grafana/issues/124)\n\n", output)
+}
+
+func TestBuildUsesAdvisoriesOfPullRequestRepositories(t *testing.T) {
+	ctx := context.Background()
+	fixture := newTestFixture()
+	fixture.PullRequests["grafana/grafana#1"][0].Title = pointerOf("Auth: Fix CVE-2024-1234")
+	fixture.PullRequests["grafana/grafana-enterprise#2"] = []ghgql.PullRequest{
+		{
+			Number:      pointerOf(200),
+			Title:       pointerOf("Reporting: Fix CVE-2024-5678"),
+			RepoOwner:   pointerOf("grafana"),
+			RepoName:    pointerOf("grafana-enterprise"),
+			AuthorLogin: pointerOf("author"),
+		},
+	}
+	fixture.SecurityAdvisories["grafana/grafana"] = []SecurityAdvisory{
+		{GHSAID: "GHSA-aaaa-aaaa-aaaa", CVEID: "CVE-2024-1234", HTMLURL: "https://github.com/grafana/grafana/security/advisories/GHSA-aaaa-aaaa-aaaa"},
+	}
+	fixture.SecurityAdvisories["grafana/grafana-enterprise"] = []SecurityAdvisory{
+		{GHSAID: "GHSA-bbbb-bbbb-bbbb", CVEID: "CVE-2024-5678", HTMLURL: "https://github.com/grafana/grafana-enterprise/security/advisories/GHSA-bbbb-bbbb-bbbb"},
+	}
+	// Advisories of other repositories are never considered:
+	fixture.SecurityAdvisories["grafana/loki"] = []SecurityAdvisory{
+		{GHSAID: "GHSA-cccc-cccc-cccc", CVEID: "CVE-2024-1234", HTMLURL: "https://github.com/grafana/loki/security/advisories/GHSA-cccc-cccc-cccc"},
+	}
+
+	body, err := Build(ctx, "10.1.1", NewFixtureDataSource(fixture), nil)
+	require.NoError(t, err)
+	output, err := NewRenderer(nil).Render(ctx, body)
+	require.NoError(t, err)
+	require.Contains(t, output, "[CVE-2024-1234](https://github.com/grafana/grafana/security/advisories/GHSA-aaaa-aaaa-aaaa)")
+	require.Contains(t, output, "[CVE-2024-5678](https://github.com/grafana/grafana-enterprise/security/advisories/GHSA-bbbb-bbbb-bbbb)")
+	require.NotContains(t, output, "grafana/loki")
+}
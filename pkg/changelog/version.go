@@ -0,0 +1,164 @@
+package changelog
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/coreos/go-semver/semver"
+	"github.com/grafana/grafana-github-actions-go/pkg/versions"
+)
+
+// streamVersionPattern matches versions of a release stream like 10.4.x.
+var streamVersionPattern = regexp.MustCompile(`^(\d+)\.(\d+)\.x$`)
+
+// prereleaseRanks defines the order of the pre-release names used by Grafana
+// which doesn't match their lexical order.
+var prereleaseRanks = map[string]int{
+	"preview": 0,
+	"alpha":   1,
+	"beta":    2,
+	"rc":      3,
+}
+
+var prereleaseNamePattern = regexp.MustCompile(`^([a-zA-Z]+)[.-]?(\d*)$`)
+
+// changelogVersion is the version of an entry inside a changelog file. Next to
+// semantic versions, release streams (e.g. 10.4.x) are supported. A stream is
+// sorted above all releases within it.
+type changelogVersion struct {
+	raw    string
+	semver *semver.Version
+	stream bool
+}
+
+func parseChangelogVersion(raw string) (changelogVersion, error) {
+	v := strings.TrimPrefix(strings.TrimSpace(raw), "v")
+	if match := streamVersionPattern.FindStringSubmatch(v); match != nil {
+		major, _ := strconv.ParseInt(match[1], 10, 64)
+		minor, _ := strconv.ParseInt(match[2], 10, 64)
+		return changelogVersion{
+			raw:    raw,
+			semver: &semver.Version{Major: major, Minor: minor, Patch: math.MaxInt64},
+			stream: true,
+		}, nil
+	}
+	sv, err := semver.NewVersion(v)
+	if err != nil {
+		return changelogVersion{}, fmt.Errorf("invalid changelog version `%s`: %w", raw, err)
+	}
+	return changelogVersion{raw: raw, semver: sv}, nil
+}
+
+func (v changelogVersion) Major() int64 {
+	return v.semver.Major
+}
+
+// IsPrerelease returns true if the version has a pre-release component.
+func (v changelogVersion) IsPrerelease() bool {
+	return !v.stream && v.semver.PreRelease != ""
+}
+
+// IsPrereleaseOf returns true if v is a pre-release of the final release o.
+func (v changelogVersion) IsPrereleaseOf(o changelogVersion) bool {
+	if !v.IsPrerelease() || o.IsPrerelease() || o.stream {
+		return false
+	}
+	return v.semver.Major == o.semver.Major && v.semver.Minor == o.semver.Minor && v.semver.Patch == o.semver.Patch
+}
+
+// Compare returns -1, 0, or 1 depending on v being older, equal to, or newer
+// than o.
+func (v changelogVersion) Compare(o changelogVersion) int {
+	a := v.semver
+	b := o.semver
+	if c := compareInt(a.Major, b.Major); c != 0 {
+		return c
+	}
+	if c := compareInt(a.Minor, b.Minor); c != 0 {
+		return c
+	}
+	if v.stream || o.stream {
+		return compareBool(v.stream, o.stream)
+	}
+	if c := compareInt(a.Patch, b.Patch); c != 0 {
+		return c
+	}
+	if c := comparePrerelease(string(a.PreRelease), string(b.PreRelease)); c != 0 {
+		return c
+	}
+	return compareMetadata(a.Metadata, b.Metadata)
+}
+
+func (v changelogVersion) LessThan(o changelogVersion) bool {
+	return v.Compare(o) < 0
+}
+
+func comparePrerelease(a string, b string) int {
+	if a == b {
+		return 0
+	}
+	// Final releases are newer than any of their pre-releases:
+	if a == "" {
+		return 1
+	}
+	if b == "" {
+		return -1
+	}
+	aMatch := prereleaseNamePattern.FindStringSubmatch(a)
+	bMatch := prereleaseNamePattern.FindStringSubmatch(b)
+	if aMatch != nil && bMatch != nil {
+		aRank, aOK := prereleaseRanks[strings.ToLower(aMatch[1])]
+		bRank, bOK := prereleaseRanks[strings.ToLower(bMatch[1])]
+		if aOK && bOK {
+			if c := compareInt(int64(aRank), int64(bRank)); c != 0 {
+				return c
+			}
+			aNum, _ := strconv.ParseInt(aMatch[2], 10, 64)
+			bNum, _ := strconv.ParseInt(bMatch[2], 10, 64)
+			return compareInt(aNum, bNum)
+		}
+	}
+	av := semver.Version{PreRelease: semver.PreRelease(a)}
+	bv := semver.Version{PreRelease: semver.PreRelease(b)}
+	return av.Compare(bv)
+}
+
+// compareMetadata orders security releases (`+security-01`) above the release
+// they are based on and by their iteration. Other build metadata only has to
+// be different so that the versions are not treated as equal.
+func compareMetadata(a string, b string) int {
+	if a == b {
+		return 0
+	}
+	aIteration := versions.Version{Buildmeta: a}.SecurityIteration()
+	bIteration := versions.Version{Buildmeta: b}.SecurityIteration()
+	if c := compareInt(int64(aIteration), int64(bIteration)); c != 0 {
+		return c
+	}
+	return strings.Compare(a, b)
+}
+
+func compareInt(a int64, b int64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
+
+func compareBool(a bool, b bool) int {
+	switch {
+	case a == b:
+		return 0
+	case a:
+		return 1
+	default:
+		return -1
+	}
+}
@@ -0,0 +1,34 @@
+package changelog
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestChangelogVersionCompare(t *testing.T) {
+	ordered := []string{
+		"10.4.0",
+		"10.4.1",
+		"10.4.1+security-01",
+		"10.4.1+security-02",
+		"10.4.x",
+		"11.0.0-preview",
+		"11.0.0-beta1",
+		"11.0.0-beta2",
+		"11.0.0-beta10",
+		"11.0.0-rc1",
+		"11.0.0",
+	}
+	for i := 0; i < len(ordered)-1; i++ {
+		a, err := parseChangelogVersion(ordered[i])
+		require.NoError(t, err)
+		b, err := parseChangelogVersion(ordered[i+1])
+		require.NoError(t, err)
+		require.True(t, a.LessThan(b), "%s < %s", ordered[i], ordered[i+1])
+		require.False(t, b.LessThan(a), "%s > %s", ordered[i+1], ordered[i])
+	}
+
+	_, err := parseChangelogVersion("10.4")
+	require.Error(t, err)
+}
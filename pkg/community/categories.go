@@ -0,0 +1,87 @@
+package community
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+)
+
+// Category is a category as returned by `/c/<id>/show.json`.
+type Category struct {
+	ID               int    `json:"id"`
+	Name             string `json:"name"`
+	Slug             string `json:"slug"`
+	Description      string `json:"description"`
+	TopicCount       int    `json:"topic_count"`
+	ParentCategoryID int    `json:"parent_category_id"`
+}
+
+// CategoryTopic is a topic as listed by `/c/<slug>/<id>.json` and
+// `/tag/<tag>.json`.
+type CategoryTopic struct {
+	ID             int      `json:"id"`
+	Title          string   `json:"title"`
+	CategoryID     int      `json:"category_id"`
+	PostsCount     int      `json:"posts_count"`
+	Pinned         bool     `json:"pinned"`
+	PinnedGlobally bool     `json:"pinned_globally"`
+	Closed         bool     `json:"closed"`
+	Archived       bool     `json:"archived"`
+	Tags           []string `json:"tags"`
+}
+
+// TopicList is a single page of topics.
+type TopicList struct {
+	Topics []CategoryTopic `json:"topics"`
+	// MoreTopicsURL is only set if there is another page.
+	MoreTopicsURL string `json:"more_topics_url"`
+}
+
+// HasMore returns true if there is another page of topics.
+func (l *TopicList) HasMore() bool {
+	return l.MoreTopicsURL != ""
+}
+
+type topicListResponse struct {
+	TopicList TopicList `json:"topic_list"`
+}
+
+// GetCategory retrieves the category with the given ID.
+func (c *Community) GetCategory(ctx context.Context, id int) (*Category, error) {
+	result := struct {
+		Category Category `json:"category"`
+	}{}
+	if err := c.do(ctx, http.MethodGet, fmt.Sprintf("/c/%d/show.json", id), nil, nil, &result); err != nil {
+		return nil, err
+	}
+	if result.Category.ID == 0 {
+		return nil, fmt.Errorf("failed to decode category API data (no ID returned)")
+	}
+	return &result.Category, nil
+}
+
+// ListCategories returns all top-level categories visible to the user.
+func (c *Community) ListCategories(ctx context.Context) ([]Category, error) {
+	result := struct {
+		CategoryList struct {
+			Categories []Category `json:"categories"`
+		} `json:"category_list"`
+	}{}
+	if err := c.do(ctx, http.MethodGet, "/categories.json", nil, nil, &result); err != nil {
+		return nil, err
+	}
+	return result.CategoryList.Categories, nil
+}
+
+// ListCategoryTopics returns a page of the latest topics inside the category.
+func (c *Community) ListCategoryTopics(ctx context.Context, categoryID int, opts *ListOptions) (*TopicList, error) {
+	category, err := c.GetCategory(ctx, categoryID)
+	if err != nil {
+		return nil, err
+	}
+	result := topicListResponse{}
+	if err := c.do(ctx, http.MethodGet, fmt.Sprintf("/c/%s/%d.json", category.Slug, categoryID), opts.values(), nil, &result); err != nil {
+		return nil, err
+	}
+	return &result.TopicList, nil
+}
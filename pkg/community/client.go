@@ -0,0 +1,84 @@
+package community
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// ListOptions controls the paging of list endpoints. Discourse starts
+// counting pages at 0.
+type ListOptions struct {
+	Page int
+}
+
+func (o *ListOptions) values() url.Values {
+	values := url.Values{}
+	if o != nil && o.Page > 0 {
+		values.Set("page", strconv.Itoa(o.Page))
+	}
+	return values
+}
+
+// IsNotFound returns true if the error was caused by the API responding with
+// status code 404.
+func IsNotFound(err error) bool {
+	apiErr := &APIError{}
+	return errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound
+}
+
+// do sends the input encoded as JSON to the given path and decodes the
+// response into output. Both input and output may be nil. If the API
+// responds with anything but 200, an APIError is returned.
+func (c *Community) do(ctx context.Context, method string, path string, values url.Values, input any, output any) error {
+	var body io.Reader
+	if input != nil {
+		buf := bytes.Buffer{}
+		if err := json.NewEncoder(&buf).Encode(input); err != nil {
+			return err
+		}
+		body = &buf
+	}
+	req, err := c.buildRequest(ctx, method, path, values, body)
+	if err != nil {
+		return err
+	}
+	if input != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return newAPIError(resp)
+	}
+	if output == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(output)
+}
+
+func (c *Community) buildRequest(ctx context.Context, method string, path string, values url.Values, body io.Reader) (*http.Request, error) {
+	fullPath := strings.Builder{}
+	fullPath.WriteString(strings.TrimSuffix(c.baseURL, "/"))
+	fullPath.WriteString(path)
+	if len(values) > 0 {
+		fullPath.WriteString("?")
+		fullPath.WriteString(values.Encode())
+	}
+	req, err := http.NewRequestWithContext(ctx, method, fullPath.String(), body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Api-Username", c.username)
+	req.Header.Set("Api-Key", c.key)
+	return req, nil
+}
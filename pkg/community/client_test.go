@@ -0,0 +1,142 @@
+package community
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/grafana/grafana-github-actions-go/pkg/community/communitytest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("categories", func(t *testing.T) {
+		srv, comm := newTestCommunity(t, communitytest.Options{})
+		srv.AddCategory(communitytest.Category{ID: 5, Name: "Other category"})
+		for i := 1; i <= 5; i++ {
+			srv.AddTopic(communitytest.Topic{ID: i, CategoryID: testCategoryID, Title: fmt.Sprintf("Topic %d", i)})
+		}
+		categories, err := comm.ListCategories(ctx)
+		require.NoError(t, err)
+		require.Len(t, categories, 2)
+		require.Equal(t, "other-category", categories[1].Slug)
+
+		category, err := comm.GetCategory(ctx, testCategoryID)
+		require.NoError(t, err)
+		require.Equal(t, "hello", category.Slug)
+		require.Equal(t, 5, category.TopicCount)
+
+		_, err = comm.GetCategory(ctx, 123)
+		require.True(t, IsNotFound(err))
+	})
+
+	t.Run("category-topics-paging", func(t *testing.T) {
+		srv, comm := newTestCommunity(t, communitytest.Options{PageSize: 2})
+		for i := 1; i <= 5; i++ {
+			srv.AddTopic(communitytest.Topic{ID: i, CategoryID: testCategoryID, Title: fmt.Sprintf("Topic %d", i)})
+		}
+		ids := make([]int, 0, 5)
+		opts := &ListOptions{}
+		for {
+			list, err := comm.ListCategoryTopics(ctx, testCategoryID, opts)
+			require.NoError(t, err)
+			for _, topic := range list.Topics {
+				ids = append(ids, topic.ID)
+			}
+			if !list.HasMore() {
+				break
+			}
+			opts.Page++
+		}
+		// Latest topics come first:
+		require.Equal(t, []int{5, 4, 3, 2, 1}, ids)
+	})
+
+	t.Run("tags", func(t *testing.T) {
+		srv, comm := newTestCommunity(t, communitytest.Options{})
+		srv.AddTopic(communitytest.Topic{ID: 1, CategoryID: testCategoryID, Title: "Topic 1", Tags: []string{"release"}})
+		srv.AddTopic(communitytest.Topic{ID: 2, CategoryID: testCategoryID, Title: "Topic 2", Tags: []string{"release", "grafana-11"}})
+		tags, err := comm.ListTags(ctx)
+		require.NoError(t, err)
+		require.Equal(t, []Tag{{ID: "grafana-11", Text: "grafana-11", Count: 1}, {ID: "release", Text: "release", Count: 2}}, tags)
+
+		list, err := comm.ListTagTopics(ctx, "grafana-11", nil)
+		require.NoError(t, err)
+		require.Len(t, list.Topics, 1)
+		require.Equal(t, 2, list.Topics[0].ID)
+
+		require.NoError(t, comm.UpdateTopic(ctx, 1, TopicUpdate{Title: "Renamed", Tags: []string{"grafana-11"}}))
+		topic, err := comm.GetTopic(ctx, 1)
+		require.NoError(t, err)
+		require.Equal(t, "Renamed", topic.Title)
+		require.Equal(t, []string{"grafana-11"}, topic.Tags)
+	})
+
+	t.Run("search-paging", func(t *testing.T) {
+		srv, comm := newTestCommunity(t, communitytest.Options{})
+		for i := 1; i <= 60; i++ {
+			srv.AddTopic(communitytest.Topic{ID: i, CategoryID: testCategoryID, Title: fmt.Sprintf("Release %d", i)},
+				communitytest.Post{Username: "test", Raw: "hello"},
+			)
+		}
+		srv.AddTopic(communitytest.Topic{ID: 61, CategoryID: testCategoryID, Title: "Release 61"},
+			communitytest.Post{Username: "someone", Raw: "hello"},
+		)
+		result, err := comm.Search(ctx, "release @test #hello in:title", nil)
+		require.NoError(t, err)
+		require.Len(t, result.Posts, 50)
+		require.True(t, result.HasMore())
+		result, err = comm.Search(ctx, "release @test #hello in:title", &SearchOptions{Page: 2})
+		require.NoError(t, err)
+		require.Len(t, result.Posts, 10)
+		require.False(t, result.HasMore())
+		require.Equal(t, "test", result.Posts[0].Username)
+	})
+
+	t.Run("posts", func(t *testing.T) {
+		srv, comm := newTestCommunity(t, communitytest.Options{})
+		created, err := comm.CreateTopic(ctx, PostInput{Title: "Topic", Category: testCategoryID, Body: "first"})
+		require.NoError(t, err)
+		reply, err := comm.CreateReply(ctx, created.TopicID, "second")
+		require.NoError(t, err)
+		require.Equal(t, 2, reply.PostNumber)
+
+		require.NoError(t, comm.UpdatePost(ctx, reply.ID, PostUpdate{Raw: "updated", EditReason: "Typo"}))
+		post, err := comm.GetPost(ctx, reply.ID)
+		require.NoError(t, err)
+		require.Equal(t, "updated", post.Raw)
+		require.Equal(t, "test", post.Username)
+
+		require.NoError(t, comm.SetTopicStatus(ctx, created.TopicID, TopicStatusClosed, true))
+		topic, found := srv.Topic(created.TopicID)
+		require.True(t, found)
+		require.True(t, topic.Closed)
+
+		// Deleting the first post deletes the whole topic:
+		require.NoError(t, comm.DeletePost(ctx, created.ID))
+		_, err = comm.GetTopic(ctx, created.TopicID)
+		require.True(t, IsNotFound(err))
+	})
+
+	t.Run("post-too-long", func(t *testing.T) {
+		_, comm := newTestCommunity(t, communitytest.Options{PostSizeLimit: 3})
+		_, err := comm.CreateTopic(ctx, PostInput{Title: "Topic", Category: testCategoryID, Body: "hello"})
+		require.ErrorIs(t, err, ErrPostTooLong)
+		require.ErrorContains(t, err, "Body is limited to 3 characters; you entered 5.")
+	})
+
+	t.Run("invalid-credentials", func(t *testing.T) {
+		srv := communitytest.NewServer(communitytest.Options{APIUsername: "test", APIKey: "secret"})
+		defer srv.Close()
+		comm := New(CommunityWithBaseURL(srv.URL()), CommunityWithHTTPClient(srv.Client()), CommunityWithAPICredentials("test", "wrong"))
+		_, err := comm.ListTags(ctx)
+		apiErr := &APIError{}
+		require.True(t, errors.As(err, &apiErr))
+		require.Equal(t, http.StatusForbidden, apiErr.StatusCode)
+		require.Equal(t, "invalid_access", apiErr.ErrorType)
+	})
+}
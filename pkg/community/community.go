@@ -1,196 +1,222 @@
+// Package community provides a client for the Discourse API as used by
+// https://community.grafana.com/. Besides the generic endpoints for topics,
+// posts, categories, tags, and search, CreateOrUpdatePost takes care of
+// keeping a release topic up to date.
+//
+// An in-memory fake of the API for tests can be found in the communitytest
+// package.
 package community
 
 import (
-	"bytes"
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
-	"io"
 	"net/http"
-	"net/url"
-	"os"
-	"strings"
 
 	"github.com/rs/zerolog"
 )
 
+// Community is a client for the Discourse API. Failed requests are retried
+// according to the RetryPolicy and unexpected responses are returned as
+// APIError.
 type Community struct {
-	key        string
-	username   string
-	baseURL    string
-	httpClient *http.Client
+	key         string
+	username    string
+	baseURL     string
+	httpClient  *http.Client
+	retryPolicy RetryPolicy
 }
 
 func New(options ...CommunityOption) *Community {
 	c := &Community{
-		httpClient: &http.Client{},
+		httpClient:  &http.Client{},
+		retryPolicy: DefaultRetryPolicy,
 	}
 	for _, opt := range options {
 		opt(c)
 	}
+	// Wrap the transport of a copy so that a client passed in by the caller
+	// isn't modified:
+	client := *c.httpClient
+	client.Transport = newRetryTransport(client.Transport, c.retryPolicy)
+	c.httpClient = &client
 	return c
 }
 
+// editReason is shown in the history of posts updated by CreateOrUpdatePost.
+const editReason = "Changelog was updated"
+
 type PostInput struct {
 	Title    string `json:"title"`
 	Body     string `json:"raw"`
 	Category int    `json:"category"`
 	Author   string `json:"-"`
+	// Tags are set on the topic when it is created or updated.
+	Tags []string `json:"tags,omitempty"`
+	// ExternalID identifies the topic independent of its title. If set,
+	// existing topics are looked up using it before falling back to
+	// searching for the title.
+	ExternalID string `json:"external_id,omitempty"`
 }
 
 type PostOptions struct {
 	// FallbackBody is used for situations where the server returns a size-limit error.
 	FallbackBody string
+	// MaxPostLength enables splitting the body into a thread of posts with
+	// at most that many characters each. The topic is created with the
+	// first chunk and the remaining ones are added as replies. When the
+	// topic is updated, replies are updated, added, or deleted as needed.
+	// It has to be below the limit of the server (32000 by default);
+	// otherwise, the thread is replaced with the FallbackBody.
+	MaxPostLength int
+	// Pin pins the topic either inside its category (PinCategory) or
+	// globally (PinGlobal). Older related topics are unpinned.
+	Pin string
+	// RelatedTopicPrefix is the title prefix of topics in the same category
+	// that are considered other versions of the topic (e.g. the posts of
+	// previous releases). If the prefix is followed by a version, only topics
+	// with a lower version are affected. Otherwise, topics created before
+	// the current one are.
+	RelatedTopicPrefix string
+	// CloseRelatedTopics closes the older related topics.
+	CloseRelatedTopics bool
+	// ArchiveRelatedTopics archives the older related topics.
+	ArchiveRelatedTopics bool
 }
 
 // CreateOrUpdate tries to update an existing post with the provided title in
 // the specified category. If no such topic exists, a new topic with the same
-// content will be created.
+// content will be created. Afterwards, the topic is pinned and older related
+// topics are unpinned, closed, or archived as configured in the options.
 func (c *Community) CreateOrUpdatePost(ctx context.Context, post PostInput, postOpts *PostOptions) (int, error) {
 	if postOpts == nil {
 		postOpts = &PostOptions{}
 	}
-	logger := zerolog.Ctx(ctx)
-	category, err := c.getCategory(ctx, post.Category)
-	if err != nil {
-		return -1, fmt.Errorf("failed to retrieve category: %w", err)
-	}
-	searchQuery := fmt.Sprintf("%s @%s #%s in:title order:latest_topic", post.Title, post.Author, category.Category.Slug)
-	opts := SearchOptions{
-		Page: 1,
-	}
-	result, err := c.search(ctx, searchQuery, &opts)
-	if err != nil {
-		return -1, err
-	}
-	if len(result.Posts) > 0 {
-		// No post found, so let's create a new one
-		logger.Info().Msgf("Updating post %d", result.Posts[0].ID)
-		if err := c.updatePost(ctx, result.Posts[0].ID, post.Body); err != nil {
-			if err == errPostTooLong {
-				if err := c.updatePost(ctx, result.Posts[0].ID, postOpts.FallbackBody); err != nil {
-					return result.Posts[0].ID, err
-				}
-				return result.Posts[0].ID, nil
-			}
-			return result.Posts[0].ID, err
-		}
-		return result.Posts[0].ID, nil
-	}
-
-	topic, err := c.createTopic(ctx, post)
+	result, err := c.createOrUpdatePost(ctx, post, postOpts)
 	if err != nil {
-		if err == errPostTooLong {
-			post.Body = postOpts.FallbackBody
-			topic, err = c.createTopic(ctx, post)
-			if err != nil {
-				return -1, err
-			}
+		if result != nil {
+			return result.ID, err
 		}
 		return -1, err
 	}
-	return topic.ID, nil
+	if err := c.applyLifecycle(ctx, result.TopicID, post, postOpts); err != nil {
+		return result.ID, err
+	}
+	return result.ID, nil
 }
 
-var errPostTooLong = fmt.Errorf("post content is too long")
-
-func (c *Community) createTopic(ctx context.Context, post PostInput) (*Post, error) {
-	body := bytes.Buffer{}
-	result := Post{}
-	if err := json.NewEncoder(&body).Encode(post); err != nil {
-		return nil, err
-	}
-	req, err := c.buildRequest(ctx, http.MethodPost, "/posts.json", nil, &body)
-	if err != nil {
-		return nil, err
-	}
-	req.Header.Set("Content-Type", "application/json")
-	resp, err := c.httpClient.Do(req)
+// createOrUpdatePost returns the first post of the topic. If an error occurs
+// after the topic was found or created, the post is returned as well.
+func (c *Community) createOrUpdatePost(ctx context.Context, post PostInput, postOpts *PostOptions) (*Post, error) {
+	logger := zerolog.Ctx(ctx)
+	existing, err := c.findExistingPost(ctx, post)
 	if err != nil {
 		return nil, err
 	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		if resp.StatusCode == http.StatusUnprocessableEntity {
-			return nil, errPostTooLong
+	threaded := postOpts.MaxPostLength > 0
+	chunks := SplitPost(post.Body, postOpts.MaxPostLength)
+	if existing != nil && threaded {
+		id, err := c.syncThread(ctx, existing.TopicID, post.Author, chunks)
+		if errors.Is(err, ErrPostTooLong) {
+			// The server limit is below the configured maximum length:
+			logger.Warn().Msgf("Post exceeds the size limit of the server, using the fallback for topic %d", existing.TopicID)
+			id, err = c.syncThread(ctx, existing.TopicID, post.Author, []string{postOpts.FallbackBody})
 		}
-		io.Copy(os.Stderr, resp.Body)
-		return nil, fmt.Errorf("creating a new post failed with status code %d", resp.StatusCode)
-	}
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return nil, err
+		if id == -1 {
+			return nil, err
+		}
+		return &Post{ID: id, TopicID: existing.TopicID}, err
+	}
+	if existing != nil {
+		logger.Info().Msgf("Updating post %d", existing.ID)
+		if err := c.UpdatePost(ctx, existing.ID, PostUpdate{Raw: post.Body, EditReason: editReason}); err != nil {
+			if errors.Is(err, ErrPostTooLong) {
+				if err := c.UpdatePost(ctx, existing.ID, PostUpdate{Raw: postOpts.FallbackBody, EditReason: editReason}); err != nil {
+					return existing, err
+				}
+				return existing, nil
+			}
+			return existing, err
+		}
+		return existing, nil
 	}
-	return &result, nil
-}
 
-func (c *Community) updatePost(ctx context.Context, postID int, raw string) error {
-	body := bytes.Buffer{}
-	input := map[string]any{
-		"post": map[string]any{
-			"raw":         raw,
-			"edit_reason": "Changelog was updated",
-		},
-	}
-	if err := json.NewEncoder(&body).Encode(input); err != nil {
-		return err
-	}
-	req, err := c.buildRequest(ctx, http.MethodPut, fmt.Sprintf("/posts/%d.json", postID), nil, &body)
-	if err != nil {
-		return err
+	// No post found, so let's create a new one
+	if threaded {
+		post.Body = chunks[0]
 	}
-	req.Header.Set("Content-Type", "application/json")
-	resp, err := c.httpClient.Do(req)
+	topic, err := c.CreateTopic(ctx, post)
 	if err != nil {
-		return err
+		if !errors.Is(err, ErrPostTooLong) {
+			return nil, err
+		}
+		post.Body = postOpts.FallbackBody
+		topic, err = c.CreateTopic(ctx, post)
+		if err != nil {
+			return nil, err
+		}
+		return topic, nil
 	}
-	defer resp.Body.Close()
-	if resp.StatusCode != http.StatusOK {
-		if resp.StatusCode == http.StatusUnprocessableEntity {
-			return errPostTooLong
+	for _, chunk := range chunks[1:] {
+		logger.Info().Msgf("Adding reply to topic %d", topic.TopicID)
+		if _, err := c.CreateReply(ctx, topic.TopicID, chunk); err != nil {
+			if !errors.Is(err, ErrPostTooLong) {
+				return topic, err
+			}
+			logger.Warn().Msgf("Post exceeds the size limit of the server, using the fallback for topic %d", topic.TopicID)
+			if _, err := c.syncThread(ctx, topic.TopicID, post.Author, []string{postOpts.FallbackBody}); err != nil {
+				return topic, err
+			}
+			return topic, nil
 		}
-		io.Copy(os.Stderr, resp.Body)
-		return fmt.Errorf("updating existing post failed with status code %d", resp.StatusCode)
 	}
-	return nil
+	return topic, nil
 }
 
-func (c *Community) getCategory(ctx context.Context, id int) (*Category, error) {
-	result := Category{}
-	req, err := c.buildRequest(ctx, http.MethodGet, fmt.Sprintf("/c/%d/show.json", id), nil, nil)
-	if err != nil {
-		return nil, err
+// findExistingPost returns the first post of the topic matching the external
+// ID of the input. Topics created without external ID are looked up by
+// searching for posts of the author with exactly the same title inside the
+// category. If no topic is found, nil is returned.
+func (c *Community) findExistingPost(ctx context.Context, post PostInput) (*Post, error) {
+	logger := zerolog.Ctx(ctx)
+	if post.ExternalID != "" {
+		topic, err := c.GetTopicByExternalID(ctx, post.ExternalID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to look up topic by external ID: %w", err)
+		}
+		if topic != nil {
+			if first := topic.firstPost(); first != nil {
+				return &Post{ID: first.ID, TopicID: topic.ID}, nil
+			}
+		}
+		logger.Info().Msgf("No topic found with external ID `%s`, searching for the title", post.ExternalID)
 	}
-	resp, err := c.httpClient.Do(req)
+	category, err := c.GetCategory(ctx, post.Category)
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("failed to retrieve category: %w", err)
 	}
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
-	}
-	defer resp.Body.Close()
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return nil, err
-	}
-	if result.Category.ID == 0 {
-		return nil, fmt.Errorf("failed to decode category API data (no ID returned)")
+	searchQuery := fmt.Sprintf("%s @%s #%s in:title order:latest_topic", post.Title, post.Author, category.Slug)
+	opts := SearchOptions{
+		Page: 1,
 	}
-	return &result, nil
-}
-
-func (c *Community) buildRequest(ctx context.Context, method string, path string, values url.Values, body io.Reader) (*http.Request, error) {
-	fullPath := strings.Builder{}
-	fullPath.WriteString(c.baseURL)
-	fullPath.WriteString(path)
-	if len(values) > 0 {
-		fullPath.WriteString("?")
-		fullPath.WriteString(values.Encode())
-	}
-	req, err := http.NewRequestWithContext(ctx, method, fullPath.String(), body)
+	result, err := c.Search(ctx, searchQuery, &opts)
 	if err != nil {
 		return nil, err
 	}
-	req.Header.Set("Api-Username", c.username)
-	req.Header.Set("Api-Key", c.key)
-	return req, nil
+	// Search also matches similar titles (e.g. 11.2.10 for 11.2.1) and so
+	// the topics are checked for an exact match if they are provided:
+	for _, p := range result.Posts {
+		if len(result.Topics) == 0 || result.topicTitle(p.TopicID) == post.Title {
+			found := p
+			if post.ExternalID != "" {
+				// Later runs can then find the topic without searching:
+				logger.Info().Msgf("Setting external ID `%s` on topic %d", post.ExternalID, found.TopicID)
+				if err := c.UpdateTopic(ctx, found.TopicID, TopicUpdate{ExternalID: post.ExternalID}); err != nil {
+					logger.Warn().Err(err).Msgf("Failed to set external ID on topic %d", found.TopicID)
+				}
+			}
+			return &found, nil
+		}
+	}
+	return nil, nil
 }
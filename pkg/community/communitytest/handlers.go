@@ -0,0 +1,498 @@
+package communitytest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+func (s *Server) categoryJSON(c *Category) map[string]any {
+	count := 0
+	for _, t := range s.topics {
+		if t.CategoryID == c.ID {
+			count++
+		}
+	}
+	return map[string]any{
+		"id":          c.ID,
+		"name":        c.Name,
+		"slug":        c.Slug,
+		"topic_count": count,
+	}
+}
+
+func (s *Server) listedTopicJSON(t *Topic) map[string]any {
+	return map[string]any{
+		"id":              t.ID,
+		"title":           t.Title,
+		"category_id":     t.CategoryID,
+		"posts_count":     len(s.topicPosts(t.ID)),
+		"pinned":          t.Pinned,
+		"pinned_globally": t.PinnedGlobally,
+		"closed":          t.Closed,
+		"archived":        t.Archived,
+		"tags":            nonNil(t.Tags),
+	}
+}
+
+func topicPostJSON(p *Post) map[string]any {
+	return map[string]any{
+		"id":          p.ID,
+		"topic_id":    p.TopicID,
+		"post_number": p.PostNumber,
+		"username":    p.Username,
+		"cooked":      "<p>" + p.Raw + "</p>",
+	}
+}
+
+func postJSON(p *Post) map[string]any {
+	result := topicPostJSON(p)
+	result["raw"] = p.Raw
+	return result
+}
+
+func (s *Server) handleListCategories(w http.ResponseWriter, r *http.Request, params []string) {
+	categories := make([]map[string]any, 0, len(s.categories))
+	for _, id := range sortedKeys(s.categories) {
+		categories = append(categories, s.categoryJSON(s.categories[id]))
+	}
+	writeJSON(w, map[string]any{
+		"category_list": map[string]any{
+			"categories": categories,
+		},
+	})
+}
+
+func (s *Server) handleGetCategory(w http.ResponseWriter, r *http.Request, params []string) {
+	c, ok := s.categories[atoi(params[0])]
+	if !ok {
+		writeNotFound(w)
+		return
+	}
+	writeJSON(w, map[string]any{
+		"category": s.categoryJSON(c),
+	})
+}
+
+func (s *Server) handleListCategoryTopics(w http.ResponseWriter, r *http.Request, params []string) {
+	c, ok := s.categories[atoi(params[1])]
+	if !ok || c.Slug != params[0] {
+		writeNotFound(w)
+		return
+	}
+	s.writeTopicList(w, r, fmt.Sprintf("/c/%s/%d", c.Slug, c.ID), func(t *Topic) bool {
+		return t.CategoryID == c.ID
+	})
+}
+
+func (s *Server) handleListTags(w http.ResponseWriter, r *http.Request, params []string) {
+	counts := make(map[string]int)
+	for _, t := range s.topics {
+		for _, tag := range t.Tags {
+			counts[tag]++
+		}
+	}
+	tags := make([]map[string]any, 0, len(counts))
+	for _, tag := range sortedKeys(counts) {
+		tags = append(tags, map[string]any{
+			"id":    tag,
+			"text":  tag,
+			"count": counts[tag],
+		})
+	}
+	writeJSON(w, map[string]any{
+		"tags": tags,
+	})
+}
+
+func (s *Server) handleListTagTopics(w http.ResponseWriter, r *http.Request, params []string) {
+	tag, _ := url.PathUnescape(params[0])
+	s.writeTopicList(w, r, "/tag/"+params[0], func(t *Topic) bool {
+		return contains(t.Tags, tag)
+	})
+}
+
+// writeTopicList writes a page of the latest topics matching the filter.
+func (s *Server) writeTopicList(w http.ResponseWriter, r *http.Request, path string, filter func(t *Topic) bool) {
+	page := atoi(r.URL.Query().Get("page"))
+	topics := make([]map[string]any, 0, s.opts.PageSize)
+	matches := 0
+	for _, t := range s.sortedTopics(true) {
+		if !filter(t) {
+			continue
+		}
+		if matches >= page*s.opts.PageSize && matches < (page+1)*s.opts.PageSize {
+			topics = append(topics, s.listedTopicJSON(t))
+		}
+		matches++
+	}
+	list := map[string]any{
+		"topics": topics,
+	}
+	if matches > (page+1)*s.opts.PageSize {
+		list["more_topics_url"] = fmt.Sprintf("%s?page=%d", path, page+1)
+	}
+	writeJSON(w, map[string]any{
+		"topic_list": list,
+	})
+}
+
+// handleSearch supports the `@username`, `#category-slug`, `tags:a,b`,
+// `in:title`, and `order:` filters. All other terms have to be contained in
+// the title (or the posts if `in:title` is missing) of a topic.
+func (s *Server) handleSearch(w http.ResponseWriter, r *http.Request, params []string) {
+	query := r.URL.Query()
+	page := atoi(query.Get("page"))
+	if page < 1 {
+		page = 1
+	}
+	var username, categorySlug string
+	var tags []string
+	titleOnly := false
+	terms := make([]string, 0, 5)
+	for _, term := range strings.Fields(query.Get("q")) {
+		switch {
+		case strings.HasPrefix(term, "@"):
+			username = strings.TrimPrefix(term, "@")
+		case strings.HasPrefix(term, "#"):
+			categorySlug = strings.TrimPrefix(term, "#")
+		case strings.HasPrefix(term, "tags:"):
+			tags = strings.Split(strings.TrimPrefix(term, "tags:"), ",")
+		case term == "in:title":
+			titleOnly = true
+		case strings.HasPrefix(term, "order:"):
+		default:
+			terms = append(terms, strings.ToLower(term))
+		}
+	}
+
+	matches := make([]*Topic, 0, 5)
+	for _, t := range s.sortedTopics(true) {
+		first := s.firstPost(t.ID)
+		if first == nil {
+			continue
+		}
+		if username != "" && first.Username != username {
+			continue
+		}
+		if categorySlug != "" {
+			if c, ok := s.categories[t.CategoryID]; !ok || c.Slug != categorySlug {
+				continue
+			}
+		}
+		if len(tags) > 0 && !containsAny(t.Tags, tags) {
+			continue
+		}
+		text := strings.ToLower(t.Title)
+		if !titleOnly {
+			for _, p := range s.topicPosts(t.ID) {
+				text += "\n" + strings.ToLower(p.Raw)
+			}
+		}
+		matched := true
+		for _, term := range terms {
+			if !strings.Contains(text, term) {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			matches = append(matches, t)
+		}
+	}
+
+	posts := make([]map[string]any, 0, searchPageSize)
+	topics := make([]map[string]any, 0, searchPageSize)
+	start := (page - 1) * searchPageSize
+	for idx := start; idx < len(matches) && idx < start+searchPageSize; idx++ {
+		t := matches[idx]
+		first := s.firstPost(t.ID)
+		posts = append(posts, map[string]any{
+			"id":          first.ID,
+			"topic_id":    t.ID,
+			"post_number": first.PostNumber,
+			"username":    first.Username,
+			"blurb":       first.Raw,
+		})
+		topics = append(topics, map[string]any{
+			"id":          t.ID,
+			"title":       t.Title,
+			"category_id": t.CategoryID,
+			"tags":        nonNil(t.Tags),
+		})
+	}
+	writeJSON(w, map[string]any{
+		"posts":  posts,
+		"topics": topics,
+		"grouped_search_result": map[string]any{
+			"more_full_page_results": len(matches) > start+searchPageSize,
+		},
+	})
+}
+
+func (s *Server) handleCreatePost(w http.ResponseWriter, r *http.Request, params []string) {
+	input := struct {
+		Title      string   `json:"title"`
+		Raw        string   `json:"raw"`
+		Category   int      `json:"category"`
+		TopicID    int      `json:"topic_id"`
+		ExternalID string   `json:"external_id"`
+		Tags       []string `json:"tags"`
+	}{}
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_parameters", err.Error())
+		return
+	}
+	if !s.checkPostSize(w, input.Raw) {
+		return
+	}
+	username := r.Header.Get("Api-Username")
+	if username == "" {
+		username = defaultUsername
+	}
+	post := &Post{
+		ID:       s.nextPostID,
+		Username: username,
+		Raw:      input.Raw,
+	}
+	if input.TopicID != 0 {
+		if _, ok := s.topics[input.TopicID]; !ok {
+			writeNotFound(w)
+			return
+		}
+		post.TopicID = input.TopicID
+		post.PostNumber = 1
+		if posts := s.topicPosts(input.TopicID); len(posts) > 0 {
+			post.PostNumber = posts[len(posts)-1].PostNumber + 1
+		}
+	} else {
+		if strings.TrimSpace(input.Title) == "" {
+			writeError(w, http.StatusUnprocessableEntity, "", "Title can't be blank")
+			return
+		}
+		if _, ok := s.categories[input.Category]; !ok && input.Category != uncategorizedCategory {
+			writeError(w, http.StatusUnprocessableEntity, "", "Category can't be blank")
+			return
+		}
+		if input.ExternalID != "" {
+			for _, t := range s.topics {
+				if t.ExternalID == input.ExternalID {
+					writeError(w, http.StatusUnprocessableEntity, "", "External ID has already been taken")
+					return
+				}
+			}
+		}
+		topic := &Topic{
+			ID:         s.nextTopicID,
+			CategoryID: input.Category,
+			Title:      input.Title,
+			ExternalID: input.ExternalID,
+			Tags:       input.Tags,
+		}
+		s.nextTopicID++
+		s.topics[topic.ID] = topic
+		post.TopicID = topic.ID
+		post.PostNumber = 1
+	}
+	s.nextPostID++
+	s.posts[post.ID] = post
+	writeJSON(w, postJSON(post))
+}
+
+func (s *Server) handleGetPost(w http.ResponseWriter, r *http.Request, params []string) {
+	p, ok := s.posts[atoi(params[0])]
+	if !ok {
+		writeNotFound(w)
+		return
+	}
+	writeJSON(w, postJSON(p))
+}
+
+func (s *Server) handleUpdatePost(w http.ResponseWriter, r *http.Request, params []string) {
+	p, ok := s.posts[atoi(params[0])]
+	if !ok {
+		writeNotFound(w)
+		return
+	}
+	input := struct {
+		Post struct {
+			Raw        string `json:"raw"`
+			EditReason string `json:"edit_reason"`
+		} `json:"post"`
+	}{}
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_parameters", err.Error())
+		return
+	}
+	if !s.checkPostSize(w, input.Post.Raw) {
+		return
+	}
+	p.Raw = input.Post.Raw
+	p.EditReason = input.Post.EditReason
+	writeJSON(w, map[string]any{
+		"post": postJSON(p),
+	})
+}
+
+// handleDeletePost deletes the post. Deleting the first post deletes the
+// whole topic.
+func (s *Server) handleDeletePost(w http.ResponseWriter, r *http.Request, params []string) {
+	p, ok := s.posts[atoi(params[0])]
+	if !ok {
+		writeNotFound(w)
+		return
+	}
+	if p.PostNumber == 1 {
+		for _, other := range s.topicPosts(p.TopicID) {
+			delete(s.posts, other.ID)
+		}
+		delete(s.topics, p.TopicID)
+	}
+	delete(s.posts, p.ID)
+	writeJSON(w, map[string]any{})
+}
+
+func (s *Server) handleGetTopicByExternalID(w http.ResponseWriter, r *http.Request, params []string) {
+	externalID, _ := url.PathUnescape(params[0])
+	for _, t := range s.topics {
+		if t.ExternalID != "" && t.ExternalID == externalID {
+			http.Redirect(w, r, fmt.Sprintf("/t/%d.json", t.ID), http.StatusMovedPermanently)
+			return
+		}
+	}
+	writeNotFound(w)
+}
+
+func (s *Server) handleGetTopic(w http.ResponseWriter, r *http.Request, params []string) {
+	t, ok := s.topics[atoi(params[0])]
+	if !ok {
+		writeNotFound(w)
+		return
+	}
+	posts := s.topicPosts(t.ID)
+	included := make([]map[string]any, 0, topicPostsPageSize)
+	stream := make([]int, 0, len(posts))
+	for idx, p := range posts {
+		if idx < topicPostsPageSize {
+			included = append(included, topicPostJSON(p))
+		}
+		stream = append(stream, p.ID)
+	}
+	writeJSON(w, map[string]any{
+		"id":              t.ID,
+		"title":           t.Title,
+		"slug":            strings.ToLower(strings.ReplaceAll(t.Title, " ", "-")),
+		"category_id":     t.CategoryID,
+		"tags":            nonNil(t.Tags),
+		"posts_count":     len(posts),
+		"pinned":          t.Pinned,
+		"pinned_globally": t.PinnedGlobally,
+		"closed":          t.Closed,
+		"archived":        t.Archived,
+		"post_stream": map[string]any{
+			"posts":  included,
+			"stream": stream,
+		},
+	})
+}
+
+func (s *Server) handleGetTopicPosts(w http.ResponseWriter, r *http.Request, params []string) {
+	topicID := atoi(params[0])
+	if _, ok := s.topics[topicID]; !ok {
+		writeNotFound(w)
+		return
+	}
+	posts := make([]map[string]any, 0, topicPostsPageSize)
+	for _, rawID := range r.URL.Query()["post_ids[]"] {
+		if p, ok := s.posts[atoi(rawID)]; ok && p.TopicID == topicID {
+			posts = append(posts, topicPostJSON(p))
+		}
+	}
+	writeJSON(w, map[string]any{
+		"post_stream": map[string]any{
+			"posts": posts,
+		},
+	})
+}
+
+func (s *Server) handleUpdateTopic(w http.ResponseWriter, r *http.Request, params []string) {
+	t, ok := s.topics[atoi(params[0])]
+	if !ok {
+		writeNotFound(w)
+		return
+	}
+	input := struct {
+		Title      string   `json:"title"`
+		CategoryID int      `json:"category_id"`
+		Tags       []string `json:"tags"`
+		ExternalID string   `json:"external_id"`
+	}{}
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_parameters", err.Error())
+		return
+	}
+	if input.ExternalID != "" {
+		for _, other := range s.topics {
+			if other.ID != t.ID && other.ExternalID == input.ExternalID {
+				writeError(w, http.StatusUnprocessableEntity, "", "External ID has already been taken")
+				return
+			}
+		}
+		t.ExternalID = input.ExternalID
+	}
+	if input.CategoryID != 0 {
+		if _, ok := s.categories[input.CategoryID]; !ok {
+			writeError(w, http.StatusUnprocessableEntity, "", "Category can't be blank")
+			return
+		}
+		t.CategoryID = input.CategoryID
+	}
+	if input.Title != "" {
+		t.Title = input.Title
+	}
+	if input.Tags != nil {
+		t.Tags = input.Tags
+	}
+	writeJSON(w, map[string]any{
+		"basic_topic": s.listedTopicJSON(t),
+	})
+}
+
+func (s *Server) handleSetTopicStatus(w http.ResponseWriter, r *http.Request, params []string) {
+	t, ok := s.topics[atoi(params[0])]
+	if !ok {
+		writeNotFound(w)
+		return
+	}
+	input := struct {
+		Status  string `json:"status"`
+		Enabled string `json:"enabled"`
+	}{}
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_parameters", err.Error())
+		return
+	}
+	enabled := input.Enabled == "true"
+	switch input.Status {
+	case "pinned":
+		t.Pinned = enabled
+		if !enabled {
+			t.PinnedGlobally = false
+		}
+	case "pinned_globally":
+		t.Pinned = enabled
+		t.PinnedGlobally = enabled
+	case "closed":
+		t.Closed = enabled
+	case "archived":
+		t.Archived = enabled
+	default:
+		writeError(w, http.StatusBadRequest, "invalid_parameters", fmt.Sprintf("unsupported status: %s", input.Status))
+		return
+	}
+	writeJSON(w, map[string]any{
+		"success": "OK",
+	})
+}
@@ -0,0 +1,359 @@
+// Package communitytest provides an in-memory fake of the Discourse API that
+// can be used to test code built on top of the community package.
+//
+// The fake implements the endpoints used by the community client with the
+// same response documents and error format as Discourse. It keeps categories,
+// topics, and posts in memory and records all requests so that tests can
+// inspect both the resulting state and the calls that led to it:
+//
+//	srv := communitytest.NewServer(communitytest.Options{})
+//	defer srv.Close()
+//	srv.AddCategory(communitytest.Category{ID: 4, Slug: "releases"})
+//	comm := community.New(
+//		community.CommunityWithBaseURL(srv.URL()),
+//		community.CommunityWithHTTPClient(srv.Client()),
+//	)
+package communitytest
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+const (
+	defaultPageSize       = 30
+	searchPageSize        = 50
+	topicPostsPageSize    = 20
+	defaultUsername       = "system"
+	firstGeneratedID      = 100
+	uncategorizedCategory = 0
+)
+
+type Options struct {
+	// PostSizeLimit is the maximum number of characters of a post. Longer
+	// posts are rejected with status code 422. 0 disables the limit.
+	PostSizeLimit int
+	// APIUsername and APIKey are required for every request if set.
+	APIUsername string
+	APIKey      string
+	// PageSize is the number of topics per page of topic lists. It
+	// defaults to 30 like on Discourse.
+	PageSize int
+}
+
+type Category struct {
+	ID   int
+	Name string
+	Slug string
+}
+
+type Topic struct {
+	ID             int
+	CategoryID     int
+	Title          string
+	ExternalID     string
+	Tags           []string
+	Pinned         bool
+	PinnedGlobally bool
+	Closed         bool
+	Archived       bool
+}
+
+type Post struct {
+	ID         int
+	TopicID    int
+	PostNumber int
+	Username   string
+	Raw        string
+	EditReason string
+}
+
+// Request is a request received by the server.
+type Request struct {
+	Method string
+	Path   string
+	Query  url.Values
+	Body   string
+}
+
+// Server is the fake Discourse server. All methods are safe for concurrent
+// use.
+type Server struct {
+	opts        Options
+	srv         *httptest.Server
+	lock        sync.Mutex
+	categories  map[int]*Category
+	topics      map[int]*Topic
+	posts       map[int]*Post
+	requests    []Request
+	nextTopicID int
+	nextPostID  int
+}
+
+type route struct {
+	method  string
+	pattern *regexp.Regexp
+	handler func(w http.ResponseWriter, r *http.Request, params []string)
+}
+
+// NewServer starts a new fake server. It has to be closed after use.
+func NewServer(opts Options) *Server {
+	if opts.PageSize <= 0 {
+		opts.PageSize = defaultPageSize
+	}
+	s := &Server{
+		opts:        opts,
+		categories:  make(map[int]*Category),
+		topics:      make(map[int]*Topic),
+		posts:       make(map[int]*Post),
+		requests:    make([]Request, 0, 10),
+		nextTopicID: firstGeneratedID,
+		nextPostID:  firstGeneratedID,
+	}
+	routes := []route{
+		{http.MethodGet, regexp.MustCompile(`^/categories\.json$`), s.handleListCategories},
+		{http.MethodGet, regexp.MustCompile(`^/c/(\d+)/show\.json$`), s.handleGetCategory},
+		{http.MethodGet, regexp.MustCompile(`^/c/([^/]+)/(\d+)\.json$`), s.handleListCategoryTopics},
+		{http.MethodGet, regexp.MustCompile(`^/tags\.json$`), s.handleListTags},
+		{http.MethodGet, regexp.MustCompile(`^/tag/([^/]+)\.json$`), s.handleListTagTopics},
+		{http.MethodGet, regexp.MustCompile(`^/search\.json$`), s.handleSearch},
+		{http.MethodPost, regexp.MustCompile(`^/posts\.json$`), s.handleCreatePost},
+		{http.MethodGet, regexp.MustCompile(`^/posts/(\d+)\.json$`), s.handleGetPost},
+		{http.MethodPut, regexp.MustCompile(`^/posts/(\d+)\.json$`), s.handleUpdatePost},
+		{http.MethodDelete, regexp.MustCompile(`^/posts/(\d+)\.json$`), s.handleDeletePost},
+		{http.MethodGet, regexp.MustCompile(`^/t/external_id/([^/]+)\.json$`), s.handleGetTopicByExternalID},
+		{http.MethodGet, regexp.MustCompile(`^/t/(\d+)\.json$`), s.handleGetTopic},
+		{http.MethodGet, regexp.MustCompile(`^/t/(\d+)/posts\.json$`), s.handleGetTopicPosts},
+		{http.MethodPut, regexp.MustCompile(`^/t/-/(\d+)\.json$`), s.handleUpdateTopic},
+		{http.MethodPut, regexp.MustCompile(`^/t/(\d+)/status\.json$`), s.handleSetTopicStatus},
+	}
+	s.srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		r.Body.Close()
+		s.lock.Lock()
+		defer s.lock.Unlock()
+		s.requests = append(s.requests, Request{
+			Method: r.Method,
+			Path:   r.URL.Path,
+			Query:  r.URL.Query(),
+			Body:   string(body),
+		})
+		if s.opts.APIKey != "" && (r.Header.Get("Api-Key") != s.opts.APIKey || r.Header.Get("Api-Username") != s.opts.APIUsername) {
+			writeError(w, http.StatusForbidden, "invalid_access", "You are not permitted to view the requested resource.")
+			return
+		}
+		r.Body = io.NopCloser(strings.NewReader(string(body)))
+		for _, rt := range routes {
+			if rt.method != r.Method {
+				continue
+			}
+			if params := rt.pattern.FindStringSubmatch(r.URL.Path); params != nil {
+				rt.handler(w, r, params[1:])
+				return
+			}
+		}
+		writeNotFound(w)
+	}))
+	return s
+}
+
+// URL returns the base URL of the server.
+func (s *Server) URL() string {
+	return s.srv.URL
+}
+
+// Client returns an HTTP client configured for the server.
+func (s *Server) Client() *http.Client {
+	return s.srv.Client()
+}
+
+func (s *Server) Close() {
+	s.srv.Close()
+}
+
+// AddCategory adds or replaces a category.
+func (s *Server) AddCategory(c Category) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if c.Slug == "" {
+		c.Slug = strings.ToLower(strings.ReplaceAll(c.Name, " ", "-"))
+	}
+	s.categories[c.ID] = &c
+}
+
+// AddTopic adds or replaces a topic together with its posts. Posts without ID
+// or post number get one assigned. A topic without ID gets a new one and is
+// returned.
+func (s *Server) AddTopic(t Topic, posts ...Post) Topic {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if t.ID == 0 {
+		t.ID = s.nextTopicID
+	}
+	if t.ID >= s.nextTopicID {
+		s.nextTopicID = t.ID + 1
+	}
+	s.topics[t.ID] = &t
+	for _, p := range posts {
+		p.TopicID = t.ID
+		if p.ID == 0 {
+			p.ID = s.nextPostID
+		}
+		if p.ID >= s.nextPostID {
+			s.nextPostID = p.ID + 1
+		}
+		if p.PostNumber == 0 {
+			p.PostNumber = len(s.topicPosts(t.ID)) + 1
+		}
+		if p.Username == "" {
+			p.Username = defaultUsername
+		}
+		post := p
+		s.posts[p.ID] = &post
+	}
+	return t
+}
+
+// Topic returns the topic with the given ID.
+func (s *Server) Topic(id int) (Topic, bool) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	t, ok := s.topics[id]
+	if !ok {
+		return Topic{}, false
+	}
+	return *t, true
+}
+
+// Topics returns all topics ordered by their ID.
+func (s *Server) Topics() []Topic {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	result := make([]Topic, 0, len(s.topics))
+	for _, t := range s.sortedTopics(false) {
+		result = append(result, *t)
+	}
+	return result
+}
+
+// Post returns the post with the given ID.
+func (s *Server) Post(id int) (Post, bool) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	p, ok := s.posts[id]
+	if !ok {
+		return Post{}, false
+	}
+	return *p, true
+}
+
+// Posts returns the posts of the topic ordered by their post number.
+func (s *Server) Posts(topicID int) []Post {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	result := make([]Post, 0, 5)
+	for _, p := range s.topicPosts(topicID) {
+		result = append(result, *p)
+	}
+	return result
+}
+
+// Requests returns all requests received so far. If a method is given, only
+// requests with that method are returned.
+func (s *Server) Requests(method string) []Request {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	result := make([]Request, 0, len(s.requests))
+	for _, r := range s.requests {
+		if method == "" || r.Method == method {
+			result = append(result, r)
+		}
+	}
+	return result
+}
+
+func (s *Server) topicPosts(topicID int) []*Post {
+	result := make([]*Post, 0, 5)
+	for _, p := range s.posts {
+		if p.TopicID == topicID {
+			result = append(result, p)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].PostNumber < result[j].PostNumber
+	})
+	return result
+}
+
+func (s *Server) firstPost(topicID int) *Post {
+	posts := s.topicPosts(topicID)
+	if len(posts) == 0 || posts[0].PostNumber != 1 {
+		return nil
+	}
+	return posts[0]
+}
+
+// sortedTopics returns the topics ordered by their ID. Newer topics come
+// first if latest is set.
+func (s *Server) sortedTopics(latest bool) []*Topic {
+	result := make([]*Topic, 0, len(s.topics))
+	for _, t := range s.topics {
+		result = append(result, t)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if latest {
+			return result[i].ID > result[j].ID
+		}
+		return result[i].ID < result[j].ID
+	})
+	return result
+}
+
+func (s *Server) checkPostSize(w http.ResponseWriter, raw string) bool {
+	size := utf8.RuneCountInString(raw)
+	if s.opts.PostSizeLimit > 0 && size > s.opts.PostSizeLimit {
+		writeError(w, http.StatusUnprocessableEntity, "", fmt.Sprintf("Body is limited to %d characters; you entered %d.", s.opts.PostSizeLimit, size))
+		return false
+	}
+	if strings.TrimSpace(raw) == "" {
+		writeError(w, http.StatusUnprocessableEntity, "", "Body can't be blank")
+		return false
+	}
+	return true
+}
+
+func writeJSON(w http.ResponseWriter, data any) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(data)
+}
+
+func writeError(w http.ResponseWriter, status int, errorType string, messages ...string) {
+	data := map[string]any{
+		"errors": messages,
+	}
+	if errorType != "" {
+		data["error_type"] = errorType
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(data)
+}
+
+func writeNotFound(w http.ResponseWriter) {
+	writeError(w, http.StatusNotFound, "not_found", "The requested URL or resource could not be found.")
+}
+
+func atoi(value string) int {
+	result, _ := strconv.Atoi(value)
+	return result
+}
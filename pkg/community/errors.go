@@ -0,0 +1,48 @@
+package community
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// maxErrorBodySize limits how much of an error response is read.
+const maxErrorBodySize = 64 * 1024
+
+// APIError is returned if the Discourse API responds with an unexpected
+// status code. If the response contained the usual error document, its
+// details are decoded into Errors and ErrorType.
+type APIError struct {
+	StatusCode int
+	// Action is the API action that failed (e.g. `create_post`).
+	Action string `json:"action"`
+	// ErrorType is a machine-readable error category like
+	// `invalid_parameters` or `rate_limit`.
+	ErrorType string   `json:"error_type"`
+	Errors    []string `json:"errors"`
+}
+
+func (e *APIError) Error() string {
+	if len(e.Errors) == 0 {
+		return fmt.Sprintf("unexpected status code %d", e.StatusCode)
+	}
+	return fmt.Sprintf("unexpected status code %d: %s", e.StatusCode, strings.Join(e.Errors, "; "))
+}
+
+// newAPIError creates an APIError from the given response. The body is
+// consumed but not closed. Bodies that are not valid JSON are used as
+// error message as is.
+func newAPIError(resp *http.Response) *APIError {
+	result := APIError{}
+	raw, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBodySize))
+	if err := json.Unmarshal(raw, &result); err != nil {
+		result = APIError{}
+		if msg := strings.TrimSpace(string(raw)); msg != "" {
+			result.Errors = []string{msg}
+		}
+	}
+	result.StatusCode = resp.StatusCode
+	return &result
+}
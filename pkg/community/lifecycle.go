@@ -0,0 +1,125 @@
+package community
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/coreos/go-semver/semver"
+	"github.com/rs/zerolog"
+)
+
+const (
+	// PinNone leaves the pinning of topics untouched.
+	PinNone = ""
+	// PinCategory pins the topic inside its category.
+	PinCategory = "category"
+	// PinGlobal pins the topic globally.
+	PinGlobal = "global"
+)
+
+// ValidatePin returns an error if the pin mode is not supported.
+func ValidatePin(pin string) error {
+	switch pin {
+	case PinNone, PinCategory, PinGlobal:
+		return nil
+	default:
+		return fmt.Errorf("unsupported pin mode: %s", pin)
+	}
+}
+
+// topicVersion returns the version that follows the prefix in the title of a
+// related topic or nil if there is none.
+func topicVersion(title string, prefix string) *semver.Version {
+	fields := strings.Fields(strings.TrimPrefix(title, prefix))
+	if len(fields) == 0 {
+		return nil
+	}
+	v, err := semver.NewVersion(strings.TrimPrefix(fields[0], "v"))
+	if err != nil {
+		return nil
+	}
+	return v
+}
+
+// isNewerTopic reports whether the related topic is newer than the current
+// one. Versions in the titles are compared if both have one; otherwise the
+// creation order decides.
+func isNewerTopic(current *semver.Version, currentID int, related *semver.Version, relatedID int) bool {
+	if current != nil && related != nil {
+		return current.LessThan(*related)
+	}
+	return relatedID > currentID
+}
+
+// applyLifecycle updates the tags of the topic, pins it, and unpins, closes,
+// or archives the older related topics. As Grafana publishes parallel release
+// streams, the versions in the titles decide which topics are older and not
+// the order in which they were created.
+func (c *Community) applyLifecycle(ctx context.Context, topicID int, post PostInput, opts *PostOptions) error {
+	logger := zerolog.Ctx(ctx)
+	if err := ValidatePin(opts.Pin); err != nil {
+		return err
+	}
+	if len(post.Tags) > 0 {
+		if err := c.UpdateTopic(ctx, topicID, TopicUpdate{Tags: post.Tags}); err != nil {
+			return fmt.Errorf("failed to update tags of topic %d: %w", topicID, err)
+		}
+	}
+	pin := opts.Pin
+	if opts.RelatedTopicPrefix != "" && (pin != PinNone || opts.CloseRelatedTopics || opts.ArchiveRelatedTopics) {
+		// Only the latest topics are considered as older ones have been
+		// taken care of by previous runs:
+		topics, err := c.ListCategoryTopics(ctx, post.Category, nil)
+		if err != nil {
+			return fmt.Errorf("failed to list topics of category %d: %w", post.Category, err)
+		}
+		currentVersion := topicVersion(post.Title, opts.RelatedTopicPrefix)
+		for _, topic := range topics.Topics {
+			if topic.ID == topicID || !strings.HasPrefix(topic.Title, opts.RelatedTopicPrefix) {
+				continue
+			}
+			relatedVersion := topicVersion(topic.Title, opts.RelatedTopicPrefix)
+			if isNewerTopic(currentVersion, topicID, relatedVersion, topic.ID) {
+				// A newer related topic exists (e.g. when a post for an
+				// older release is updated) and so that one stays pinned:
+				if pin != PinNone {
+					logger.Info().Msgf("Not pinning topic %d as the newer topic %d exists", topicID, topic.ID)
+					pin = PinNone
+				}
+				continue
+			}
+			if opts.Pin != PinNone && (topic.Pinned || topic.PinnedGlobally) {
+				status := TopicStatusPinned
+				if topic.PinnedGlobally {
+					status = TopicStatusPinnedGlobally
+				}
+				logger.Info().Msgf("Unpinning topic %d", topic.ID)
+				if err := c.SetTopicStatus(ctx, topic.ID, status, false); err != nil {
+					return err
+				}
+			}
+			if opts.CloseRelatedTopics && !topic.Closed {
+				logger.Info().Msgf("Closing topic %d", topic.ID)
+				if err := c.SetTopicStatus(ctx, topic.ID, TopicStatusClosed, true); err != nil {
+					return err
+				}
+			}
+			if opts.ArchiveRelatedTopics && !topic.Archived {
+				logger.Info().Msgf("Archiving topic %d", topic.ID)
+				if err := c.SetTopicStatus(ctx, topic.ID, TopicStatusArchived, true); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	switch pin {
+	case PinCategory:
+		logger.Info().Msgf("Pinning topic %d", topicID)
+		return c.SetTopicStatus(ctx, topicID, TopicStatusPinned, true)
+	case PinGlobal:
+		logger.Info().Msgf("Pinning topic %d globally", topicID)
+		return c.SetTopicStatus(ctx, topicID, TopicStatusPinnedGlobally, true)
+	}
+	return nil
+}
@@ -22,3 +22,11 @@ func CommunityWithHTTPClient(client *http.Client) CommunityOption {
 		c.httpClient = client
 	}
 }
+
+// CommunityWithRetryPolicy overrides DefaultRetryPolicy for requests sent to
+// Discourse.
+func CommunityWithRetryPolicy(policy RetryPolicy) CommunityOption {
+	return func(c *Community) {
+		c.retryPolicy = policy
+	}
+}
@@ -5,35 +5,85 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
-	"net/http/httptest"
 	"strings"
 	"testing"
 	"unicode/utf8"
 
+	"github.com/grafana/grafana-github-actions-go/pkg/community/communitytest"
 	"github.com/stretchr/testify/require"
 )
 
+const testCategoryID = 4
+
+// newTestCommunity starts a fake Discourse server with a single category and
+// returns a client authenticated as user `test`.
+func newTestCommunity(t *testing.T, opts communitytest.Options) (*communitytest.Server, *Community) {
+	t.Helper()
+	srv := communitytest.NewServer(opts)
+	t.Cleanup(srv.Close)
+	srv.AddCategory(communitytest.Category{ID: testCategoryID, Name: "Hello", Slug: "hello"})
+	comm := New(
+		CommunityWithBaseURL(srv.URL()),
+		CommunityWithHTTPClient(srv.Client()),
+		CommunityWithAPICredentials("test", "key"),
+	)
+	return srv, comm
+}
+
+// writtenPosts returns the content of all posts created or updated on the
+// server in the order of the requests.
+func writtenPosts(t *testing.T, srv *communitytest.Server) []string {
+	t.Helper()
+	result := make([]string, 0, 5)
+	for _, req := range srv.Requests("") {
+		switch {
+		case req.Method == http.MethodPost && req.Path == "/posts.json":
+			input := struct {
+				Raw string `json:"raw"`
+			}{}
+			require.NoError(t, json.Unmarshal([]byte(req.Body), &input))
+			result = append(result, input.Raw)
+		case req.Method == http.MethodPut && strings.HasPrefix(req.Path, "/posts/"):
+			input := struct {
+				Post struct {
+					Raw string `json:"raw"`
+				} `json:"post"`
+			}{}
+			require.NoError(t, json.Unmarshal([]byte(req.Body), &input))
+			result = append(result, input.Post.Raw)
+		}
+	}
+	return result
+}
+
+func postContents(posts []communitytest.Post) []string {
+	result := make([]string, 0, len(posts))
+	for _, p := range posts {
+		result = append(result, p.Raw)
+	}
+	return result
+}
+
 func TestCommunityPost(t *testing.T) {
 	t.Run("short-content-create", func(t *testing.T) {
 		// For short content we can post the content directly to the server:
 		ctx := context.Background()
-		srv := NewMockCommunityServer(func(o *MockCommunityServerOptions) {
-			o.PostSizeLimit = 50_000
-		})
-		comm := New(CommunityWithBaseURL(srv.GetURL()), CommunityWithHTTPClient(srv.GetClient()))
+		srv, comm := newTestCommunity(t, communitytest.Options{PostSizeLimit: 50_000})
 		body := strings.Repeat("hello", 10)
 		_, err := comm.CreateOrUpdatePost(ctx, PostInput{
 			Title:    "Sample Post",
-			Category: 4,
+			Category: testCategoryID,
 			Body:     body,
 			Author:   "test",
 		}, &PostOptions{
 			FallbackBody: "fallback",
 		})
 		require.NoError(t, err)
-		postCalls := srv.GetPostCalls()
-		require.Len(t, postCalls, 1)
-		require.Equal(t, body, postCalls[0].Raw)
+		require.Equal(t, []string{body}, writtenPosts(t, srv))
+		topics := srv.Topics()
+		require.Len(t, topics, 1)
+		require.Equal(t, "Sample Post", topics[0].Title)
+		require.Equal(t, testCategoryID, topics[0].CategoryID)
 	})
 	t.Run("too-much-content-create", func(t *testing.T) {
 		// If the changelog is larger than 50000 characters, then the server
@@ -44,149 +94,448 @@ func TestCommunityPost(t *testing.T) {
 		// In such a situation, the client should try again with a shorter
 		// message.
 		ctx := context.Background()
-		srv := NewMockCommunityServer(func(o *MockCommunityServerOptions) {
-			o.PostSizeLimit = 50_000
-		})
-		comm := New(CommunityWithBaseURL(srv.GetURL()), CommunityWithHTTPClient(srv.GetClient()))
+		srv, comm := newTestCommunity(t, communitytest.Options{PostSizeLimit: 50_000})
 
 		// This produces a string with 50005 characters, which will go beyond
 		// the size limit:
 		body := strings.Repeat("hello", 10001)
 		_, err := comm.CreateOrUpdatePost(ctx, PostInput{
 			Title:    "Sample Post",
-			Category: 4,
+			Category: testCategoryID,
 			Body:     body,
 			Author:   "test",
 		}, &PostOptions{
 			FallbackBody: "fallback",
 		})
 		require.NoError(t, err)
-		postCalls := srv.GetPostCalls()
-		require.Len(t, postCalls, 2)
-		require.Equal(t, "fallback", postCalls[1].Raw)
+		require.Equal(t, []string{body, "fallback"}, writtenPosts(t, srv))
+		topics := srv.Topics()
+		require.Len(t, topics, 1)
+		require.Equal(t, []string{"fallback"}, postContents(srv.Posts(topics[0].ID)))
 	})
 
 	t.Run("too-much-content-update", func(t *testing.T) {
 		// Same as the previous test but for updating an existing post.
 		ctx := context.Background()
-		srv := NewMockCommunityServer(func(opts *MockCommunityServerOptions) {
-			opts.PostSizeLimit = 50_000
-			opts.ExistingPosts = []TestSearchPost{
-				{
-					ID:      1,
-					TopicID: 1,
-				},
-			}
-		})
-		comm := New(CommunityWithBaseURL(srv.GetURL()), CommunityWithHTTPClient(srv.GetClient()))
+		srv, comm := newTestCommunity(t, communitytest.Options{PostSizeLimit: 50_000})
+		srv.AddTopic(communitytest.Topic{ID: 1, CategoryID: testCategoryID, Title: "Sample Post"},
+			communitytest.Post{ID: 1, Username: "test", Raw: "old"},
+		)
 		body := strings.Repeat("hello", 10001)
 		_, err := comm.CreateOrUpdatePost(ctx, PostInput{
 			Title:    "Sample Post",
-			Category: 4,
+			Category: testCategoryID,
 			Body:     body,
 			Author:   "test",
 		}, &PostOptions{
 			FallbackBody: "fallback",
 		})
 		require.NoError(t, err)
-		postCalls := srv.GetPostCalls()
-		require.Len(t, postCalls, 2)
-		require.Equal(t, "fallback", postCalls[1].Raw)
+		require.Equal(t, []string{body, "fallback"}, writtenPosts(t, srv))
+		require.Equal(t, []string{"fallback"}, postContents(srv.Posts(1)))
 	})
-}
 
-type TestPost struct {
-	Title    string
-	Raw      string
-	Category int
-	Author   string
-}
-type TestPostUpdate struct {
-	Post struct {
-		Raw string
-	}
+	t.Run("too-much-content-create-thread", func(t *testing.T) {
+		// With a maximum post length, the content is split into a topic
+		// and replies instead of using the fallback:
+		ctx := context.Background()
+		srv, comm := newTestCommunity(t, communitytest.Options{PostSizeLimit: 30})
+		_, err := comm.CreateOrUpdatePost(ctx, PostInput{
+			Title:    "Sample Post",
+			Category: testCategoryID,
+			Body:     "### Features\n\n- Feature 1\n\n### Bug fixes\n\n- Fix 1",
+			Author:   "test",
+		}, &PostOptions{
+			FallbackBody:  "fallback",
+			MaxPostLength: 30,
+		})
+		require.NoError(t, err)
+		topics := srv.Topics()
+		require.Len(t, topics, 1)
+		require.Equal(t, []string{"### Features\n\n- Feature 1", "### Bug fixes\n\n- Fix 1"}, postContents(srv.Posts(topics[0].ID)))
+	})
+
+	t.Run("thread-above-server-limit-create", func(t *testing.T) {
+		// A maximum post length above the limit of the server still falls
+		// back to the fallback body:
+		ctx := context.Background()
+		srv, comm := newTestCommunity(t, communitytest.Options{PostSizeLimit: 20})
+		_, err := comm.CreateOrUpdatePost(ctx, PostInput{
+			Title:    "Sample Post",
+			Category: testCategoryID,
+			Body:     "### Features\n\n- Feature 1\n\n### Bug fixes\n\n- Fix 1",
+			Author:   "test",
+		}, &PostOptions{
+			FallbackBody:  "fallback",
+			MaxPostLength: 30,
+		})
+		require.NoError(t, err)
+		topics := srv.Topics()
+		require.Len(t, topics, 1)
+		require.Equal(t, []string{"fallback"}, postContents(srv.Posts(topics[0].ID)))
+	})
+
+	t.Run("thread-above-server-limit-reply", func(t *testing.T) {
+		// Only a reply exceeds the limit of the server and so the replies
+		// that were already created are removed again:
+		ctx := context.Background()
+		srv, comm := newTestCommunity(t, communitytest.Options{PostSizeLimit: 25})
+		_, err := comm.CreateOrUpdatePost(ctx, PostInput{
+			Title:    "Sample Post",
+			Category: testCategoryID,
+			Body:     "### A\n\n- 1\n\n### B\n\n- 2\n\n### C\n\n- 3333333333333333333",
+			Author:   "test",
+		}, &PostOptions{
+			FallbackBody:  "fallback",
+			MaxPostLength: 30,
+		})
+		require.NoError(t, err)
+		topics := srv.Topics()
+		require.Len(t, topics, 1)
+		require.Equal(t, []string{"fallback"}, postContents(srv.Posts(topics[0].ID)))
+	})
+
+	t.Run("thread-above-server-limit-update", func(t *testing.T) {
+		ctx := context.Background()
+		srv, comm := newTestCommunity(t, communitytest.Options{PostSizeLimit: 20})
+		srv.AddTopic(communitytest.Topic{ID: 1, CategoryID: testCategoryID, Title: "Sample Post"},
+			communitytest.Post{ID: 1, Username: "test", Raw: "old 1"},
+			communitytest.Post{ID: 2, Username: "test", Raw: "old 2"},
+		)
+		_, err := comm.CreateOrUpdatePost(ctx, PostInput{
+			Title:    "Sample Post",
+			Category: testCategoryID,
+			Body:     "### Features\n\n- Feature 1\n\n### Bug fixes\n\n- Fix 1",
+			Author:   "test",
+		}, &PostOptions{
+			FallbackBody:  "fallback",
+			MaxPostLength: 30,
+		})
+		require.NoError(t, err)
+		require.Equal(t, []string{"fallback"}, postContents(srv.Posts(1)))
+	})
+
+	t.Run("update-thread", func(t *testing.T) {
+		// Existing replies are updated and the ones no longer needed are
+		// deleted. Replies of other users are left alone.
+		ctx := context.Background()
+		srv, comm := newTestCommunity(t, communitytest.Options{PostSizeLimit: 30})
+		srv.AddTopic(communitytest.Topic{ID: 1, CategoryID: testCategoryID, Title: "Sample Post"},
+			communitytest.Post{ID: 1, Username: "test", Raw: "old 1"},
+			communitytest.Post{ID: 2, Username: "test", Raw: "old 2"},
+			communitytest.Post{ID: 3, Username: "someone", Raw: "Thanks!"},
+			communitytest.Post{ID: 4, Username: "test", Raw: "old 3"},
+		)
+		id, err := comm.CreateOrUpdatePost(ctx, PostInput{
+			Title:    "Sample Post",
+			Category: testCategoryID,
+			Body:     "### Features\n\n- Feature 1\n\n### Bug fixes\n\n- Fix 1",
+			Author:   "test",
+		}, &PostOptions{
+			MaxPostLength: 30,
+		})
+		require.NoError(t, err)
+		require.Equal(t, 1, id)
+		require.Equal(t, []string{"### Features\n\n- Feature 1", "### Bug fixes\n\n- Fix 1", "Thanks!"}, postContents(srv.Posts(1)))
+		_, found := srv.Post(4)
+		require.False(t, found)
+	})
+
+	t.Run("update-long-thread", func(t *testing.T) {
+		// Discourse only includes the first 20 posts in a topic and so the
+		// remaining replies have to be loaded separately:
+		ctx := context.Background()
+		srv, comm := newTestCommunity(t, communitytest.Options{PostSizeLimit: 30})
+		posts := make([]communitytest.Post, 0, 25)
+		for i := 0; i < 25; i++ {
+			posts = append(posts, communitytest.Post{Username: "test", Raw: fmt.Sprintf("old %d", i)})
+		}
+		topic := srv.AddTopic(communitytest.Topic{CategoryID: testCategoryID, Title: "Sample Post"}, posts...)
+		_, err := comm.CreateOrUpdatePost(ctx, PostInput{
+			Title:    "Sample Post",
+			Category: testCategoryID,
+			Body:     "### Features\n\n- Feature 1\n\n### Bug fixes\n\n- Fix 1",
+			Author:   "test",
+		}, &PostOptions{
+			MaxPostLength: 30,
+		})
+		require.NoError(t, err)
+		require.Equal(t, []string{"### Features\n\n- Feature 1", "### Bug fixes\n\n- Fix 1"}, postContents(srv.Posts(topic.ID)))
+	})
 }
 
-type TestSearchPost struct {
-	ID      int `json:"id"`
-	TopicID int `json:"topic_id"`
+func TestCommunityPostLookup(t *testing.T) {
+	t.Run("create-with-external-id", func(t *testing.T) {
+		ctx := context.Background()
+		srv, comm := newTestCommunity(t, communitytest.Options{PostSizeLimit: 50_000})
+		input := PostInput{
+			Title:      "Sample Post",
+			Category:   testCategoryID,
+			Body:       "hello",
+			Author:     "test",
+			ExternalID: "grafana-changelog-1.0.0",
+		}
+		id, err := comm.CreateOrUpdatePost(ctx, input, nil)
+		require.NoError(t, err)
+		topics := srv.Topics()
+		require.Len(t, topics, 1)
+		require.Equal(t, "grafana-changelog-1.0.0", topics[0].ExternalID)
+
+		// A rerun finds the topic again using the external ID even if the
+		// title changed:
+		input.Body = "updated"
+		input.Title = "Renamed Post"
+		updatedID, err := comm.CreateOrUpdatePost(ctx, input, nil)
+		require.NoError(t, err)
+		require.Equal(t, id, updatedID)
+		require.Len(t, srv.Topics(), 1)
+		require.Equal(t, []string{"updated"}, postContents(srv.Posts(topics[0].ID)))
+	})
+
+	t.Run("external-id-before-search", func(t *testing.T) {
+		ctx := context.Background()
+		srv, comm := newTestCommunity(t, communitytest.Options{PostSizeLimit: 50_000})
+		srv.AddTopic(communitytest.Topic{ID: 1, CategoryID: testCategoryID, Title: "Sample Post"},
+			communitytest.Post{ID: 1, Username: "test", Raw: "old"},
+		)
+		srv.AddTopic(communitytest.Topic{ID: 2, CategoryID: testCategoryID, Title: "Sample Post", ExternalID: "grafana-changelog-1.0.0"},
+			communitytest.Post{ID: 2, Username: "test", Raw: "old"},
+		)
+		id, err := comm.CreateOrUpdatePost(ctx, PostInput{
+			Title:      "Sample Post",
+			Category:   testCategoryID,
+			Body:       "hello",
+			Author:     "test",
+			ExternalID: "grafana-changelog-1.0.0",
+		}, nil)
+		require.NoError(t, err)
+		require.Equal(t, 2, id)
+		require.Equal(t, []string{"old"}, postContents(srv.Posts(1)))
+	})
+
+	t.Run("search-requires-exact-title", func(t *testing.T) {
+		// Legacy topics without external ID are found by searching but a
+		// similar title is not enough:
+		ctx := context.Background()
+		srv, comm := newTestCommunity(t, communitytest.Options{PostSizeLimit: 50_000})
+		srv.AddTopic(communitytest.Topic{ID: 1, CategoryID: testCategoryID, Title: "Changelog: Updates in Grafana 11.2.10"},
+			communitytest.Post{ID: 1, Username: "test", Raw: "old"},
+		)
+		_, err := comm.CreateOrUpdatePost(ctx, PostInput{
+			Title:      "Changelog: Updates in Grafana 11.2.1",
+			Category:   testCategoryID,
+			Body:       "hello",
+			Author:     "test",
+			ExternalID: "grafana-changelog-11.2.1",
+		}, nil)
+		require.NoError(t, err)
+		topics := srv.Topics()
+		require.Len(t, topics, 2)
+		require.Equal(t, "Changelog: Updates in Grafana 11.2.1", topics[1].Title)
+		require.Equal(t, []string{"old"}, postContents(srv.Posts(1)))
+	})
+
+	t.Run("legacy-topic-gets-external-id", func(t *testing.T) {
+		// A legacy topic found by searching is given the external ID so
+		// that the next run doesn't have to search again:
+		ctx := context.Background()
+		srv, comm := newTestCommunity(t, communitytest.Options{PostSizeLimit: 50_000})
+		srv.AddTopic(communitytest.Topic{ID: 1, CategoryID: testCategoryID, Title: "Sample Post"},
+			communitytest.Post{ID: 1, Username: "test", Raw: "old"},
+		)
+		input := PostInput{
+			Title:      "Sample Post",
+			Category:   testCategoryID,
+			Body:       "hello",
+			Author:     "test",
+			ExternalID: "grafana-changelog-1.0.0",
+		}
+		id, err := comm.CreateOrUpdatePost(ctx, input, nil)
+		require.NoError(t, err)
+		require.Equal(t, 1, id)
+		topic, _ := srv.Topic(1)
+		require.Equal(t, "grafana-changelog-1.0.0", topic.ExternalID)
+		searches := len(searchRequests(srv))
+		require.Equal(t, 1, searches)
+
+		input.Body = "updated"
+		id, err = comm.CreateOrUpdatePost(ctx, input, nil)
+		require.NoError(t, err)
+		require.Equal(t, 1, id)
+		require.Equal(t, []string{"updated"}, postContents(srv.Posts(1)))
+		require.Len(t, searchRequests(srv), searches)
+		lookups := srv.Requests(http.MethodGet)
+		require.Contains(t, requestPaths(lookups), "/t/external_id/grafana-changelog-1.0.0.json")
+	})
 }
 
-type MockCommunityServerOptions struct {
-	PostSizeLimit int
-	ExistingPosts []TestSearchPost
+func searchRequests(srv *communitytest.Server) []communitytest.Request {
+	result := make([]communitytest.Request, 0, 2)
+	for _, req := range srv.Requests(http.MethodGet) {
+		if req.Path == "/search.json" {
+			result = append(result, req)
+		}
+	}
+	return result
 }
 
-type MockCommunityServer struct {
-	opts      MockCommunityServerOptions
-	srv       *httptest.Server
-	postCalls []TestPost
+func requestPaths(requests []communitytest.Request) []string {
+	result := make([]string, 0, len(requests))
+	for _, req := range requests {
+		result = append(result, req.Path)
+	}
+	return result
 }
 
-func NewMockCommunityServer(options func(*MockCommunityServerOptions)) *MockCommunityServer {
-	opts := MockCommunityServerOptions{}
-	options(&opts)
-	s := MockCommunityServer{
-		opts:      opts,
-		postCalls: make([]TestPost, 0, 5),
+func TestCommunityPostLifecycle(t *testing.T) {
+	addCategoryTopics := func(srv *communitytest.Server) {
+		srv.AddTopic(communitytest.Topic{ID: 1, CategoryID: testCategoryID, Title: "Changelog: Updates in Grafana 1.0.0", Pinned: true})
+		srv.AddTopic(communitytest.Topic{ID: 2, CategoryID: testCategoryID, Title: "Changelog: Updates in Grafana 1.0.1", Closed: true})
+		srv.AddTopic(communitytest.Topic{ID: 3, CategoryID: testCategoryID, Title: "Something else", Pinned: true})
 	}
-	handler := http.NewServeMux()
-	handler.HandleFunc("/c/4/show.json", func(w http.ResponseWriter, r *http.Request) {
-		fmt.Fprintf(w, `{"category": {"id": 4, "slug": "hello"}}`)
-	})
-	handler.HandleFunc("/search.json", func(w http.ResponseWriter, r *http.Request) {
-		data := map[string]interface{}{
-			"posts": s.opts.ExistingPosts,
+	t.Run("pin-and-close", func(t *testing.T) {
+		ctx := context.Background()
+		srv, comm := newTestCommunity(t, communitytest.Options{PostSizeLimit: 50_000})
+		addCategoryTopics(srv)
+		_, err := comm.CreateOrUpdatePost(ctx, PostInput{
+			Title:    "Changelog: Updates in Grafana 1.1.0",
+			Category: testCategoryID,
+			Body:     "hello",
+			Author:   "test",
+			Tags:     []string{"release", "grafana-1"},
+		}, &PostOptions{
+			Pin:                PinGlobal,
+			RelatedTopicPrefix: "Changelog: Updates in Grafana ",
+			CloseRelatedTopics: true,
+		})
+		require.NoError(t, err)
+		topics := srv.Topics()
+		require.Len(t, topics, 4)
+		require.Equal(t, communitytest.Topic{ID: 1, CategoryID: testCategoryID, Title: "Changelog: Updates in Grafana 1.0.0", Closed: true}, topics[0])
+		require.Equal(t, communitytest.Topic{ID: 2, CategoryID: testCategoryID, Title: "Changelog: Updates in Grafana 1.0.1", Closed: true}, topics[1])
+		require.Equal(t, communitytest.Topic{ID: 3, CategoryID: testCategoryID, Title: "Something else", Pinned: true}, topics[2])
+		require.Equal(t, []string{"release", "grafana-1"}, topics[3].Tags)
+		require.True(t, topics[3].PinnedGlobally)
+		// Topics that are already closed are left alone:
+		statusCalls := 0
+		for _, req := range srv.Requests(http.MethodPut) {
+			if strings.HasSuffix(req.Path, "/status.json") {
+				statusCalls++
+			}
 		}
-		json.NewEncoder(w).Encode(data)
-	})
-	handler.HandleFunc("/posts.json", func(w http.ResponseWriter, r *http.Request) {
-		defer r.Body.Close()
-		post := TestPost{}
-		if err := json.NewDecoder(r.Body).Decode(&post); err != nil {
-			http.Error(w, "Decoding failed", http.StatusInternalServerError)
-			return
+		require.Equal(t, 3, statusCalls)
+	})
+
+	t.Run("newer-topic-stays-pinned", func(t *testing.T) {
+		ctx := context.Background()
+		srv, comm := newTestCommunity(t, communitytest.Options{PostSizeLimit: 50_000})
+		srv.AddTopic(communitytest.Topic{ID: 1, CategoryID: testCategoryID, Title: "Changelog: Updates in Grafana 1.0.0"},
+			communitytest.Post{ID: 1, Username: "test", Raw: "old"},
+		)
+		srv.AddTopic(communitytest.Topic{ID: 2, CategoryID: testCategoryID, Title: "Changelog: Updates in Grafana 1.0.1", Pinned: true})
+		_, err := comm.CreateOrUpdatePost(ctx, PostInput{
+			Title:    "Changelog: Updates in Grafana 1.0.0",
+			Category: testCategoryID,
+			Body:     "hello",
+			Author:   "test",
+		}, &PostOptions{
+			Pin:                PinCategory,
+			RelatedTopicPrefix: "Changelog: Updates in Grafana ",
+		})
+		require.NoError(t, err)
+		topic, _ := srv.Topic(1)
+		require.False(t, topic.Pinned)
+		topic, _ = srv.Topic(2)
+		require.True(t, topic.Pinned)
+	})
+
+	t.Run("parallel-release-streams", func(t *testing.T) {
+		ctx := context.Background()
+		srv, comm := newTestCommunity(t, communitytest.Options{PostSizeLimit: 50_000})
+		srv.AddTopic(communitytest.Topic{ID: 1, CategoryID: testCategoryID, Title: "Changelog: Updates in Grafana 10.4.1"})
+		srv.AddTopic(communitytest.Topic{ID: 2, CategoryID: testCategoryID, Title: "Changelog: Updates in Grafana 11.2.0"})
+		opts := &PostOptions{
+			Pin:                  PinCategory,
+			RelatedTopicPrefix:   "Changelog: Updates in Grafana ",
+			CloseRelatedTopics:   true,
+			ArchiveRelatedTopics: true,
 		}
-		s.postCalls = append(s.postCalls, post)
-		if size := utf8.RuneCountInString(post.Raw); size > s.opts.PostSizeLimit {
-			w.WriteHeader(http.StatusUnprocessableEntity)
-			fmt.Fprintf(w, `{"action":"create_post","errors":["Body is limited to %d characters; you entered %d."]}`, s.opts.PostSizeLimit, size)
-			return
+		publish := func(version string) int {
+			id, err := comm.CreateOrUpdatePost(ctx, PostInput{
+				Title:    "Changelog: Updates in Grafana " + version,
+				Category: testCategoryID,
+				Body:     "hello",
+				Author:   "test",
+			}, opts)
+			require.NoError(t, err)
+			return id
 		}
-		fmt.Fprintf(w, `{}`)
-	})
-	for _, existing := range s.opts.ExistingPosts {
-		handler.HandleFunc(fmt.Sprintf("/posts/%d.json", existing.ID), func(w http.ResponseWriter, r *http.Request) {
-			defer r.Body.Close()
-			post := TestPostUpdate{}
-			if err := json.NewDecoder(r.Body).Decode(&post); err != nil {
-				http.Error(w, "Decoding failed", http.StatusInternalServerError)
-				return
-			}
-			s.postCalls = append(s.postCalls, TestPost{
-				Raw: post.Post.Raw,
-			})
-			if size := utf8.RuneCountInString(post.Post.Raw); size > s.opts.PostSizeLimit {
-				w.WriteHeader(http.StatusUnprocessableEntity)
-				fmt.Fprintf(w, `{"action":"create_post","errors":["Body is limited to %d characters; you entered %d."]}`, s.opts.PostSizeLimit, size)
-				return
-			}
-			fmt.Fprintf(w, `{}`)
-		})
-	}
-	srv := httptest.NewServer(handler)
-	s.srv = srv
-	return &s
-}
 
-func (m *MockCommunityServer) GetPostCalls() []TestPost {
-	return m.postCalls
-}
+		newer := publish("11.2.1")
+		older := publish("10.4.2")
 
-func (m *MockCommunityServer) GetURL() string {
-	return m.srv.URL
+		// The patch of the older stream was published last but must not
+		// affect the topic of the newer stream:
+		topic, _ := srv.Topic(newer)
+		require.True(t, topic.Pinned)
+		require.False(t, topic.Closed)
+		require.False(t, topic.Archived)
+		topic, _ = srv.Topic(older)
+		require.False(t, topic.Pinned)
+		require.False(t, topic.Closed)
+		for _, id := range []int{1, 2} {
+			topic, _ = srv.Topic(id)
+			require.True(t, topic.Closed, "topic %d", id)
+			require.True(t, topic.Archived, "topic %d", id)
+		}
+	})
+
+	t.Run("invalid-pin", func(t *testing.T) {
+		require.Error(t, ValidatePin("sometimes"))
+	})
 }
 
-func (m *MockCommunityServer) GetClient() *http.Client {
-	return m.srv.Client()
+func TestSplitPost(t *testing.T) {
+	tests := []struct {
+		name     string
+		body     string
+		limit    int
+		expected []string
+	}{
+		{
+			name:     "no-limit",
+			body:     "### A\n\n- 1\n",
+			limit:    0,
+			expected: []string{"### A\n\n- 1"},
+		},
+		{
+			name:     "sections",
+			body:     "### A\n\n- 1\n\n### B\n\n- 2\n\n### C\n\n- 3",
+			limit:    22,
+			expected: []string{"### A\n\n- 1\n\n### B\n\n- 2", "### C\n\n- 3"},
+		},
+		{
+			name:     "long-section",
+			body:     "### A\n\n- 1111\n- 2222\n- 3333",
+			limit:    25,
+			expected: []string{"### A\n\n- 1111\n- 2222", "### A (continued)\n\n- 3333"},
+		},
+		{
+			name:     "long-line",
+			body:     "1234567890",
+			limit:    4,
+			expected: []string{"1234", "5678", "90"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			chunks := SplitPost(test.body, test.limit)
+			require.Equal(t, test.expected, chunks)
+			for _, chunk := range chunks {
+				if test.limit > 0 {
+					require.LessOrEqual(t, utf8.RuneCountInString(chunk), test.limit)
+				}
+			}
+		})
+	}
 }
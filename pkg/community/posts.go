@@ -0,0 +1,84 @@
+package community
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// ErrPostTooLong is returned if the server rejects the content of a post. The
+// size limit depends on the configuration of the server.
+var ErrPostTooLong = errors.New("post content is too long")
+
+// Post is a single post as returned by `/posts/<id>.json` and the search.
+type Post struct {
+	ID         int    `json:"id"`
+	TopicID    int    `json:"topic_id"`
+	PostNumber int    `json:"post_number"`
+	Username   string `json:"username"`
+	// Raw contains the Markdown source of the post. It is not included in
+	// search results.
+	Raw string `json:"raw"`
+	// Blurb is the excerpt of the post shown in search results.
+	Blurb string `json:"blurb"`
+}
+
+// PostUpdate contains the new content of a post.
+type PostUpdate struct {
+	Raw        string `json:"raw"`
+	EditReason string `json:"edit_reason,omitempty"`
+}
+
+type replyInput struct {
+	TopicID int    `json:"topic_id"`
+	Body    string `json:"raw"`
+}
+
+// GetPost retrieves a single post including its Markdown source.
+func (c *Community) GetPost(ctx context.Context, postID int) (*Post, error) {
+	result := Post{}
+	if err := c.do(ctx, http.MethodGet, fmt.Sprintf("/posts/%d.json", postID), nil, nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// CreateReply adds a new post to the topic. If the body is longer than
+// allowed by the server, an error wrapping ErrPostTooLong is returned.
+func (c *Community) CreateReply(ctx context.Context, topicID int, raw string) (*Post, error) {
+	result := Post{}
+	if err := c.do(ctx, http.MethodPost, "/posts.json", nil, replyInput{TopicID: topicID, Body: raw}, &result); err != nil {
+		return nil, fmt.Errorf("creating a reply failed: %w", wrapPostTooLong(err))
+	}
+	return &result, nil
+}
+
+// UpdatePost replaces the content of the post. If the body is longer than
+// allowed by the server, an error wrapping ErrPostTooLong is returned.
+func (c *Community) UpdatePost(ctx context.Context, postID int, update PostUpdate) error {
+	if err := c.do(ctx, http.MethodPut, fmt.Sprintf("/posts/%d.json", postID), nil, map[string]any{
+		"post": update,
+	}, nil); err != nil {
+		return fmt.Errorf("updating post %d failed: %w", postID, wrapPostTooLong(err))
+	}
+	return nil
+}
+
+// DeletePost deletes the post.
+func (c *Community) DeletePost(ctx context.Context, postID int) error {
+	if err := c.do(ctx, http.MethodDelete, fmt.Sprintf("/posts/%d.json", postID), nil, nil, nil); err != nil {
+		return fmt.Errorf("deleting post %d failed: %w", postID, err)
+	}
+	return nil
+}
+
+// wrapPostTooLong marks validation errors of the post content with
+// ErrPostTooLong.
+func wrapPostTooLong(err error) error {
+	apiErr := &APIError{}
+	if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusUnprocessableEntity {
+		return fmt.Errorf("%w: %w", ErrPostTooLong, err)
+	}
+	return err
+}
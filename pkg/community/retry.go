@@ -0,0 +1,198 @@
+package community
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// RetryPolicy controls how requests to Discourse are retried.
+//
+// Requests rejected with status code 429 are always retried as Discourse
+// didn't process them. Network errors and 5xx responses are only retried
+// for idempotent requests (GET, HEAD, PUT, DELETE) as a POST might have
+// created a post already.
+type RetryPolicy struct {
+	// MaxRetries is the number of retries after the initial attempt. 0
+	// disables retries.
+	MaxRetries int
+	// BaseDelay is the delay before the first retry. It is doubled for
+	// every following one.
+	BaseDelay time.Duration
+	// MaxDelay caps the delay between two attempts. If the server asks
+	// for a longer delay using the Retry-After header, the request is not
+	// retried.
+	MaxDelay time.Duration
+}
+
+var DefaultRetryPolicy = RetryPolicy{
+	MaxRetries: 5,
+	BaseDelay:  time.Second,
+	MaxDelay:   time.Minute,
+}
+
+type retryTransport struct {
+	base   http.RoundTripper
+	policy RetryPolicy
+	sleep  func(ctx context.Context, d time.Duration) error
+	now    func() time.Time
+
+	lock sync.Mutex
+	// blockedUntil is set when the server rate-limits us so that other
+	// requests wait as well instead of hitting the limit again.
+	blockedUntil time.Time
+}
+
+func newRetryTransport(base http.RoundTripper, policy RetryPolicy) *retryTransport {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &retryTransport{
+		base:   base,
+		policy: policy,
+		sleep:  sleepContext,
+		now:    time.Now,
+	}
+}
+
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	logger := zerolog.Ctx(ctx)
+	attemptReq := req
+	for attempt := 0; ; attempt++ {
+		if err := t.waitForRateLimit(ctx); err != nil {
+			return nil, err
+		}
+		if attempt > 0 && req.Body != nil && req.Body != http.NoBody {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			attemptReq = req.Clone(ctx)
+			attemptReq.Body = body
+		}
+		resp, err := t.base.RoundTrip(attemptReq)
+		delay, retry := t.retryDelay(req, resp, err, attempt)
+		if !retry {
+			return resp, err
+		}
+		if err != nil {
+			logger.Warn().Err(err).Msgf("%s %s failed, retrying in %s", req.Method, req.URL.Path, delay)
+		} else {
+			logger.Warn().Msgf("%s %s returned status code %d, retrying in %s", req.Method, req.URL.Path, resp.StatusCode, delay)
+			io.Copy(io.Discard, io.LimitReader(resp.Body, maxErrorBodySize))
+			resp.Body.Close()
+		}
+		if delay > 0 {
+			if err := t.sleep(ctx, delay); err != nil {
+				return nil, err
+			}
+		}
+	}
+}
+
+// retryDelay decides if the request should be retried and how long to wait
+// before doing so.
+func (t *retryTransport) retryDelay(req *http.Request, resp *http.Response, err error, attempt int) (time.Duration, bool) {
+	if attempt >= t.policy.MaxRetries || req.Context().Err() != nil {
+		return 0, false
+	}
+	// Without GetBody the request body cannot be sent again:
+	if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+		return 0, false
+	}
+	backoff := t.policy.BaseDelay << attempt
+	if backoff > t.policy.MaxDelay || backoff <= 0 {
+		backoff = t.policy.MaxDelay
+	}
+	if err != nil {
+		return backoff, isIdempotent(req.Method)
+	}
+	switch {
+	case resp.StatusCode == http.StatusTooManyRequests:
+	case resp.StatusCode >= 500 && resp.StatusCode != http.StatusNotImplemented:
+		if !isIdempotent(req.Method) {
+			return 0, false
+		}
+	default:
+		return 0, false
+	}
+	delay, ok := t.parseRetryAfter(resp.Header.Get("Retry-After"))
+	if !ok {
+		return backoff, true
+	}
+	if delay > t.policy.MaxDelay {
+		return 0, false
+	}
+	if resp.StatusCode == http.StatusTooManyRequests {
+		t.lock.Lock()
+		if until := t.now().Add(delay); until.After(t.blockedUntil) {
+			t.blockedUntil = until
+		}
+		t.lock.Unlock()
+		// The other requests wait for the rate limit as well:
+		return 0, true
+	}
+	return delay, true
+}
+
+// parseRetryAfter supports both the delay-seconds and the HTTP-date format of
+// the Retry-After header.
+func (t *retryTransport) parseRetryAfter(value string) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+		return time.Duration(seconds) * time.Second, true
+	}
+	date, err := http.ParseTime(value)
+	if err != nil {
+		return 0, false
+	}
+	delay := date.Sub(t.now())
+	if delay < 0 {
+		delay = 0
+	}
+	return delay, true
+}
+
+func (t *retryTransport) waitForRateLimit(ctx context.Context) error {
+	t.lock.Lock()
+	delay := t.blockedUntil.Sub(t.now())
+	t.lock.Unlock()
+	if delay <= 0 {
+		return nil
+	}
+	zerolog.Ctx(ctx).Info().Msgf("Waiting %s for the rate limit to reset", delay)
+	return t.sleep(ctx, delay)
+}
+
+func isIdempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+func sleepContext(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,173 @@
+package community
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRetryTransport(t *testing.T) {
+	// responder returns the given status codes in order and 200 afterwards.
+	responder := func(codes ...int) (*httptest.Server, *[]string) {
+		var lock sync.Mutex
+		calls := make([]string, 0, len(codes)+1)
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			lock.Lock()
+			defer lock.Unlock()
+			body, _ := io.ReadAll(r.Body)
+			calls = append(calls, string(body))
+			if len(calls) <= len(codes) {
+				if codes[len(calls)-1] == http.StatusTooManyRequests {
+					w.Header().Set("Retry-After", "3")
+				}
+				w.WriteHeader(codes[len(calls)-1])
+				fmt.Fprintf(w, `{"errors":["failed"]}`)
+				return
+			}
+			fmt.Fprintf(w, `{}`)
+		}))
+		t.Cleanup(srv.Close)
+		return srv, &calls
+	}
+	newTransport := func(sleeps *[]time.Duration) *retryTransport {
+		transport := newRetryTransport(nil, RetryPolicy{
+			MaxRetries: 2,
+			BaseDelay:  time.Second,
+			MaxDelay:   10 * time.Second,
+		})
+		now := time.Now()
+		transport.now = func() time.Time { return now }
+		transport.sleep = func(ctx context.Context, d time.Duration) error {
+			*sleeps = append(*sleeps, d)
+			now = now.Add(d)
+			return nil
+		}
+		return transport
+	}
+
+	tests := []struct {
+		name           string
+		method         string
+		codes          []int
+		expectedStatus int
+		expectedCalls  int
+		expectedSleeps []time.Duration
+	}{
+		{
+			name:           "get-with-server-errors",
+			method:         http.MethodGet,
+			codes:          []int{http.StatusBadGateway, http.StatusServiceUnavailable},
+			expectedStatus: http.StatusOK,
+			expectedCalls:  3,
+			expectedSleeps: []time.Duration{time.Second, 2 * time.Second},
+		},
+		{
+			name:           "get-exceeding-retries",
+			method:         http.MethodGet,
+			codes:          []int{http.StatusBadGateway, http.StatusBadGateway, http.StatusBadGateway},
+			expectedStatus: http.StatusBadGateway,
+			expectedCalls:  3,
+			expectedSleeps: []time.Duration{time.Second, 2 * time.Second},
+		},
+		{
+			name:           "post-with-server-error",
+			method:         http.MethodPost,
+			codes:          []int{http.StatusBadGateway},
+			expectedStatus: http.StatusBadGateway,
+			expectedCalls:  1,
+			expectedSleeps: []time.Duration{},
+		},
+		{
+			name:           "post-rate-limited",
+			method:         http.MethodPost,
+			codes:          []int{http.StatusTooManyRequests},
+			expectedStatus: http.StatusOK,
+			expectedCalls:  2,
+			expectedSleeps: []time.Duration{3 * time.Second},
+		},
+		{
+			name:           "client-error",
+			method:         http.MethodPut,
+			codes:          []int{http.StatusForbidden},
+			expectedStatus: http.StatusForbidden,
+			expectedCalls:  1,
+			expectedSleeps: []time.Duration{},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			srv, calls := responder(test.codes...)
+			sleeps := make([]time.Duration, 0, 2)
+			client := &http.Client{Transport: newTransport(&sleeps)}
+			req, err := http.NewRequest(test.method, srv.URL, strings.NewReader("body"))
+			require.NoError(t, err)
+			resp, err := client.Do(req)
+			require.NoError(t, err)
+			defer resp.Body.Close()
+			require.Equal(t, test.expectedStatus, resp.StatusCode)
+			require.Len(t, *calls, test.expectedCalls)
+			for _, call := range *calls {
+				// The body is sent again with every attempt:
+				require.Equal(t, "body", call)
+			}
+			require.Equal(t, test.expectedSleeps, sleeps)
+		})
+	}
+
+	t.Run("retry-after-too-long", func(t *testing.T) {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Retry-After", "3600")
+			w.WriteHeader(http.StatusTooManyRequests)
+		}))
+		defer srv.Close()
+		sleeps := make([]time.Duration, 0, 2)
+		client := &http.Client{Transport: newTransport(&sleeps)}
+		resp, err := client.Get(srv.URL)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		require.Equal(t, http.StatusTooManyRequests, resp.StatusCode)
+		require.Empty(t, sleeps)
+	})
+
+	t.Run("retry-after-date", func(t *testing.T) {
+		transport := newRetryTransport(nil, DefaultRetryPolicy)
+		now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+		transport.now = func() time.Time { return now }
+		delay, ok := transport.parseRetryAfter(now.Add(5 * time.Second).Format(http.TimeFormat))
+		require.True(t, ok)
+		require.Equal(t, 5*time.Second, delay)
+		_, ok = transport.parseRetryAfter("soon")
+		require.False(t, ok)
+	})
+}
+
+func TestAPIError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+		fmt.Fprintf(w, `{"errors":["You are not permitted to view the requested resource."],"error_type":"invalid_access"}`)
+	}))
+	defer srv.Close()
+	comm := New(CommunityWithBaseURL(srv.URL), CommunityWithHTTPClient(srv.Client()))
+	_, err := comm.CreateOrUpdatePost(context.Background(), PostInput{
+		Title:    "Sample Post",
+		Category: 4,
+		Body:     "hello",
+		Author:   "test",
+	}, nil)
+	require.Error(t, err)
+	apiErr := &APIError{}
+	require.True(t, errors.As(err, &apiErr))
+	require.Equal(t, http.StatusForbidden, apiErr.StatusCode)
+	require.Equal(t, "invalid_access", apiErr.ErrorType)
+	require.Equal(t, []string{"You are not permitted to view the requested resource."}, apiErr.Errors)
+	require.ErrorContains(t, err, "You are not permitted to view the requested resource.")
+}
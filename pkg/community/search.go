@@ -2,56 +2,59 @@ package community
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
-	"io"
 	"net/http"
 	"net/url"
-	"os"
 	"strconv"
 )
 
 type SearchResult struct {
-	Posts []Post `json:"posts"`
+	Posts               []Post        `json:"posts"`
+	Topics              []SearchTopic `json:"topics"`
+	GroupedSearchResult struct {
+		MoreFullPageResults bool `json:"more_full_page_results"`
+	} `json:"grouped_search_result"`
 }
 
-type Post struct {
-	ID      int `json:"id"`
-	TopicID int `json:"topic_id"`
+type SearchTopic struct {
+	ID         int      `json:"id"`
+	Title      string   `json:"title"`
+	CategoryID int      `json:"category_id"`
+	Tags       []string `json:"tags"`
 }
 
-type Category struct {
-	Category struct {
-		ID   int    `json:"id"`
-		Slug string `json:"slug"`
-	} `json:"category"`
+// HasMore returns true if there is another page of results.
+func (r *SearchResult) HasMore() bool {
+	return r.GroupedSearchResult.MoreFullPageResults
+}
+
+func (r *SearchResult) topicTitle(topicID int) string {
+	for _, t := range r.Topics {
+		if t.ID == topicID {
+			return t.Title
+		}
+	}
+	return ""
 }
 
 type SearchOptions struct {
+	// Page of the results starting at 1.
 	Page int
 }
 
-func (c *Community) search(ctx context.Context, query string, opts *SearchOptions) (*SearchResult, error) {
+// Search runs a full-text search. The query supports the usual Discourse
+// filters like `@username`, `#category-slug`, `tags:name`, or `in:title`.
+func (c *Community) Search(ctx context.Context, query string, opts *SearchOptions) (*SearchResult, error) {
 	result := SearchResult{}
+	page := 1
+	if opts != nil && opts.Page > 0 {
+		page = opts.Page
+	}
 	qs := url.Values{}
-	qs.Set("page", strconv.Itoa(opts.Page))
+	qs.Set("page", strconv.Itoa(page))
 	qs.Set("q", query)
-	req, err := c.buildRequest(ctx, http.MethodGet, "/search.json", qs, nil)
-	if err != nil {
-		return nil, fmt.Errorf("failed to build request URL: %w", err)
-	}
-	resp, err := c.httpClient.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("failed to send search request: %w", err)
-	}
-	if resp.StatusCode != 200 {
-		io.Copy(os.Stderr, resp.Body)
-		resp.Body.Close()
-		return nil, fmt.Errorf("search request returned with unexpected status code %d", resp.StatusCode)
-	}
-	defer resp.Body.Close()
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return nil, fmt.Errorf("failed to decode search response: %w", err)
+	if err := c.do(ctx, http.MethodGet, "/search.json", qs, nil, &result); err != nil {
+		return nil, fmt.Errorf("search request failed: %w", err)
 	}
 	return &result, nil
 }
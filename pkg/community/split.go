@@ -0,0 +1,135 @@
+package community
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// continuedSuffix is appended to the heading of a section that had to be
+// split across multiple posts.
+const continuedSuffix = " (continued)"
+
+type postSection struct {
+	Heading string
+	Lines   []string
+}
+
+// SplitPost splits the body into chunks of at most limit characters. Chunks
+// are split at headings so that every section ends up in a single post if
+// possible. Sections that are longer than the limit are split between lines
+// and their heading is repeated with a "(continued)" suffix. A limit of 0 or
+// less returns the body as a single chunk.
+func SplitPost(body string, limit int) []string {
+	body = strings.TrimSpace(body)
+	if limit <= 0 || utf8.RuneCountInString(body) <= limit {
+		return []string{body}
+	}
+	result := make([]string, 0, 3)
+	current := strings.Builder{}
+	flush := func() {
+		if chunk := strings.TrimSpace(current.String()); chunk != "" {
+			result = append(result, chunk)
+		}
+		current.Reset()
+	}
+	// fits checks if the text can be added to the current chunk including
+	// the separating newline.
+	fits := func(text string) bool {
+		length := utf8.RuneCountInString(strings.TrimSpace(current.String())) + utf8.RuneCountInString(text)
+		if current.Len() > 0 {
+			length++
+		}
+		return length <= limit
+	}
+	add := func(text string) {
+		if current.Len() > 0 {
+			current.WriteString("\n")
+		}
+		current.WriteString(text)
+	}
+
+	for _, section := range splitPostSections(body) {
+		text := strings.Join(section.allLines(), "\n")
+		if fits(text) {
+			add(text)
+			continue
+		}
+		flush()
+		if fits(text) {
+			add(text)
+			continue
+		}
+		// The section has to be split between lines:
+		if section.Heading != "" {
+			add(section.Heading)
+		}
+		for _, line := range section.Lines {
+			if fits(line) {
+				add(line)
+				continue
+			}
+			// A chunk that only contains the heading is kept so that the
+			// line can be split below it:
+			if section.Heading == "" || strings.TrimSpace(current.String()) != section.Heading {
+				flush()
+				if section.Heading != "" {
+					add(section.Heading + continuedSuffix)
+					add("")
+				}
+			}
+			for !fits(line) {
+				// Lines that are too long on their own are split
+				// forcefully:
+				available := limit - utf8.RuneCountInString(current.String())
+				if current.Len() > 0 {
+					available--
+				}
+				head, tail := splitRunes(line, available)
+				add(head)
+				flush()
+				line = tail
+			}
+			add(line)
+		}
+	}
+	flush()
+	return result
+}
+
+func (s postSection) allLines() []string {
+	if s.Heading == "" {
+		return s.Lines
+	}
+	return append([]string{s.Heading}, s.Lines...)
+}
+
+// splitPostSections splits the body at every Markdown heading.
+func splitPostSections(body string) []postSection {
+	result := make([]postSection, 0, 10)
+	current := postSection{}
+	for _, line := range strings.Split(body, "\n") {
+		if strings.HasPrefix(line, "#") {
+			if current.Heading != "" || len(current.Lines) > 0 {
+				result = append(result, current)
+			}
+			current = postSection{Heading: line}
+			continue
+		}
+		current.Lines = append(current.Lines, line)
+	}
+	if current.Heading != "" || len(current.Lines) > 0 {
+		result = append(result, current)
+	}
+	return result
+}
+
+func splitRunes(s string, n int) (string, string) {
+	if n < 1 {
+		n = 1
+	}
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s, ""
+	}
+	return string(runes[:n]), string(runes[n:])
+}
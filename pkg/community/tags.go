@@ -0,0 +1,35 @@
+package community
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// Tag is a tag as listed by `/tags.json`.
+type Tag struct {
+	ID    string `json:"id"`
+	Text  string `json:"text"`
+	Count int    `json:"count"`
+}
+
+// ListTags returns all tags of the site.
+func (c *Community) ListTags(ctx context.Context) ([]Tag, error) {
+	result := struct {
+		Tags []Tag `json:"tags"`
+	}{}
+	if err := c.do(ctx, http.MethodGet, "/tags.json", nil, nil, &result); err != nil {
+		return nil, err
+	}
+	return result.Tags, nil
+}
+
+// ListTagTopics returns a page of the latest topics with the given tag.
+func (c *Community) ListTagTopics(ctx context.Context, tag string, opts *ListOptions) (*TopicList, error) {
+	result := topicListResponse{}
+	if err := c.do(ctx, http.MethodGet, fmt.Sprintf("/tag/%s.json", url.PathEscape(tag)), opts.values(), nil, &result); err != nil {
+		return nil, err
+	}
+	return &result.TopicList, nil
+}
@@ -0,0 +1,63 @@
+package community
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/rs/zerolog"
+)
+
+// syncThread updates the first post of the topic with the first chunk and
+// the replies of the author with the remaining ones. Missing replies are
+// created and replies that are no longer needed are deleted.
+func (c *Community) syncThread(ctx context.Context, topicID int, author string, chunks []string) (int, error) {
+	logger := zerolog.Ctx(ctx)
+	topic, err := c.GetTopic(ctx, topicID)
+	if err != nil {
+		return -1, fmt.Errorf("failed to retrieve topic %d: %w", topicID, err)
+	}
+	first := topic.firstPost()
+	if first == nil {
+		return -1, fmt.Errorf("first post of topic %d not found", topicID)
+	}
+	firstPostID := first.ID
+	posts, err := c.ListTopicPosts(ctx, topic)
+	if err != nil {
+		return -1, fmt.Errorf("failed to retrieve posts of topic %d: %w", topicID, err)
+	}
+	replies := make([]TopicPost, 0, len(chunks))
+	for _, p := range posts {
+		if p.PostNumber > 1 && p.Username == author {
+			replies = append(replies, p)
+		}
+	}
+	sort.Slice(replies, func(i, j int) bool {
+		return replies[i].PostNumber < replies[j].PostNumber
+	})
+
+	logger.Info().Msgf("Updating post %d", firstPostID)
+	if err := c.UpdatePost(ctx, firstPostID, PostUpdate{Raw: chunks[0], EditReason: editReason}); err != nil {
+		return firstPostID, err
+	}
+	for idx, chunk := range chunks[1:] {
+		if idx < len(replies) {
+			logger.Info().Msgf("Updating reply %d", replies[idx].ID)
+			if err := c.UpdatePost(ctx, replies[idx].ID, PostUpdate{Raw: chunk, EditReason: editReason}); err != nil {
+				return firstPostID, err
+			}
+			continue
+		}
+		logger.Info().Msgf("Adding reply to topic %d", topicID)
+		if _, err := c.CreateReply(ctx, topicID, chunk); err != nil {
+			return firstPostID, err
+		}
+	}
+	for idx := len(chunks) - 1; idx < len(replies); idx++ {
+		logger.Info().Msgf("Deleting reply %d", replies[idx].ID)
+		if err := c.DeletePost(ctx, replies[idx].ID); err != nil {
+			return firstPostID, err
+		}
+	}
+	return firstPostID, nil
+}
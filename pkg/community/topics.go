@@ -0,0 +1,159 @@
+package community
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// TopicStatus is a status of a topic that can be toggled using
+// SetTopicStatus.
+type TopicStatus string
+
+const (
+	TopicStatusPinned         TopicStatus = "pinned"
+	TopicStatusPinnedGlobally TopicStatus = "pinned_globally"
+	TopicStatusClosed         TopicStatus = "closed"
+	TopicStatusArchived       TopicStatus = "archived"
+)
+
+// topicPostsPageSize is the number of posts Discourse includes in a topic
+// and accepts per request for additional posts.
+const topicPostsPageSize = 20
+
+// Topic is a topic as returned by `/t/<id>.json`.
+type Topic struct {
+	ID             int      `json:"id"`
+	Title          string   `json:"title"`
+	Slug           string   `json:"slug"`
+	CategoryID     int      `json:"category_id"`
+	Tags           []string `json:"tags"`
+	PostsCount     int      `json:"posts_count"`
+	Pinned         bool     `json:"pinned"`
+	PinnedGlobally bool     `json:"pinned_globally"`
+	Closed         bool     `json:"closed"`
+	Archived       bool     `json:"archived"`
+	PostStream     struct {
+		// Posts contains only the first posts of the topic. Use
+		// ListTopicPosts to retrieve all of them.
+		Posts []TopicPost `json:"posts"`
+		// Stream contains the IDs of all posts of the topic.
+		Stream []int `json:"stream"`
+	} `json:"post_stream"`
+}
+
+// firstPost returns the opening post of the topic.
+func (t *Topic) firstPost() *TopicPost {
+	for idx, p := range t.PostStream.Posts {
+		if p.PostNumber == 1 {
+			return &t.PostStream.Posts[idx]
+		}
+	}
+	return nil
+}
+
+// TopicPost is a post as included in a topic. Its content is only available
+// as HTML.
+type TopicPost struct {
+	ID         int    `json:"id"`
+	PostNumber int    `json:"post_number"`
+	Username   string `json:"username"`
+	Cooked     string `json:"cooked"`
+}
+
+// TopicUpdate contains the attributes of a topic that should be changed.
+// Empty fields are left unchanged.
+type TopicUpdate struct {
+	Title      string   `json:"title,omitempty"`
+	CategoryID int      `json:"category_id,omitempty"`
+	Tags       []string `json:"tags,omitempty"`
+	ExternalID string   `json:"external_id,omitempty"`
+}
+
+// GetTopic retrieves the topic including its first posts.
+func (c *Community) GetTopic(ctx context.Context, topicID int) (*Topic, error) {
+	result := Topic{}
+	if err := c.do(ctx, http.MethodGet, fmt.Sprintf("/t/%d.json", topicID), nil, nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// GetTopicByExternalID returns the topic with the given external ID or nil if
+// there is none. Discourse redirects the request to the actual topic URL.
+func (c *Community) GetTopicByExternalID(ctx context.Context, externalID string) (*Topic, error) {
+	result := Topic{}
+	if err := c.do(ctx, http.MethodGet, fmt.Sprintf("/t/external_id/%s.json", url.PathEscape(externalID)), nil, nil, &result); err != nil {
+		if IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &result, nil
+}
+
+// ListTopicPosts returns all posts of the topic. Posts that were not included
+// in the topic itself are loaded in batches.
+func (c *Community) ListTopicPosts(ctx context.Context, topic *Topic) ([]TopicPost, error) {
+	result := make([]TopicPost, 0, len(topic.PostStream.Stream))
+	result = append(result, topic.PostStream.Posts...)
+	loaded := make(map[int]struct{}, len(result))
+	for _, p := range result {
+		loaded[p.ID] = struct{}{}
+	}
+	missing := make([]int, 0, len(topic.PostStream.Stream))
+	for _, id := range topic.PostStream.Stream {
+		if _, found := loaded[id]; !found {
+			missing = append(missing, id)
+		}
+	}
+	for start := 0; start < len(missing); start += topicPostsPageSize {
+		end := min(start+topicPostsPageSize, len(missing))
+		values := url.Values{}
+		for _, id := range missing[start:end] {
+			values.Add("post_ids[]", strconv.Itoa(id))
+		}
+		batch := struct {
+			PostStream struct {
+				Posts []TopicPost `json:"posts"`
+			} `json:"post_stream"`
+		}{}
+		if err := c.do(ctx, http.MethodGet, fmt.Sprintf("/t/%d/posts.json", topic.ID), values, nil, &batch); err != nil {
+			return nil, err
+		}
+		result = append(result, batch.PostStream.Posts...)
+	}
+	return result, nil
+}
+
+// CreateTopic creates a new topic with the post as its opening post. If the
+// body is longer than allowed by the server, an error wrapping ErrPostTooLong
+// is returned.
+func (c *Community) CreateTopic(ctx context.Context, post PostInput) (*Post, error) {
+	result := Post{}
+	if err := c.do(ctx, http.MethodPost, "/posts.json", nil, post, &result); err != nil {
+		return nil, fmt.Errorf("creating a new topic failed: %w", wrapPostTooLong(err))
+	}
+	return &result, nil
+}
+
+// UpdateTopic changes the title, category, or tags of the topic.
+func (c *Community) UpdateTopic(ctx context.Context, topicID int, update TopicUpdate) error {
+	if err := c.do(ctx, http.MethodPut, fmt.Sprintf("/t/-/%d.json", topicID), nil, update, nil); err != nil {
+		return fmt.Errorf("updating topic %d failed: %w", topicID, err)
+	}
+	return nil
+}
+
+// SetTopicStatus enables or disables the status of the topic.
+func (c *Community) SetTopicStatus(ctx context.Context, topicID int, status TopicStatus, enabled bool) error {
+	if err := c.do(ctx, http.MethodPut, fmt.Sprintf("/t/%d/status.json", topicID), nil, map[string]any{
+		"status":  status,
+		"enabled": fmt.Sprintf("%t", enabled),
+	}, nil); err != nil {
+		return fmt.Errorf("failed to set status `%s` of topic %d: %w", status, topicID, err)
+	}
+	return nil
+}
@@ -0,0 +1,133 @@
+package ghgql
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/Khan/genqlient/graphql"
+	"github.com/rs/zerolog"
+)
+
+// authorsBatchSize limits the number of pull requests that are looked up
+// within a single GraphQL query.
+const authorsBatchSize = 50
+
+// PullRequestAuthors contains the author of a pull request and the authors of
+// all its commits (including co-authors).
+type PullRequestAuthors struct {
+	Number int
+	// Author is the login of the user who opened the pull request.
+	Author string
+	// AuthorIsBot is true if the pull request was opened by an app.
+	AuthorIsBot bool
+	// CommitAuthors contains the logins of all users who authored or
+	// co-authored a commit of the pull request in order of appearance.
+	CommitAuthors []string
+}
+
+type pullRequestAuthorsNode struct {
+	Number int `json:"number"`
+	Author *struct {
+		Login        string `json:"login"`
+		ResourcePath string `json:"resourcePath"`
+	} `json:"author"`
+	Commits struct {
+		Nodes []struct {
+			Commit struct {
+				Authors struct {
+					Nodes []struct {
+						User *struct {
+							Login string `json:"login"`
+						} `json:"user"`
+					} `json:"nodes"`
+				} `json:"authors"`
+			} `json:"commit"`
+		} `json:"nodes"`
+	} `json:"commits"`
+}
+
+type pullRequestAuthorsResponse struct {
+	Repository map[string]*pullRequestAuthorsNode `json:"repository"`
+}
+
+// GetPullRequestAuthors retrieves the authors of the given pull requests.
+// genqlient doesn't support a dynamic number of aliased fields and so the
+// query is built by hand. All pull requests are fetched using as few queries
+// as possible.
+func (c *Client) GetPullRequestAuthors(ctx context.Context, repoOwner string, repoName string, numbers []int) (map[int]PullRequestAuthors, error) {
+	logger := zerolog.Ctx(ctx)
+	result := make(map[int]PullRequestAuthors)
+	for start := 0; start < len(numbers); start += authorsBatchSize {
+		end := start + authorsBatchSize
+		if end > len(numbers) {
+			end = len(numbers)
+		}
+		req := &graphql.Request{
+			OpName: "getPullRequestAuthors",
+			Query:  buildPullRequestAuthorsQuery(numbers[start:end]),
+			Variables: map[string]any{
+				"owner": repoOwner,
+				"repo":  repoName,
+			},
+		}
+		data := pullRequestAuthorsResponse{}
+		err := c.gql.MakeRequest(ctx, req, &graphql.Response{Data: &data})
+		if err != nil {
+			// Pull requests that cannot be found produce an error but the
+			// others are still returned:
+			if len(data.Repository) == 0 {
+				return nil, err
+			}
+			logger.Warn().Err(err).Msg("Not all pull request authors could be retrieved")
+		}
+		for _, node := range data.Repository {
+			if node == nil {
+				continue
+			}
+			result[node.Number] = node.toPullRequestAuthors()
+		}
+	}
+	return result, nil
+}
+
+func buildPullRequestAuthorsQuery(numbers []int) string {
+	out := strings.Builder{}
+	out.WriteString("query getPullRequestAuthors($owner: String!, $repo: String!) {\n")
+	out.WriteString("  repository(owner: $owner, name: $repo) {\n")
+	for _, num := range numbers {
+		out.WriteString(fmt.Sprintf("    pr%d: pullRequest(number: %d) {\n", num, num))
+		out.WriteString("      number\n")
+		out.WriteString("      author { login resourcePath }\n")
+		out.WriteString("      commits(first: 100) { nodes { commit { authors(first: 10) { nodes { user { login } } } } } }\n")
+		out.WriteString("    }\n")
+	}
+	out.WriteString("  }\n")
+	out.WriteString("}\n")
+	return out.String()
+}
+
+func (n *pullRequestAuthorsNode) toPullRequestAuthors() PullRequestAuthors {
+	result := PullRequestAuthors{
+		Number:        n.Number,
+		CommitAuthors: make([]string, 0, 2),
+	}
+	if n.Author != nil {
+		result.Author = n.Author.Login
+		result.AuthorIsBot = strings.HasPrefix(n.Author.ResourcePath, "/apps/")
+	}
+	seen := make(map[string]struct{})
+	for _, commit := range n.Commits.Nodes {
+		for _, author := range commit.Commit.Authors.Nodes {
+			if author.User == nil || author.User.Login == "" {
+				continue
+			}
+			if _, found := seen[author.User.Login]; found {
+				continue
+			}
+			seen[author.User.Login] = struct{}{}
+			result.CommitAuthors = append(result.CommitAuthors, author.User.Login)
+		}
+	}
+	return result
+}
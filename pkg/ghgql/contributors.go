@@ -0,0 +1,66 @@
+package ghgql
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Khan/genqlient/graphql"
+)
+
+// contributorsBatchSize limits the number of searches that are done within a
+// single GraphQL query.
+const contributorsBatchSize = 20
+
+type issueCountResponse struct {
+	IssueCount int `json:"issueCount"`
+}
+
+// CountMergedPullRequestsBefore returns for every provided author the number
+// of pull requests they got merged into the repository before the given point
+// in time. This is done through aliased searches so that only few queries are
+// necessary.
+func (c *Client) CountMergedPullRequestsBefore(ctx context.Context, repoOwner string, repoName string, authors map[string]time.Time) (map[string]int, error) {
+	logins := make([]string, 0, len(authors))
+	for login := range authors {
+		logins = append(logins, login)
+	}
+	result := make(map[string]int, len(authors))
+	for start := 0; start < len(logins); start += contributorsBatchSize {
+		end := start + contributorsBatchSize
+		if end > len(logins) {
+			end = len(logins)
+		}
+		batch := logins[start:end]
+		variables := make(map[string]any, len(batch))
+		query := strings.Builder{}
+		params := make([]string, 0, len(batch))
+		for idx, login := range batch {
+			params = append(params, fmt.Sprintf("$q%d: String!", idx))
+			variables[fmt.Sprintf("q%d", idx)] = fmt.Sprintf("repo:%s/%s is:pr is:merged author:%s merged:<%s", repoOwner, repoName, login, authors[login].UTC().Format(time.RFC3339))
+		}
+		query.WriteString(fmt.Sprintf("query countMergedPullRequests(%s) {\n", strings.Join(params, ", ")))
+		for idx := range batch {
+			query.WriteString(fmt.Sprintf("  a%d: search(query: $q%d, type: ISSUE, first: 0) { issueCount }\n", idx, idx))
+		}
+		query.WriteString("}\n")
+		req := &graphql.Request{
+			OpName:    "countMergedPullRequests",
+			Query:     query.String(),
+			Variables: variables,
+		}
+		data := make(map[string]issueCountResponse)
+		if err := c.gql.MakeRequest(ctx, req, &graphql.Response{Data: &data}); err != nil {
+			return nil, err
+		}
+		for idx, login := range batch {
+			count, ok := data[fmt.Sprintf("a%d", idx)]
+			if !ok {
+				return nil, fmt.Errorf("no search result returned for %s", login)
+			}
+			result[login] = count.IssueCount
+		}
+	}
+	return result, nil
+}
@@ -11,12 +11,59 @@ import (
 	"github.com/Khan/genqlient/graphql"
 )
 
+// A comment author association with repository.
+type CommentAuthorAssociation string
+
+const (
+	// Author has been invited to collaborate on the repository.
+	CommentAuthorAssociationCollaborator CommentAuthorAssociation = "COLLABORATOR"
+	// Author has previously committed to the repository.
+	CommentAuthorAssociationContributor CommentAuthorAssociation = "CONTRIBUTOR"
+	// Author has not previously committed to GitHub.
+	CommentAuthorAssociationFirstTimer CommentAuthorAssociation = "FIRST_TIMER"
+	// Author has not previously committed to the repository.
+	CommentAuthorAssociationFirstTimeContributor CommentAuthorAssociation = "FIRST_TIME_CONTRIBUTOR"
+	// Author is a placeholder for an unclaimed user.
+	CommentAuthorAssociationMannequin CommentAuthorAssociation = "MANNEQUIN"
+	// Author is a member of the organization that owns the repository.
+	CommentAuthorAssociationMember CommentAuthorAssociation = "MEMBER"
+	// Author has no association with the repository.
+	CommentAuthorAssociationNone CommentAuthorAssociation = "NONE"
+	// Author is the owner of the repository.
+	CommentAuthorAssociationOwner CommentAuthorAssociation = "OWNER"
+)
+
+// The review status of a pull request.
+type PullRequestReviewDecision string
+
+const (
+	// The pull request has received an approving review.
+	PullRequestReviewDecisionApproved PullRequestReviewDecision = "APPROVED"
+	// Changes have been requested on the pull request.
+	PullRequestReviewDecisionChangesRequested PullRequestReviewDecision = "CHANGES_REQUESTED"
+	// A review is required before the pull request can be merged.
+	PullRequestReviewDecisionReviewRequired PullRequestReviewDecision = "REVIEW_REQUIRED"
+)
+
+// The possible states of a pull request.
+type PullRequestState string
+
+const (
+	// A pull request that has been closed without being merged.
+	PullRequestStateClosed PullRequestState = "CLOSED"
+	// A pull request that has been closed by being merged.
+	PullRequestStateMerged PullRequestState = "MERGED"
+	// A pull request that is still open.
+	PullRequestStateOpen PullRequestState = "OPEN"
+)
+
 // __getMilestonedPullRequestsInput is used internally by genqlient
 type __getMilestonedPullRequestsInput struct {
-	Owner           string `json:"owner"`
-	Repo            string `json:"repo"`
-	MilestoneNumber int    `json:"milestoneNumber"`
-	Cursor          string `json:"cursor"`
+	Owner           string             `json:"owner"`
+	Repo            string             `json:"repo"`
+	MilestoneNumber int                `json:"milestoneNumber"`
+	States          []PullRequestState `json:"states"`
+	Cursor          string             `json:"cursor"`
 }
 
 // GetOwner returns __getMilestonedPullRequestsInput.Owner, and is useful for accessing the field via an interface.
@@ -28,6 +75,9 @@ func (v *__getMilestonedPullRequestsInput) GetRepo() string { return v.Repo }
 // GetMilestoneNumber returns __getMilestonedPullRequestsInput.MilestoneNumber, and is useful for accessing the field via an interface.
 func (v *__getMilestonedPullRequestsInput) GetMilestoneNumber() int { return v.MilestoneNumber }
 
+// GetStates returns __getMilestonedPullRequestsInput.States, and is useful for accessing the field via an interface.
+func (v *__getMilestonedPullRequestsInput) GetStates() []PullRequestState { return v.States }
+
 // GetCursor returns __getMilestonedPullRequestsInput.Cursor, and is useful for accessing the field via an interface.
 func (v *__getMilestonedPullRequestsInput) GetCursor() string { return v.Cursor }
 
@@ -111,8 +161,16 @@ type getMilestonedPullRequestsRepositoryMilestonePullRequestsPullRequestConnecti
 	Labels getMilestonedPullRequestsRepositoryMilestonePullRequestsPullRequestConnectionNodesPullRequestLabelsLabelConnection `json:"labels"`
 	// The actor who authored the comment.
 	Author getMilestonedPullRequestsRepositoryMilestonePullRequestsPullRequestConnectionNodesPullRequestAuthorActor `json:"-"`
+	// Author's association with the subject of the comment.
+	AuthorAssociation CommentAuthorAssociation `json:"authorAssociation"`
 	// Identifies the name of the head Ref associated with the pull request, even if the ref has been deleted.
 	HeadRefName string `json:"headRefName"`
+	// Identifies the state of the pull request.
+	State PullRequestState `json:"state"`
+	// The current status of this pull request with respect to code review.
+	ReviewDecision PullRequestReviewDecision `json:"reviewDecision"`
+	// The date and time that the pull request was merged.
+	MergedAt time.Time `json:"mergedAt"`
 }
 
 // GetNumber returns getMilestonedPullRequestsRepositoryMilestonePullRequestsPullRequestConnectionNodesPullRequest.Number, and is useful for accessing the field via an interface.
@@ -140,11 +198,31 @@ func (v *getMilestonedPullRequestsRepositoryMilestonePullRequestsPullRequestConn
 	return v.Author
 }
 
+// GetAuthorAssociation returns getMilestonedPullRequestsRepositoryMilestonePullRequestsPullRequestConnectionNodesPullRequest.AuthorAssociation, and is useful for accessing the field via an interface.
+func (v *getMilestonedPullRequestsRepositoryMilestonePullRequestsPullRequestConnectionNodesPullRequest) GetAuthorAssociation() CommentAuthorAssociation {
+	return v.AuthorAssociation
+}
+
 // GetHeadRefName returns getMilestonedPullRequestsRepositoryMilestonePullRequestsPullRequestConnectionNodesPullRequest.HeadRefName, and is useful for accessing the field via an interface.
 func (v *getMilestonedPullRequestsRepositoryMilestonePullRequestsPullRequestConnectionNodesPullRequest) GetHeadRefName() string {
 	return v.HeadRefName
 }
 
+// GetState returns getMilestonedPullRequestsRepositoryMilestonePullRequestsPullRequestConnectionNodesPullRequest.State, and is useful for accessing the field via an interface.
+func (v *getMilestonedPullRequestsRepositoryMilestonePullRequestsPullRequestConnectionNodesPullRequest) GetState() PullRequestState {
+	return v.State
+}
+
+// GetReviewDecision returns getMilestonedPullRequestsRepositoryMilestonePullRequestsPullRequestConnectionNodesPullRequest.ReviewDecision, and is useful for accessing the field via an interface.
+func (v *getMilestonedPullRequestsRepositoryMilestonePullRequestsPullRequestConnectionNodesPullRequest) GetReviewDecision() PullRequestReviewDecision {
+	return v.ReviewDecision
+}
+
+// GetMergedAt returns getMilestonedPullRequestsRepositoryMilestonePullRequestsPullRequestConnectionNodesPullRequest.MergedAt, and is useful for accessing the field via an interface.
+func (v *getMilestonedPullRequestsRepositoryMilestonePullRequestsPullRequestConnectionNodesPullRequest) GetMergedAt() time.Time {
+	return v.MergedAt
+}
+
 func (v *getMilestonedPullRequestsRepositoryMilestonePullRequestsPullRequestConnectionNodesPullRequest) UnmarshalJSON(b []byte) error {
 
 	if string(b) == "null" {
@@ -189,7 +267,15 @@ type __premarshalgetMilestonedPullRequestsRepositoryMilestonePullRequestsPullReq
 
 	Author json.RawMessage `json:"author"`
 
+	AuthorAssociation CommentAuthorAssociation `json:"authorAssociation"`
+
 	HeadRefName string `json:"headRefName"`
+
+	State PullRequestState `json:"state"`
+
+	ReviewDecision PullRequestReviewDecision `json:"reviewDecision"`
+
+	MergedAt time.Time `json:"mergedAt"`
 }
 
 func (v *getMilestonedPullRequestsRepositoryMilestonePullRequestsPullRequestConnectionNodesPullRequest) MarshalJSON() ([]byte, error) {
@@ -219,7 +305,11 @@ func (v *getMilestonedPullRequestsRepositoryMilestonePullRequestsPullRequestConn
 				"unable to marshal getMilestonedPullRequestsRepositoryMilestonePullRequestsPullRequestConnectionNodesPullRequest.Author: %w", err)
 		}
 	}
+	retval.AuthorAssociation = v.AuthorAssociation
 	retval.HeadRefName = v.HeadRefName
+	retval.State = v.State
+	retval.ReviewDecision = v.ReviewDecision
+	retval.MergedAt = v.MergedAt
 	return &retval, nil
 }
 
@@ -635,10 +725,10 @@ func (v *getMilestonesWithTitleResponse) GetRepository() getMilestonesWithTitleR
 
 // The query or mutation executed by getMilestonedPullRequests.
 const getMilestonedPullRequests_Operation = `
-query getMilestonedPullRequests ($owner: String!, $repo: String!, $milestoneNumber: Int!, $cursor: String!) {
+query getMilestonedPullRequests ($owner: String!, $repo: String!, $milestoneNumber: Int!, $states: [PullRequestState!]!, $cursor: String!) {
 	repository(owner: $owner, name: $repo) {
 		milestone(number: $milestoneNumber) {
-			pullRequests(first: 20, states: [MERGED], labels: ["add to changelog"], after: $cursor) {
+			pullRequests(first: 20, states: $states, labels: ["add to changelog"], after: $cursor) {
 				pageInfo {
 					endCursor
 					hasNextPage
@@ -657,7 +747,11 @@ query getMilestonedPullRequests ($owner: String!, $repo: String!, $milestoneNumb
 						resourcePath
 						login
 					}
+					authorAssociation
 					headRefName
+					state
+					reviewDecision
+					mergedAt
 				}
 			}
 		}
@@ -671,6 +765,7 @@ func getMilestonedPullRequests(
 	owner string,
 	repo string,
 	milestoneNumber int,
+	states []PullRequestState,
 	cursor string,
 ) (*getMilestonedPullRequestsResponse, error) {
 	req := &graphql.Request{
@@ -680,6 +775,7 @@ func getMilestonedPullRequests(
 			Owner:           owner,
 			Repo:            repo,
 			MilestoneNumber: milestoneNumber,
+			States:          states,
 			Cursor:          cursor,
 		},
 	}
@@ -3,6 +3,7 @@ package ghgql
 import (
 	"context"
 	"sort"
+	"time"
 )
 
 type PullRequest struct {
@@ -15,6 +16,17 @@ type PullRequest struct {
 	RepoOwner          *string
 	RepoName           *string
 	HeadRefName        *string
+	AuthorAssociation  *string
+	State              *string
+	ReviewDecision     *string
+	MergedAt           *time.Time
+	// Authors contains the resolved logins of the people who should be
+	// credited for the pull request. For backports these are the authors of
+	// the original pull request.
+	Authors []string
+	// FirstContribution is set if this pull request is the first
+	// contribution of its author to the repository.
+	FirstContribution bool
 }
 
 func (pr *PullRequest) GetNumber() int {
@@ -68,11 +80,68 @@ func (pr *PullRequest) GetHeadRefName() string {
 	}
 	return *pr.HeadRefName
 }
+
+func (pr *PullRequest) GetAuthorAssociation() string {
+	if pr.AuthorAssociation == nil {
+		return ""
+	}
+	return *pr.AuthorAssociation
+}
+
+func (pr *PullRequest) GetState() string {
+	if pr.State == nil {
+		return ""
+	}
+	return *pr.State
+}
+
+func (pr *PullRequest) GetReviewDecision() string {
+	if pr.ReviewDecision == nil {
+		return ""
+	}
+	return *pr.ReviewDecision
+}
+
+func (pr *PullRequest) GetMergedAt() time.Time {
+	if pr.MergedAt == nil {
+		return time.Time{}
+	}
+	return *pr.MergedAt
+}
+
+// IsOpen returns true if the pull request has not been merged or closed yet.
+func (pr *PullRequest) IsOpen() bool {
+	return pr.GetState() == string(PullRequestStateOpen)
+}
+
+// GetMilestonedPRsForChangelog returns all merged pull requests of the given
+// milestone that are labelled for the changelog.
 func (c *Client) GetMilestonedPRsForChangelog(ctx context.Context, repoOwner string, repoName string, milestoneNumber int) ([]PullRequest, error) {
+	return c.getMilestonedPRs(ctx, repoOwner, repoName, milestoneNumber, []PullRequestState{PullRequestStateMerged})
+}
+
+// GetPendingMilestonedPRsForChangelog returns all open pull requests of the
+// given milestone that are labelled for the changelog and have already been
+// approved.
+func (c *Client) GetPendingMilestonedPRsForChangelog(ctx context.Context, repoOwner string, repoName string, milestoneNumber int) ([]PullRequest, error) {
+	prs, err := c.getMilestonedPRs(ctx, repoOwner, repoName, milestoneNumber, []PullRequestState{PullRequestStateOpen})
+	if err != nil {
+		return nil, err
+	}
+	result := make([]PullRequest, 0, len(prs))
+	for _, pr := range prs {
+		if pr.GetReviewDecision() == string(PullRequestReviewDecisionApproved) {
+			result = append(result, pr)
+		}
+	}
+	return result, nil
+}
+
+func (c *Client) getMilestonedPRs(ctx context.Context, repoOwner string, repoName string, milestoneNumber int, states []PullRequestState) ([]PullRequest, error) {
 	cursor := ""
 	result := make([]PullRequest, 0, 30)
 	for {
-		resp, err := getMilestonedPullRequests(ctx, c.gql, repoOwner, repoName, milestoneNumber, cursor)
+		resp, err := getMilestonedPullRequests(ctx, c.gql, repoOwner, repoName, milestoneNumber, states, cursor)
 		if err != nil {
 			return nil, err
 		}
@@ -88,6 +157,10 @@ func (c *Client) GetMilestonedPRsForChangelog(ctx context.Context, repoOwner str
 			title := pr.Title
 			body := pr.Body
 			headRefName := pr.HeadRefName
+			state := string(pr.State)
+			reviewDecision := string(pr.ReviewDecision)
+			authorAssociation := string(pr.AuthorAssociation)
+			mergedAt := pr.MergedAt
 			r := PullRequest{
 				Number:             &number,
 				Title:              &title,
@@ -97,7 +170,11 @@ func (c *Client) GetMilestonedPRsForChangelog(ctx context.Context, repoOwner str
 				RepoOwner:          &repoOwner,
 				AuthorLogin:        &author,
 				AuthorResourcePath: &authorResourcePath,
+				AuthorAssociation:  &authorAssociation,
 				HeadRefName:        &headRefName,
+				State:              &state,
+				ReviewDecision:     &reviewDecision,
+				MergedAt:           &mergedAt,
 			}
 			result = append(result, r)
 		}
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"strconv"
+	"strings"
 )
 
 type Version struct {
@@ -28,7 +29,7 @@ func (v Version) String() string {
 }
 
 func Parse(v string) (Version, error) {
-	matches := SemverRegexp.FindStringSubmatch(v)
+	matches := SemverRegexp.FindStringSubmatch(strings.TrimPrefix(v, "v"))
 	if len(matches) < 3 {
 		return Version{}, errors.New("version does not match a semver regex")
 	}
@@ -48,6 +49,32 @@ func Parse(v string) (Version, error) {
 	}, nil
 }
 
+// IsSecurityRelease returns true if the build metadata marks the version as a
+// security release (e.g. `10.4.1+security-01`).
+func (v Version) IsSecurityRelease() bool {
+	return strings.HasPrefix(v.Buildmeta, "security")
+}
+
+// SecurityIteration returns the number of the security release (1 for
+// `+security-01`) or 0 if the version is not a security release.
+func (v Version) SecurityIteration() int {
+	if !v.IsSecurityRelease() {
+		return 0
+	}
+	num, err := strconv.Atoi(strings.TrimLeft(strings.TrimPrefix(v.Buildmeta, "security"), "-."))
+	if err != nil || num < 1 {
+		return 1
+	}
+	return num
+}
+
+// WithoutBuildmeta returns the version the security release (or any other
+// build) is based on.
+func (v Version) WithoutBuildmeta() Version {
+	v.Buildmeta = ""
+	return v
+}
+
 // BumpMinor bumps the minor version, resets the patch version to 0, and removes prerelease and buildmeta.
 func BumpMinor(v Version) (Version, error) {
 	minor, err := strconv.ParseInt(v.Minor, 10, 64)
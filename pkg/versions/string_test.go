@@ -26,3 +26,21 @@ func TestString(t *testing.T) {
 		})
 	}
 }
+
+func TestSecurityRelease(t *testing.T) {
+	tests := map[string]int{
+		"1.2.3":                    0,
+		"1.2.3+example-build-meta": 0,
+		"1.2.3+security-01":        1,
+		"v1.2.3+security-02":       2,
+		"1.2.3-1+security":         1,
+	}
+	for v, iteration := range tests {
+		t.Run(v, func(t *testing.T) {
+			r, err := versions.Parse(v)
+			require.NoError(t, err)
+			require.Equal(t, iteration > 0, r.IsSecurityRelease())
+			require.Equal(t, iteration, r.SecurityIteration())
+		})
+	}
+}
@@ -0,0 +1,35 @@
+package main
+
+import (
+	"context"
+	"os"
+
+	"github.com/grafana/grafana-github-actions-go/pkg/changelog"
+)
+
+// diffChangelogFile compares the rendered changelog with the entry of the
+// same version inside the given file. If the file doesn't contain an entry for
+// the version yet, nil is returned.
+func diffChangelogFile(ctx context.Context, path string, version string, rendered string) (*changelog.ChangelogDiff, error) {
+	input, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer input.Close()
+	existing, found, err := changelog.ExtractContentForVersion(ctx, input, version, nil)
+	if err != nil || !found {
+		return nil, err
+	}
+	return changelog.DiffChangelogs(ctx, existing, rendered)
+}
+
+// diffRepository compares the rendered changelog with the entry of the same
+// version inside the changelog files of the repository located at root. If
+// there is no entry for the version yet, nil is returned.
+func diffRepository(ctx context.Context, root string, version string, rendered string) (*changelog.ChangelogDiff, error) {
+	existing, found, err := changelog.LoadLocalContent(ctx, root, version)
+	if err != nil || !found {
+		return nil, err
+	}
+	return changelog.DiffChangelogs(ctx, existing, rendered)
+}
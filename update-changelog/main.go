@@ -4,7 +4,7 @@ import (
 	"context"
 	"fmt"
 	"os"
-	"path/filepath"
+	"strconv"
 	"strings"
 
 	"github.com/grafana/grafana-github-actions-go/pkg/changelog"
@@ -19,6 +19,15 @@ import (
 
 const inputVersion = "VERSION"
 const inputSkipPR = "SKIP_PR"
+const inputChangelogFormat = "CHANGELOG_FORMAT"
+const inputUnreleased = "UNRELEASED"
+const inputUnreleasedIssue = "UNRELEASED_ISSUE"
+const inputNewContributors = "NEW_CONTRIBUTORS"
+const inputArchiveKeepMajors = "ARCHIVE_KEEP_MAJORS"
+const inputFoldPrereleases = "FOLD_PRERELEASES"
+const inputDiff = "DIFF"
+const inputSortOrder = "SORT_ORDER"
+const inputGroupByArea = "GROUP_BY_AREA"
 
 func main() {
 	var changelogFile string
@@ -28,6 +37,8 @@ func main() {
 	var targetBranch string
 	var preview bool
 	var listInputs bool
+	var recordPath string
+	var replayPath string
 	pflag.BoolVar(&preview, "preview", false, "Render a preview of the changelog entry without updating any files")
 	pflag.StringVar(&changelogFile, "changelog-file", "", "Path to changelog file to inject the new entry into")
 	pflag.StringVar(&repository, "repo", os.Getenv("GITHUB_REPOSITORY"), "GitHub repository to clone and update")
@@ -35,6 +46,8 @@ func main() {
 	pflag.StringVar(&ref, "ref", os.Getenv("GITHUB_REF_NAME"), "Git branch to update the changelog in")
 	pflag.StringVar(&targetBranch, "target-branch", "update-changelog", "Name of the branch to use for the pull-request")
 	pflag.BoolVar(&listInputs, "list-inputs", false, "Show a list of all available inputs")
+	pflag.StringVar(&recordPath, "record", "", "Save all data retrieved from GitHub for building the changelog into a fixture file")
+	pflag.StringVar(&replayPath, "replay", "", "Build the changelog from a fixture file created with --record instead of GitHub")
 	pflag.Parse()
 
 	logger := zerolog.New(zerolog.ConsoleWriter{Out: os.Stderr}).With().Timestamp().Logger()
@@ -43,6 +56,15 @@ func main() {
 		ctx,
 		toolkit.WithRegisteredInput(inputVersion, "Version number to generate the changelog for"),
 		toolkit.WithRegisteredInput(inputSkipPR, "Skip the PR creation"),
+		toolkit.WithRegisteredInput(inputChangelogFormat, "Format of the rendered changelog (default, keepachangelog, conventional)"),
+		toolkit.WithRegisteredInput(inputUnreleased, "Render the unreleased changes (including approved but pending PRs) into a sticky issue instead of updating the changelog file"),
+		toolkit.WithRegisteredInput(inputUnreleasedIssue, "Number of the issue to keep updated with the unreleased changes"),
+		toolkit.WithRegisteredInput(inputNewContributors, "Highlight first-time contributors (none, section, mark, both)"),
+		toolkit.WithRegisteredInput(inputArchiveKeepMajors, "Number of major versions to keep in CHANGELOG.md before moving older ones into .changelog-archive (0 disables archiving)"),
+		toolkit.WithRegisteredInput(inputFoldPrereleases, "Merge the entries of pre-releases into the entry of their final release"),
+		toolkit.WithRegisteredInput(inputDiff, "Report entries that were added, removed, or moved compared to the existing changelog of the version"),
+		toolkit.WithRegisteredInput(inputSortOrder, "Order of the entries within a section (number, area, merged); empty keeps the order of the milestone"),
+		toolkit.WithRegisteredInput(inputGroupByArea, "Group the entries of a section under sub-headings by the area prefix of their title"),
 	)
 	if err != nil {
 		logger.Fatal().Err(err).Msg("Failed to initialize toolkit")
@@ -59,6 +81,26 @@ func main() {
 	}()
 
 	skipPR := tk.MustGetBoolInput(ctx, inputSkipPR)
+	showDiff := tk.MustGetBoolInput(ctx, inputDiff)
+	updateOpts := &changelog.UpdateFileOptions{
+		FoldPrereleases: tk.MustGetBoolInput(ctx, inputFoldPrereleases),
+	}
+	unreleased := tk.MustGetBoolInput(ctx, inputUnreleased)
+	var unreleasedIssue int
+	if rawUnreleasedIssue := tk.MustGetInput(ctx, inputUnreleasedIssue); rawUnreleasedIssue != "" {
+		unreleasedIssue, err = strconv.Atoi(rawUnreleasedIssue)
+		if err != nil {
+			logger.Fatal().Err(err).Msgf("Failed to parse %s", tk.GetInputEnvName(inputUnreleasedIssue))
+		}
+	}
+
+	var archiveKeepMajors int
+	if rawArchiveKeepMajors := tk.MustGetInput(ctx, inputArchiveKeepMajors); rawArchiveKeepMajors != "" {
+		archiveKeepMajors, err = strconv.Atoi(rawArchiveKeepMajors)
+		if err != nil {
+			logger.Fatal().Err(err).Msgf("Failed to parse %s", tk.GetInputEnvName(inputArchiveKeepMajors))
+		}
+	}
 
 	version := tk.MustGetInput(ctx, inputVersion)
 	if version == "" {
@@ -76,17 +118,84 @@ func main() {
 		logger.Fatal().Err(err).Msg("Invalid version number")
 	}
 
-	body, err := changelog.Build(ctx, version, tk)
+	rendererOpts, err := changelog.NewContributorsRendererOptions(tk.MustGetInput(ctx, inputNewContributors))
+	if err != nil {
+		logger.Fatal().Err(err).Msgf("Invalid value for %s", tk.GetInputEnvName(inputNewContributors))
+	}
+	detectNewContributors := len(rendererOpts) > 0
+	sortOpts, err := changelog.SortRendererOptions(tk.MustGetInput(ctx, inputSortOrder), tk.MustGetBoolInput(ctx, inputGroupByArea))
+	if err != nil {
+		logger.Fatal().Err(err).Msgf("Invalid value for %s", tk.GetInputEnvName(inputSortOrder))
+	}
+	rendererOpts = append(rendererOpts, sortOpts...)
+
+	dataSource := changelog.NewGitHubDataSource(tk)
+	if replayPath != "" {
+		fixture, err := changelog.LoadFixture(replayPath)
+		if err != nil {
+			logger.Fatal().Err(err).Msg("Failed to load fixture")
+		}
+		dataSource = changelog.NewFixtureDataSource(fixture)
+	}
+	var recorder *changelog.RecordingDataSource
+	if recordPath != "" {
+		recorder = changelog.NewRecordingDataSource(dataSource)
+		dataSource = recorder
+	}
+
+	body, err := changelog.Build(ctx, version, dataSource, &changelog.BuildOptions{
+		IncludePending:        unreleased,
+		DetectNewContributors: detectNewContributors,
+	})
 	if err != nil {
 		logger.Fatal().Err(err).Msg("Failed to build changelog")
 	}
+	if recorder != nil {
+		if err := recorder.Fixture().Save(recordPath); err != nil {
+			logger.Fatal().Err(err).Msg("Failed to save fixture")
+		}
+		logger.Info().Msgf("Fixture saved to %s", recordPath)
+	}
 
-	renderer := changelog.NewRenderer(tk)
+	renderer, err := changelog.NewRendererForFormat(tk, tk.MustGetInput(ctx, inputChangelogFormat), rendererOpts...)
+	if err != nil {
+		logger.Fatal().Err(err).Msg("Failed to create changelog renderer")
+	}
 	renderedMarkdown, err := renderer.Render(ctx, body)
 	if err != nil {
 		logger.Fatal().Err(err).Msg("Failed to render changelog to markdown")
 	}
 
+	if unreleased {
+		if preview {
+			fmt.Println(renderedMarkdown)
+			return
+		}
+		if repository == "" {
+			logger.Fatal().Msg("No repository specified for the unreleased changes issue")
+		}
+		repoOwner, repoName, found := strings.Cut(repository, "/")
+		if !found || repoOwner == "" || repoName == "" {
+			logger.Fatal().Msgf("Invalid repository `%s`, expected owner/repo", repository)
+		}
+		issue, err := updateUnreleasedIssue(ctx, newGitHubIssueClient(tk), repoOwner, repoName, version, renderedMarkdown, unreleasedIssue)
+		if err != nil {
+			logger.Fatal().Err(err).Msg("Failed to update unreleased changes issue")
+		}
+		logger.Info().Msgf("Unreleased changes available at <%s>.", issue.GetHTMLURL())
+		return
+	}
+
+	if showDiff && changelogFile != "" {
+		diff, err := diffChangelogFile(ctx, changelogFile, version, renderedMarkdown)
+		if err != nil {
+			logger.Fatal().Err(err).Msg("Failed to compare with the existing changelog")
+		}
+		if diff != nil {
+			diff.Log(ctx)
+		}
+	}
+
 	if preview {
 		if changelogFile != "" {
 			input, err := os.Open(changelogFile)
@@ -94,7 +203,7 @@ func main() {
 				logger.Fatal().Err(err).Msg("Failed to open changelog file")
 			}
 			defer input.Close()
-			if err := changelog.UpdateFile(ctx, os.Stdout, input, renderedMarkdown, body); err != nil {
+			if err := changelog.UpdateFile(ctx, os.Stdout, input, renderedMarkdown, body, updateOpts); err != nil {
 				logger.Fatal().Err(err).Msg("Failed to update changelog file")
 			}
 		} else {
@@ -105,7 +214,7 @@ func main() {
 	if changelogFile != "" {
 		logger.Info().Msgf("Updating %s", changelogFile)
 
-		if err := changelog.UpdateFileAtPath(ctx, changelogFile, renderedMarkdown, body); err != nil {
+		if err := changelog.UpdateFileAtPath(ctx, changelogFile, renderedMarkdown, body, updateOpts); err != nil {
 			logger.Fatal().Err(err).Msg("Failed to update changelog file")
 		}
 	}
@@ -114,9 +223,10 @@ func main() {
 		if repository != "" {
 			// If a changelog repository is provided, clone that repo at the
 			// provided revision and use the changelog from there.
-			elems := strings.Split(repository, "/")
-			repoOwner := elems[0]
-			repoRepo := elems[1]
+			repoOwner, repoRepo, found := strings.Cut(repository, "/")
+			if !found || repoOwner == "" || repoRepo == "" {
+				logger.Fatal().Msgf("Invalid repository `%s`, expected owner/repo", repository)
+			}
 			logger = logger.With().Str("repo", repoRepo).Str("owner", repoOwner).Str("targetBranch", targetBranch).Logger()
 			branchExists, err := tk.BranchExists(ctx, repoOwner, repoRepo, targetBranch)
 			title := fmt.Sprintf("Changelog: Updated changelog for %s", version)
@@ -150,12 +260,27 @@ func main() {
 				logger.Fatal().Err(err).Msg("Failed to switch to target branch")
 			}
 
-			if err := changelog.UpdateFileAtPath(ctx, filepath.Join(repositoryPath, "CHANGELOG.md"), renderedMarkdown, body); err != nil {
+			var diff *changelog.ChangelogDiff
+			if showDiff {
+				diff, err = diffRepository(ctx, repositoryPath, version, renderedMarkdown)
+				if err != nil {
+					logger.Fatal().Err(err).Msg("Failed to compare with the existing changelog")
+				}
+				if diff != nil {
+					diff.Log(ctx)
+				}
+			}
+
+			changedFiles, err := changelog.UpdateFiles(ctx, repositoryPath, renderedMarkdown, body, &changelog.UpdateFilesOptions{
+				KeepMajors:      archiveKeepMajors,
+				FoldPrereleases: updateOpts.FoldPrereleases,
+			})
+			if err != nil {
 				logger.Fatal().Err(err).Msg("Failed to update changelog")
 			}
 
-			if err := gitRepo.Exec(ctx, "add", "CHANGELOG.md"); err != nil {
-				logger.Fatal().Err(err).Msg("Failed to add CHANGELOG.md")
+			if err := gitRepo.Exec(ctx, append([]string{"add"}, changedFiles...)...); err != nil {
+				logger.Fatal().Err(err).Msgf("Failed to add %s", strings.Join(changedFiles, ", "))
 			}
 
 			if err := gitRepo.Exec(ctx, "commit", "-m", title); err != nil {
@@ -208,6 +333,10 @@ func main() {
 			pr.Draft = &isDraft
 			pr.Base = &ref
 			pr.Head = &targetBranch
+			if diff != nil {
+				prBody := diff.Markdown()
+				pr.Body = &prBody
+			}
 
 			tk.IncrRequestCount()
 			createPR, _, err := ghc.PullRequests.Create(ctx, repoOwner, repoRepo, &pr)
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/google/go-github/v50/github"
+	"github.com/grafana/grafana-github-actions-go/pkg/toolkit"
+	"github.com/rs/zerolog"
+)
+
+// unreleasedIssueMarker is added to the body of the sticky issue so that it
+// can be identified again on the next run.
+func unreleasedIssueMarker(version string) string {
+	return fmt.Sprintf("<!-- update-changelog:unreleased:%s -->", version)
+}
+
+func unreleasedIssueTitle(version string) string {
+	return fmt.Sprintf("Changelog: Unreleased changes for %s", version)
+}
+
+func unreleasedIssueBody(version string, renderedMarkdown string) string {
+	out := strings.Builder{}
+	out.WriteString(unreleasedIssueMarker(version))
+	out.WriteString("\n\n")
+	out.WriteString("This issue is updated automatically and lists the changes that will be part of the next release. ")
+	out.WriteString("Entries marked as *pending* belong to approved pull requests that haven't been merged yet.\n\n")
+	out.WriteString(renderedMarkdown)
+	return out.String()
+}
+
+// unreleasedIssueClient contains the GitHub API operations required for
+// maintaining the unreleased changes issue.
+type unreleasedIssueClient interface {
+	SearchIssues(ctx context.Context, query string, opts *github.SearchOptions) (*github.IssuesSearchResult, *github.Response, error)
+	CreateIssue(ctx context.Context, owner string, repo string, issue *github.IssueRequest) (*github.Issue, *github.Response, error)
+	EditIssue(ctx context.Context, owner string, repo string, number int, issue *github.IssueRequest) (*github.Issue, *github.Response, error)
+}
+
+// gitHubIssueClient implements unreleasedIssueClient using the GitHub client
+// of the toolkit and counts the requests for the usage metrics.
+type gitHubIssueClient struct {
+	tk *toolkit.Toolkit
+}
+
+func newGitHubIssueClient(tk *toolkit.Toolkit) *gitHubIssueClient {
+	return &gitHubIssueClient{tk: tk}
+}
+
+func (c *gitHubIssueClient) SearchIssues(ctx context.Context, query string, opts *github.SearchOptions) (*github.IssuesSearchResult, *github.Response, error) {
+	c.tk.IncrRequestCount()
+	return c.tk.GitHubClient().Search.Issues(ctx, query, opts)
+}
+
+func (c *gitHubIssueClient) CreateIssue(ctx context.Context, owner string, repo string, issue *github.IssueRequest) (*github.Issue, *github.Response, error) {
+	c.tk.IncrRequestCount()
+	return c.tk.GitHubClient().Issues.Create(ctx, owner, repo, issue)
+}
+
+func (c *gitHubIssueClient) EditIssue(ctx context.Context, owner string, repo string, number int, issue *github.IssueRequest) (*github.Issue, *github.Response, error) {
+	c.tk.IncrRequestCount()
+	return c.tk.GitHubClient().Issues.Edit(ctx, owner, repo, number, issue)
+}
+
+// updateUnreleasedIssue creates or updates the sticky issue containing the
+// unreleased changelog for the given version. If issueNumber is set, that
+// issue is updated. Otherwise an open issue with the matching title and marker
+// is looked up and a new one is created if none exists yet.
+func updateUnreleasedIssue(ctx context.Context, client unreleasedIssueClient, repoOwner string, repoName string, version string, renderedMarkdown string, issueNumber int) (*github.Issue, error) {
+	logger := zerolog.Ctx(ctx)
+	title := unreleasedIssueTitle(version)
+	body := unreleasedIssueBody(version, renderedMarkdown)
+
+	if issueNumber <= 0 {
+		existing, err := findUnreleasedIssue(ctx, client, repoOwner, repoName, version)
+		if err != nil {
+			return nil, err
+		}
+		if existing != nil {
+			issueNumber = existing.GetNumber()
+		}
+	}
+
+	if issueNumber > 0 {
+		logger.Info().Msgf("Updating issue #%d", issueNumber)
+		issue, _, err := client.EditIssue(ctx, repoOwner, repoName, issueNumber, &github.IssueRequest{
+			Title: &title,
+			Body:  &body,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to update issue #%d: %w", issueNumber, err)
+		}
+		return issue, nil
+	}
+
+	logger.Info().Msg("Creating new issue")
+	issue, _, err := client.CreateIssue(ctx, repoOwner, repoName, &github.IssueRequest{
+		Title: &title,
+		Body:  &body,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create issue: %w", err)
+	}
+	return issue, nil
+}
+
+func findUnreleasedIssue(ctx context.Context, client unreleasedIssueClient, repoOwner string, repoName string, version string) (*github.Issue, error) {
+	query := fmt.Sprintf(`repo:%s/%s is:issue is:open in:title "%s"`, repoOwner, repoName, unreleasedIssueTitle(version))
+	marker := unreleasedIssueMarker(version)
+	opts := &github.SearchOptions{}
+	opts.Page = 1
+	for {
+		result, resp, err := client.SearchIssues(ctx, query, opts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to search for existing issue: %w", err)
+		}
+		for _, issue := range result.Issues {
+			if strings.Contains(issue.GetBody(), marker) {
+				return issue, nil
+			}
+		}
+		if resp == nil || resp.NextPage <= opts.Page {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+	return nil, nil
+}
@@ -0,0 +1,144 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/google/go-github/v50/github"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeIssueClient keeps the issues of a single repository in memory. The
+// search returns one issue per page to cover the pagination.
+type fakeIssueClient struct {
+	issues   []*github.Issue
+	queries  []string
+	created  int
+	edited   []int
+	failWith error
+}
+
+func (c *fakeIssueClient) SearchIssues(ctx context.Context, query string, opts *github.SearchOptions) (*github.IssuesSearchResult, *github.Response, error) {
+	if c.failWith != nil {
+		return nil, nil, c.failWith
+	}
+	c.queries = append(c.queries, query)
+	matches := make([]*github.Issue, 0, len(c.issues))
+	for _, issue := range c.issues {
+		if issue.GetState() == "open" && strings.Contains(query, fmt.Sprintf(`"%s"`, issue.GetTitle())) {
+			matches = append(matches, issue)
+		}
+	}
+	resp := &github.Response{}
+	if opts.Page < len(matches) {
+		resp.NextPage = opts.Page + 1
+	}
+	if opts.Page < 1 || opts.Page > len(matches) {
+		return &github.IssuesSearchResult{}, resp, nil
+	}
+	return &github.IssuesSearchResult{
+		Total:  github.Int(len(matches)),
+		Issues: matches[opts.Page-1 : opts.Page],
+	}, resp, nil
+}
+
+func (c *fakeIssueClient) CreateIssue(ctx context.Context, owner string, repo string, issue *github.IssueRequest) (*github.Issue, *github.Response, error) {
+	c.created++
+	created := &github.Issue{
+		Number: github.Int(100 + len(c.issues)),
+		State:  github.String("open"),
+		Title:  issue.Title,
+		Body:   issue.Body,
+	}
+	c.issues = append(c.issues, created)
+	return created, nil, nil
+}
+
+func (c *fakeIssueClient) EditIssue(ctx context.Context, owner string, repo string, number int, issue *github.IssueRequest) (*github.Issue, *github.Response, error) {
+	c.edited = append(c.edited, number)
+	for _, existing := range c.issues {
+		if existing.GetNumber() == number {
+			existing.Title = issue.Title
+			existing.Body = issue.Body
+			return existing, nil, nil
+		}
+	}
+	return nil, nil, fmt.Errorf("issue #%d not found", number)
+}
+
+func TestUpdateUnreleasedIssue(t *testing.T) {
+	ctx := context.Background()
+	title := unreleasedIssueTitle("11.3.0")
+
+	t.Run("create", func(t *testing.T) {
+		client := &fakeIssueClient{}
+		issue, err := updateUnreleasedIssue(ctx, client, "grafana", "grafana", "11.3.0", "### Features\n\n- New", 0)
+		require.NoError(t, err)
+		require.Equal(t, 1, client.created)
+		require.Empty(t, client.edited)
+		require.Equal(t, title, issue.GetTitle())
+		require.True(t, strings.HasPrefix(issue.GetBody(), unreleasedIssueMarker("11.3.0")))
+		require.True(t, strings.HasSuffix(issue.GetBody(), "### Features\n\n- New"))
+		require.Equal(t, []string{`repo:grafana/grafana is:issue is:open in:title "Changelog: Unreleased changes for 11.3.0"`}, client.queries)
+
+		// The next run finds the issue again:
+		_, err = updateUnreleasedIssue(ctx, client, "grafana", "grafana", "11.3.0", "### Features\n\n- Newer", 0)
+		require.NoError(t, err)
+		require.Equal(t, 1, client.created)
+		require.Equal(t, []int{issue.GetNumber()}, client.edited)
+		require.True(t, strings.HasSuffix(client.issues[0].GetBody(), "- Newer"))
+	})
+
+	t.Run("requires-marker", func(t *testing.T) {
+		// Issues with the same title but without the marker (e.g. created
+		// manually) are skipped and later pages are checked as well:
+		client := &fakeIssueClient{
+			issues: []*github.Issue{
+				{Number: github.Int(1), State: github.String("open"), Title: github.String(title), Body: github.String("Manual")},
+				{Number: github.Int(2), State: github.String("closed"), Title: github.String(title), Body: github.String(unreleasedIssueMarker("11.3.0"))},
+				{Number: github.Int(3), State: github.String("open"), Title: github.String(title), Body: github.String(unreleasedIssueMarker("11.3.0"))},
+			},
+		}
+		issue, err := updateUnreleasedIssue(ctx, client, "grafana", "grafana", "11.3.0", "content", 0)
+		require.NoError(t, err)
+		require.Equal(t, 3, issue.GetNumber())
+		require.Equal(t, []int{3}, client.edited)
+		require.Equal(t, 0, client.created)
+		require.Equal(t, "Manual", client.issues[0].GetBody())
+	})
+
+	t.Run("other-version", func(t *testing.T) {
+		client := &fakeIssueClient{
+			issues: []*github.Issue{
+				{Number: github.Int(1), State: github.String("open"), Title: github.String(unreleasedIssueTitle("11.2.0")), Body: github.String(unreleasedIssueMarker("11.2.0"))},
+			},
+		}
+		_, err := updateUnreleasedIssue(ctx, client, "grafana", "grafana", "11.3.0", "content", 0)
+		require.NoError(t, err)
+		require.Equal(t, 1, client.created)
+		require.Empty(t, client.edited)
+	})
+
+	t.Run("explicit-issue-number", func(t *testing.T) {
+		client := &fakeIssueClient{
+			issues: []*github.Issue{
+				{Number: github.Int(5), State: github.String("open"), Title: github.String("Anything"), Body: github.String("old")},
+			},
+			// No search is needed:
+			failWith: fmt.Errorf("search not expected"),
+		}
+		issue, err := updateUnreleasedIssue(ctx, client, "grafana", "grafana", "11.3.0", "content", 5)
+		require.NoError(t, err)
+		require.Equal(t, title, issue.GetTitle())
+		require.Equal(t, []int{5}, client.edited)
+	})
+
+	t.Run("search-error", func(t *testing.T) {
+		client := &fakeIssueClient{failWith: fmt.Errorf("rate limited")}
+		_, err := updateUnreleasedIssue(ctx, client, "grafana", "grafana", "11.3.0", "content", 0)
+		require.ErrorContains(t, err, "rate limited")
+		require.Equal(t, 0, client.created)
+	})
+}